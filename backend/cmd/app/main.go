@@ -2,18 +2,27 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"html/template"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
+	"lnk/domain/entities"
 	"lnk/domain/entities/usecases"
+	"lnk/extensions/buildinfo"
 	"lnk/extensions/config"
+	"lnk/extensions/health"
+	"lnk/extensions/lifecycle"
 	"lnk/extensions/logger"
 	"lnk/extensions/opentelemetry"
+	"lnk/extensions/pagemeta"
+	"lnk/extensions/qrcode"
 	redisPackage "lnk/extensions/redis"
+	"lnk/extensions/threatlist"
 	gocqlPackage "lnk/gateways/gocql"
 	"lnk/gateways/gocql/repositories"
 	httpServer "lnk/gateways/http"
@@ -25,73 +34,174 @@ import (
 )
 
 func main() {
-	cfg, appLogger := setupConfigAndLogger()
+	cfg, appLogger, logLevel := setupConfigAndLogger()
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	shutdownOTel, err := setupOTelSDK(ctx, cfg)
+	err := run(ctx, cfg, appLogger, logLevel)
 	if err != nil {
-		appLogger.Fatal("Failed to setup OpenTelemetry", zap.Error(err))
+		appLogger.Error("Application stopped with error", zap.Error(err))
+		_ = appLogger.Sync()
+
+		os.Exit(1)
+	}
+
+	appLogger.Info("Application stopped")
+}
+
+// run wires every component into the lifecycle manager. Components are stopped
+// in reverse registration order, so telemetry, registered first, is flushed
+// after everything that could still emit spans or metrics.
+func run(ctx context.Context, cfg *config.Config, appLogger *zap.Logger, logLevel zap.AtomicLevel) error {
+	manager := lifecycle.NewManager(appLogger, cfg.App.ShutdownTimeout)
+
+	err := start(ctx, cfg, appLogger, logLevel, manager)
+	if err != nil {
+		return errors.Join(err, manager.Shutdown(context.WithoutCancel(ctx)))
+	}
+
+	return manager.Wait(ctx)
+}
+
+func start(
+	ctx context.Context,
+	cfg *config.Config,
+	appLogger *zap.Logger,
+	logLevel zap.AtomicLevel,
+	manager *lifecycle.Manager,
+) error {
+	otelSDK, err := setupOTelSDK(ctx, cfg)
+	if err != nil {
+		return err
 	}
-	defer func() {
-		if shutdownErr := shutdownOTel(ctx); shutdownErr != nil {
-			appLogger.Error("Failed to shutdown OpenTelemetry", zap.Error(shutdownErr))
-		}
-	}()
+
+	manager.Add(lifecycle.Component{Name: "opentelemetry", Stop: otelSDK.Shutdown})
 
 	session, err := setupDatabase(cfg, appLogger)
 	if err != nil {
-		appLogger.Fatal("Failed to setup database", zap.Error(err))
+		return err
 	}
-	defer session.Close()
+
+	manager.Add(lifecycle.Component{Name: "cassandra", Stop: func(context.Context) error {
+		session.Close()
+		return nil
+	}})
 
 	redisClient, err := setupRedis(ctx, cfg, appLogger)
 	if err != nil {
-		appLogger.Fatal("Failed to setup Redis", zap.Error(err))
+		return err
 	}
 
-	defer func() {
-		if closeErr := redisClient.Close(); closeErr != nil {
-			appLogger.Error("Failed to close Redis client", zap.Error(closeErr))
-		}
-	}()
+	manager.Add(lifecycle.Component{Name: "redis", Stop: func(context.Context) error {
+		return redisClient.Close()
+	}})
 
 	err = initializeCounter(ctx, redisClient, cfg, appLogger)
 	if err != nil {
-		appLogger.Fatal("Failed to initialize counter", zap.Error(err))
+		return err
+	}
+
+	idAllocator := createIDAllocator(cfg, appLogger, redisClient)
+	manager.Add(lifecycle.Component{Name: "id-allocator", Stop: func(context.Context) error {
+		idAllocator.Close()
+		return nil
+	}})
+
+	threats, err := createThreatList(cfg, appLogger)
+	if err != nil {
+		return err
 	}
-	useCase := createUseCase(cfg, appLogger, session, redisClient)
-	server, err := createAndStartServer(cfg, appLogger, useCase)
+
+	if threats != nil {
+		manager.Add(lifecycle.Component{Name: "threat-list", Run: threats.Run})
+	}
+
+	useCase, err := createUseCase(cfg, appLogger, session, redisClient, idAllocator, threats)
+	if err != nil {
+		return err
+	}
+
+	err = reconcileCounter(ctx, useCase, appLogger)
 	if err != nil {
-		appLogger.Fatal("Failed to create and start server", zap.Error(err))
+		return err
 	}
 
-	shutdownServer(ctx, appLogger, server)
+	manager.Add(lifecycle.Component{Name: "counter-checkpoints", Run: func(ctx context.Context) error {
+		useCase.RunCounterCheckpoints(ctx, cfg.Redis.CounterCheckpointInterval)
+		return nil
+	}})
+
+	manager.Add(lifecycle.Component{Name: "preview-fetches", Run: func(ctx context.Context) error {
+		useCase.RunPreviewFetches(ctx)
+		return nil
+	}})
+
+	manager.Add(lifecycle.Component{Name: "threat-scans", Run: func(ctx context.Context) error {
+		useCase.RunThreatScans(ctx)
+		return nil
+	}})
+
+	checker, err := createHealthChecker(cfg, session, redisClient)
+	if err != nil {
+		return err
+	}
+
+	bulkJobs := usecases.NewBulkJobRunner(usecases.NewBulkJobRunnerParams{
+		Logger:  appLogger,
+		UseCase: useCase,
+		Store: redisPackage.NewBulkJobStore(redisPackage.NewBulkJobStoreParams{
+			Client: redisClient,
+			TTL:    cfg.Redis.BulkJobTTL,
+		}),
+	})
+	manager.Add(lifecycle.Component{Name: "bulk-jobs", Stop: bulkJobs.Stop})
+
+	qrRenderer, err := createQRRenderer(cfg, appLogger, redisClient)
+	if err != nil {
+		return err
+	}
+
+	var interstitial *template.Template
+	if cfg.App.InterstitialPath != "" {
+		if interstitial, err = handlers.ParseInterstitial(cfg.App.InterstitialPath); err != nil {
+			return err
+		}
+	}
+
+	server := createServer(cfg, appLogger, useCase, checker, redisClient, bulkJobs, qrRenderer, interstitial)
+	manager.Add(lifecycle.Component{Name: "http-server", Run: server.Run, Stop: server.Shutdown})
+
+	if cfg.App.AdminEnabled {
+		adminServer := createAdminServer(cfg, appLogger, logLevel, useCase, qrRenderer, otelSDK.MetricsHandler)
+		manager.Add(lifecycle.Component{Name: "admin-server", Run: adminServer.Run, Stop: adminServer.Shutdown})
+	}
+
+	return nil
 }
 
-func setupOTelSDK(ctx context.Context, cfg *config.Config) (func(context.Context) error, error) {
-	shutdown, err := opentelemetry.SetupOTelSDK(ctx, &cfg.OTel)
+func setupOTelSDK(ctx context.Context, cfg *config.Config) (*opentelemetry.SDK, error) {
+	sdk, err := opentelemetry.SetupOTelSDK(ctx, &cfg.OTel)
 	if err != nil {
 		return nil, fmt.Errorf("failed to setup OpenTelemetry SDK: %w", err)
 	}
-	return shutdown, nil
+	return sdk, nil
 }
 
-func setupConfigAndLogger() (*config.Config, *zap.Logger) {
+func setupConfigAndLogger() (*config.Config, *zap.Logger, zap.AtomicLevel) {
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
-	appLogger, err := logger.NewLogger(cfg.Logger)
+	appLogger, logLevel, err := logger.NewLoggerWithLevel(cfg.Logger)
 	if err != nil {
 		log.Fatalf("Failed to create logger: %v", err)
 	}
 
-	appLogger.Info("Starting application")
+	appLogger.Info("Starting application", zap.String("version", buildinfo.Get().Version))
 
-	return cfg, appLogger
+	return cfg, appLogger, logLevel
 }
 
 func setupDatabase(cfg *config.Config, appLogger *zap.Logger) (*gocql.Session, error) {
@@ -103,7 +213,7 @@ func setupDatabase(cfg *config.Config, appLogger *zap.Logger) (*gocql.Session, e
 	return session, nil
 }
 
-func setupRedis(ctx context.Context, cfg *config.Config, appLogger *zap.Logger) (*redis.Client, error) {
+func setupRedis(ctx context.Context, cfg *config.Config, appLogger *zap.Logger) (redis.UniversalClient, error) {
 	redisClient, err := redisPackage.SetupRedis(ctx, &cfg.Redis, appLogger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to setup Redis: %w", err)
@@ -112,7 +222,7 @@ func setupRedis(ctx context.Context, cfg *config.Config, appLogger *zap.Logger)
 	return redisClient, nil
 }
 
-func initializeCounter(ctx context.Context, redisClient *redis.Client, cfg *config.Config, appLogger *zap.Logger) error {
+func initializeCounter(ctx context.Context, redisClient redis.UniversalClient, cfg *config.Config, appLogger *zap.Logger) error {
 	_, err := redisPackage.SetInitialCounterValue(ctx, redisClient, &cfg.Redis, appLogger)
 	if err != nil {
 		return fmt.Errorf("failed to set initial counter: %w", err)
@@ -121,21 +231,183 @@ func initializeCounter(ctx context.Context, redisClient *redis.Client, cfg *conf
 	return nil
 }
 
-func createUseCase(cfg *config.Config, appLogger *zap.Logger, session *gocql.Session, redisClient *redis.Client) *usecases.UseCase {
-	repository := repositories.NewRepository(appLogger, session)
+func createIDAllocator(cfg *config.Config, appLogger *zap.Logger, redisClient redis.UniversalClient) *redisPackage.BlockAllocator {
+	return redisPackage.NewBlockAllocator(redisPackage.NewBlockAllocatorParams{
+		Redis:     redisPackage.NewRedisAdapter(redisClient),
+		Logger:    appLogger,
+		Key:       cfg.Redis.CounterRedisKey(),
+		BlockSize: cfg.Redis.CounterBlockSize,
+	})
+}
+
+func createUseCase(
+	cfg *config.Config,
+	appLogger *zap.Logger,
+	session *gocql.Session,
+	redisClient redis.UniversalClient,
+	idAllocator usecases.IDAllocator,
+	threats *threatlist.List,
+) (*usecases.UseCase, error) {
+	queryPolicy, err := gocqlPackage.NewQueryPolicy(&cfg.Gocql)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query policy: %w", err)
+	}
+
+	repository := repositories.NewRepository(appLogger, session).WithQueryPolicy(queryPolicy)
 	redisAdapter := redisPackage.NewRedisAdapter(redisClient)
 
+	domains, err := usecases.NewDomains(cfg.App.PublicBaseURL, cfg.App.ShortDomains)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure short domains: %w", err)
+	}
+
 	return usecases.NewUseCase(usecases.NewUseCaseParams{
-		Logger:     appLogger,
-		Repository: repository,
-		Redis:      redisAdapter,
-		Salt:       cfg.App.Base62Salt,
-		CounterKey: cfg.Redis.CounterKey,
+		Logger:      appLogger,
+		Repository:  repository,
+		Redis:       redisAdapter,
+		IDAllocator: idAllocator,
+		Dedup: usecases.DedupPolicy{
+			Enabled: cfg.App.DedupEnabled,
+			Owners:  cfg.App.DedupOwners,
+		},
+		Workspaces: usecases.WorkspacePolicy{
+			Enforced: cfg.App.WorkspacesEnforced,
+			Usage:    redisPackage.NewUsageMeter(redisClient),
+			DefaultQuota: entities.WorkspaceQuota{
+				LinksPerMonth: cfg.App.WorkspaceLinksPerMonth,
+				Domains:       cfg.App.WorkspaceMaxDomains,
+			},
+		},
+		Domains:         domains,
+		Salt:            cfg.App.Base62Salt,
+		ContinueSecret:  cfg.App.InterstitialSecret,
+		CounterKey:      cfg.Redis.CounterKey,
+		CounterRedisKey: cfg.Redis.CounterRedisKey(),
+		CounterHeadroom: cfg.Redis.CounterHeadroom,
+		BulkConcurrency: cfg.App.BulkConcurrency,
+		Previews:        createPreviewPolicy(cfg),
+		Threats:         createThreatPolicy(cfg, redisClient, threats),
+	}), nil
+}
+
+// createThreatList loads the threat feeds; without a directory, no list is
+// loaded.
+func createThreatList(cfg *config.Config, appLogger *zap.Logger) (*threatlist.List, error) {
+	if cfg.Threats.Dir == "" {
+		return nil, nil
+	}
+
+	list, err := threatlist.NewList(threatlist.NewListParams{
+		Logger:  appLogger,
+		Dir:     cfg.Threats.Dir,
+		Refresh: cfg.Threats.Refresh,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load threat list: %w", err)
+	}
+
+	return list, nil
+}
+
+// createThreatPolicy checks destinations against threats; without a list,
+// nothing is checked. A Redis lease keeps replicas from scanning at once.
+func createThreatPolicy(cfg *config.Config, redisClient redis.UniversalClient, threats *threatlist.List) usecases.ThreatPolicy {
+	if threats == nil {
+		return usecases.ThreatPolicy{}
+	}
+
+	return usecases.ThreatPolicy{
+		Matcher:      threats,
+		Lease:        redisPackage.NewLease(redisClient, "threat-scans"),
+		ScanInterval: cfg.Threats.ScanInterval,
+	}
+}
+
+// createPreviewPolicy configures the background fetches of destination
+// metadata; without a fetcher, nothing is fetched.
+func createPreviewPolicy(cfg *config.Config) usecases.PreviewPolicy {
+	if !cfg.PageMeta.Enabled {
+		return usecases.PreviewPolicy{}
+	}
+
+	return usecases.PreviewPolicy{
+		Fetcher: pagemeta.NewFetcher(pagemeta.NewFetcherParams{
+			UserAgent:    cfg.PageMeta.UserAgent,
+			Timeout:      cfg.PageMeta.Timeout,
+			RobotsTTL:    cfg.PageMeta.RobotsTTL,
+			MaxBodyBytes: cfg.PageMeta.MaxBodyBytes,
+			MaxRedirects: cfg.PageMeta.MaxRedirects,
+		}),
+		Backoff:   cfg.PageMeta.Backoff,
+		Workers:   cfg.PageMeta.Workers,
+		QueueSize: cfg.PageMeta.QueueSize,
+		Attempts:  cfg.PageMeta.Attempts,
+	}
+}
+
+func reconcileCounter(ctx context.Context, useCase *usecases.UseCase, appLogger *zap.Logger) error {
+	status, err := useCase.RestoreCounter(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to reconcile counter: %w", err)
+	}
+
+	appLogger.Info("Counter reconciled",
+		zap.Int64("redis_value", status.RedisValue),
+		zap.Int64("high_water_mark", status.HighWaterMark),
+		zap.Bool("healthy", status.Healthy),
+	)
+
+	return nil
+}
+
+func createHealthChecker(cfg *config.Config, session *gocql.Session, redisClient redis.UniversalClient) (*health.Checker, error) {
+	checker := health.NewChecker(health.NewCheckerParams{
+		Timeout:  cfg.App.ReadinessTimeout,
+		CacheTTL: cfg.App.ReadinessCacheTTL,
 	})
+
+	executor := gocqlPackage.NewExecutor(session)
+
+	migrationCheck, err := gocqlPackage.MigrationCheck(executor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migration check: %w", err)
+	}
+
+	checker.Register("cassandra", gocqlPackage.PingCheck(executor))
+	checker.Register("migrations", migrationCheck)
+	checker.Register("redis", redisPackage.PingCheck(redisClient))
+
+	return checker, nil
 }
 
-func createAndStartServer(cfg *config.Config, appLogger *zap.Logger, useCase *usecases.UseCase) (*httpServer.Server, error) {
-	httpHandlers := handlers.NewHandlers(appLogger, useCase)
+func createServer(
+	cfg *config.Config,
+	appLogger *zap.Logger,
+	useCase *usecases.UseCase,
+	checker *health.Checker,
+	redisClient redis.UniversalClient,
+	bulkJobs *usecases.BulkJobRunner,
+	qrRenderer *qrcode.Renderer,
+	interstitial *template.Template,
+) *httpServer.Server {
+	httpHandlers := handlers.NewHandlers(handlers.NewHandlersParams{
+		Logger:   appLogger,
+		UseCase:  useCase,
+		Checker:  checker,
+		BulkJobs: bulkJobs,
+		BulkLimits: handlers.BulkLimits{
+			MaxItems:      cfg.App.BulkMaxItems,
+			MaxAsyncItems: cfg.App.BulkMaxAsyncItems,
+		},
+		QRRenderer:   qrRenderer,
+		Interstitial: interstitial,
+		Idempotency: redisPackage.NewIdempotencyStore(redisPackage.NewIdempotencyStoreParams{
+			Client:  redisClient,
+			TTL:     cfg.Redis.IdempotencyTTL,
+			LockTTL: cfg.Redis.IdempotencyLockTTL,
+		}),
+		IdempotencyLockTTL: cfg.Redis.IdempotencyLockTTL,
+	})
 
 	router := httpServer.NewRouter(httpServer.RouterConfig{
 		Logger:   appLogger,
@@ -144,32 +416,61 @@ func createAndStartServer(cfg *config.Config, appLogger *zap.Logger, useCase *us
 		Handlers: httpHandlers,
 	})
 
-	server := httpServer.NewServer(appLogger, cfg.App.Port, router)
-
-	err := server.Start()
-	if err != nil {
-		return nil, fmt.Errorf("failed to start HTTP server: %w", err)
-	}
+	server := httpServer.NewServer(appLogger, ":"+cfg.App.Port, router)
+	server.OnShutdown(checker.SetDraining)
+	server.SetDrainDelay(cfg.App.ShutdownDrainDelay)
 
-	return server, nil
+	return server
 }
 
-func shutdownServer(ctx context.Context, appLogger *zap.Logger, server *httpServer.Server) {
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
-
-	<-sigChan
-	appLogger.Info("Received shutdown signal")
+func createQRRenderer(cfg *config.Config, appLogger *zap.Logger, redisClient redis.UniversalClient) (*qrcode.Renderer, error) {
+	params := qrcode.NewRendererParams{
+		Cache: redisPackage.NewQRCache(redisClient, cfg.Redis.QRCacheTTL),
+		OnError: func(err error) {
+			appLogger.Warn("QR code cache failed", zap.Error(err))
+		},
+	}
 
-	const shutdownTimeout = 10 * time.Second
+	if cfg.App.QRLogoPath != "" {
+		logo, err := os.Open(cfg.App.QRLogoPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open QR logo: %w", err)
+		}
+		defer logo.Close()
 
-	shutdownCtx, shutdownCancel := context.WithTimeout(ctx, shutdownTimeout)
-	defer shutdownCancel()
+		params.Logo = logo
+	}
 
-	err := server.Shutdown(shutdownCtx)
+	renderer, err := qrcode.NewRenderer(params)
 	if err != nil {
-		appLogger.Error("Error during server shutdown", zap.Error(err))
+		return nil, fmt.Errorf("failed to create QR renderer: %w", err)
 	}
 
-	appLogger.Info("Application stopped")
+	return renderer, nil
+}
+
+func createAdminServer(
+	cfg *config.Config,
+	appLogger *zap.Logger,
+	logLevel zap.AtomicLevel,
+	useCase *usecases.UseCase,
+	qrRenderer *qrcode.Renderer,
+	metricsHandler http.Handler,
+) *httpServer.Server {
+	adminHandler := handlers.NewAdminHandler(handlers.NewAdminHandlerParams{
+		Logger:     appLogger,
+		Counter:    useCase,
+		Cache:      qrRenderer,
+		Workspaces: useCase,
+		Links:      useCase,
+		Metrics:    metricsHandler,
+		Level:      logLevel,
+	})
+
+	router := httpServer.NewAdminRouter(httpServer.AdminRouterConfig{
+		Logger:  appLogger,
+		Handler: adminHandler,
+	})
+
+	return httpServer.NewServer(appLogger, cfg.App.AdminAddr, router)
 }
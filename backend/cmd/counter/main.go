@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"lnk/domain/entities/usecases"
+	"lnk/extensions/config"
+	"lnk/extensions/logger"
+	redisPackage "lnk/extensions/redis"
+	gocqlPackage "lnk/gateways/gocql"
+	"lnk/gateways/gocql/repositories"
+
+	"go.uber.org/zap"
+)
+
+const usage = "usage: counter <status|reconcile>"
+
+// The counter command inspects the Redis ID counter against its Cassandra
+// checkpoint. "status" prints the counter health and drift and exits non-zero
+// when unhealthy; "reconcile" restores a counter that went backwards.
+func main() {
+	if len(os.Args) != 2 || (os.Args[1] != "status" && os.Args[1] != "reconcile") {
+		log.Fatal(usage)
+	}
+
+	ctx := context.Background()
+	cfg, appLogger := setupConfigAndLogger()
+
+	useCase, cleanup, err := setupUseCase(ctx, cfg, appLogger)
+	if err != nil {
+		log.Fatalf("Failed to setup use case: %v", err)
+	}
+
+	status, err := runCommand(ctx, useCase, os.Args[1])
+
+	cleanup()
+
+	if err != nil {
+		log.Fatalf("Failed to %s counter: %v", os.Args[1], err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(status); err != nil {
+		log.Fatalf("Failed to encode status: %v", err)
+	}
+
+	if !status.Healthy {
+		os.Exit(1)
+	}
+}
+
+func runCommand(ctx context.Context, useCase *usecases.UseCase, command string) (*usecases.CounterStatus, error) {
+	if command == "reconcile" {
+		return useCase.ReconcileCounter(ctx)
+	}
+
+	return useCase.CounterStatus(ctx)
+}
+
+func setupConfigAndLogger() (*config.Config, *zap.Logger) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	appLogger, err := logger.NewLogger(cfg.Logger)
+	if err != nil {
+		log.Fatalf("Failed to create logger: %v", err)
+	}
+
+	return cfg, appLogger
+}
+
+func setupUseCase(ctx context.Context, cfg *config.Config, appLogger *zap.Logger) (*usecases.UseCase, func(), error) {
+	session, err := gocqlPackage.SetupDatabase(&cfg.Gocql, appLogger, false)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to setup database: %w", err)
+	}
+
+	redisClient, err := redisPackage.SetupRedis(ctx, &cfg.Redis, appLogger)
+	if err != nil {
+		session.Close()
+		return nil, nil, fmt.Errorf("failed to setup Redis: %w", err)
+	}
+
+	queryPolicy, err := gocqlPackage.NewQueryPolicy(&cfg.Gocql)
+	if err != nil {
+		session.Close()
+		_ = redisClient.Close()
+
+		return nil, nil, fmt.Errorf("failed to build query policy: %w", err)
+	}
+
+	useCase := usecases.NewUseCase(usecases.NewUseCaseParams{
+		Logger:          appLogger,
+		Repository:      repositories.NewRepository(appLogger, session).WithQueryPolicy(queryPolicy),
+		Redis:           redisPackage.NewRedisAdapter(redisClient),
+		Salt:            cfg.App.Base62Salt,
+		CounterKey:      cfg.Redis.CounterKey,
+		CounterRedisKey: cfg.Redis.CounterRedisKey(),
+		CounterHeadroom: cfg.Redis.CounterHeadroom,
+	})
+
+	cleanup := func() {
+		session.Close()
+		_ = redisClient.Close()
+	}
+
+	return useCase, cleanup, nil
+}
@@ -1,12 +1,17 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"os"
 
+	"lnk/domain/entities/usecases"
 	"lnk/extensions/config"
 	"lnk/extensions/logger"
 	gocqlPackage "lnk/gateways/gocql"
+	"lnk/gateways/gocql/repositories"
 
 	gocql "github.com/apache/cassandra-gocql-driver/v2"
 	"go.uber.org/zap"
@@ -23,6 +28,56 @@ func main() {
 	defer session.Close()
 
 	appLogger.Info("Migrations completed successfully")
+
+	if err := backfillDefaultDomain(cfg, appLogger, session); err != nil {
+		log.Fatalf("Failed to backfill links: %v", err)
+	}
+
+	if err := backfillOwnerListing(appLogger, session); err != nil {
+		log.Fatalf("Failed to backfill owner listings: %v", err)
+	}
+}
+
+// backfillOwnerListing adds links created before owner listings to
+// links_by_owner. It is idempotent, so it runs on every migration.
+func backfillOwnerListing(appLogger *zap.Logger, session *gocql.Session) error {
+	indexed, err := repositories.NewRepository(appLogger, session).BackfillOwnerListing(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to backfill owner listings: %w", err)
+	}
+
+	appLogger.Info("Backfilled owner listings", zap.Int("indexed", indexed))
+
+	return nil
+}
+
+// backfillDefaultDomain copies links created before custom domains into the
+// default domain. It is idempotent, so it runs on every migration. The default
+// domain must be configured explicitly: copying into the localhost fallback
+// would leave the links unreachable on the real domain.
+func backfillDefaultDomain(cfg *config.Config, appLogger *zap.Logger, session *gocql.Session) error {
+	if _, ok := os.LookupEnv("PUBLIC_BASE_URL"); !ok {
+		return errors.New("PUBLIC_BASE_URL must be set to backfill the default domain")
+	}
+
+	domains, err := usecases.NewDomains(cfg.App.PublicBaseURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to configure short domains: %w", err)
+	}
+
+	repository := repositories.NewRepository(appLogger, session)
+
+	copied, err := repository.BackfillDefaultDomain(context.Background(), domains.Default())
+	if err != nil {
+		return fmt.Errorf("failed to backfill default domain: %w", err)
+	}
+
+	appLogger.Info("Backfilled links into the default domain",
+		zap.String("domain", domains.Default()),
+		zap.Int("copied", copied),
+	)
+
+	return nil
 }
 
 func setupConfigAndLogger() (*config.Config, *zap.Logger) {
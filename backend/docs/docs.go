@@ -17,161 +17,1735 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/health": {
+        "/api/v1/links": {
             "get": {
-                "description": "Check if the API is running",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "List the links of the caller's owner, newest first. Pass next_cursor from a response as cursor to get the next page, keeping the other parameters unchanged. A page may hold fewer than limit links when filters skip many links; only a missing next_cursor marks the end.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "health"
+                    "links"
+                ],
+                "summary": "List links",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Links per page, 1 to 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only links on this short domain",
+                        "name": "domain",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only links with this tag",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Prefix of the short code or destination host",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only links created at or after this RFC 3339 time",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only links created before this RFC 3339 time",
+                        "name": "created_to",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Health check endpoint",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/handlers.ListLinksResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
                         }
                     }
                 }
             }
         },
-        "/shorten": {
+        "/api/v1/links/bulk": {
             "post": {
-                "description": "Create a short URL from a long URL",
+                "description": "Create short URLs from a JSON array or NDJSON stream of {\"url\": ...} objects, or from CSV with a \"url\" column (or the URLs in the first column). Every item gets its own result. With async=true the links are created in the background and the response points at a job to poll.",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/x-ndjson",
+                    "text/csv"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "urls"
+                    "links"
                 ],
-                "summary": "Create a short URL",
+                "summary": "Create short URLs in bulk",
                 "parameters": [
                     {
-                        "description": "URL to shorten",
+                        "description": "URLs to shorten",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.CreateURLRequest"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handlers.BulkItemRequest"
+                            }
                         }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Create the links in a background job",
+                        "name": "async",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Short domain of the links; the default domain when empty",
+                        "name": "domain",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Replays the first response for retries with the same key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handlers.CreateURLResponse"
+                            "$ref": "#/definitions/handlers.BulkCreateResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.BulkJobResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/handlers.ErrorResponse"
+                            "$ref": "#/definitions/handlers.Problem"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
                         "schema": {
-                            "$ref": "#/definitions/handlers.ErrorResponse"
+                            "$ref": "#/definitions/handlers.Problem"
                         }
                     }
                 }
             }
         },
-        "/{short_url}": {
+        "/api/v1/links/bulk/jobs/{id}": {
             "get": {
-                "description": "Get the original URL from a short URL",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get the progress of a bulk job; results are included once it has finished",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "urls"
+                    "links"
                 ],
-                "summary": "Get original URL by short URL",
+                "summary": "Get a bulk job",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Short URL identifier",
-                        "name": "short_url",
+                        "description": "Job ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "308": {
-                        "description": "Permanent Redirect",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/handlers.BulkJobResponse"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/handlers.ErrorResponse"
+                            "$ref": "#/definitions/handlers.Problem"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "503": {
+                        "description": "Service Unavailable",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/handlers.Problem"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "handlers.CreateURLRequest": {
-            "type": "object",
-            "required": [
-                "url"
-            ],
-            "properties": {
-                "url": {
-                    "type": "string",
-                    "example": "https://example.com"
-                }
-            }
         },
-        "handlers.CreateURLResponse": {
-            "type": "object",
-            "properties": {
-                "original_url": {
-                    "type": "string",
-                    "example": "https://example.com"
-                },
-                "short_url": {
-                    "type": "string",
-                    "example": "abc123"
+        "/api/v1/links/{code}": {
+            "get": {
+                "description": "Get a link of the caller's owner with its title, notes, tags and status, and the metadata fetched from its destination",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "links"
+                ],
+                "summary": "Get a link",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Short domain of the link; the default domain when empty",
+                        "name": "domain",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.LinkResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Edit the title, notes and tags of a link of the caller's owner. Omitted fields are left unchanged, and tags replace the previous tags. Needs the editor role when workspaces are enforced.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "links"
+                ],
+                "summary": "Edit a link",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Short domain of the link; the default domain when empty",
+                        "name": "domain",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Fields to change",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.UpdateLinkRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.LinkResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
                 }
             }
         },
-        "handlers.ErrorResponse": {
-            "type": "object",
-            "properties": {
-                "error": {
-                    "type": "string",
-                    "example": "error message"
+        "/api/v1/links/{code}/qr": {
+            "get": {
+                "description": "Render a QR code for the fully qualified short URL as PNG or SVG. With logo=true the configured logo is drawn in the center and error correction H is used.",
+                "produces": [
+                    "image/png",
+                    "image/svg+xml"
+                ],
+                "tags": [
+                    "links"
+                ],
+                "summary": "Get a QR code for a short link",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short URL identifier",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Short domain of the link; the default domain when empty",
+                        "name": "domain",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "png",
+                        "description": "png or svg",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 256,
+                        "description": "Width and height in pixels (64-2048)",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "M",
+                        "description": "Error correction level: L, M, Q or H",
+                        "name": "ecc",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 4,
+                        "description": "Quiet zone in modules (0-16)",
+                        "name": "margin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "000000",
+                        "description": "Foreground color, RRGGBB or RRGGBBAA",
+                        "name": "fg",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "ffffff",
+                        "description": "Background color, RRGGBB or RRGGBBAA",
+                        "name": "bg",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Draw the configured logo in the center",
+                        "name": "logo",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/workspaces": {
+            "post": {
+                "description": "Create a workspace with the default quota; the calling user becomes its owner. IDs that are taken, including owner IDs that already own links, return 409.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "workspaces"
+                ],
+                "summary": "Create a workspace",
+                "parameters": [
+                    {
+                        "description": "Workspace to create",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CreateWorkspaceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WorkspaceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/workspaces/{id}": {
+            "get": {
+                "description": "Get a workspace with its quota, custom domains and usage in the current month; visible to its members only",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "workspaces"
+                ],
+                "summary": "Get a workspace",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Workspace ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WorkspaceResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/workspaces/{id}/domains": {
+            "post": {
+                "description": "Assign a configured short domain to the workspace within its domain quota; owners only. Only the workspace can then create links on it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "workspaces"
+                ],
+                "summary": "Assign a custom domain",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Workspace ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Domain to assign",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.AddWorkspaceDomainRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WorkspaceDomainResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/workspaces/{id}/domains/{domain}": {
+            "delete": {
+                "description": "Unassign a custom domain from the workspace; owners only. Existing links on it keep working.",
+                "tags": [
+                    "workspaces"
+                ],
+                "summary": "Unassign a custom domain",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Workspace ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Domain",
+                        "name": "domain",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/workspaces/{id}/members": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "workspaces"
+                ],
+                "summary": "List workspace members",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Workspace ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WorkspaceMembersResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/workspaces/{id}/members/{user_id}": {
+            "put": {
+                "description": "Give a user the owner, editor or viewer role in the workspace; owners only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "workspaces"
+                ],
+                "summary": "Add or update a workspace member",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Workspace ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Role of the member",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SetWorkspaceMemberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WorkspaceMemberResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove a user from the workspace; owners only",
+                "tags": [
+                    "workspaces"
+                ],
+                "summary": "Remove a workspace member",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Workspace ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Check if the API is running",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Health check endpoint",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/livez": {
+            "get": {
+                "description": "Report whether the process is alive",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Liveness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/preview/settings": {
+            "post": {
+                "description": "Set or clear the cookie that shows the preview page instead of redirecting for every short link, then redirect back to return. Browsers must post the form from this host.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "tags": [
+                    "urls"
+                ],
+                "summary": "Set the preview preference",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Always show the preview page",
+                        "name": "always",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Path on this host to return to",
+                        "name": "return",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "303": {
+                        "description": "See Other"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/preview/{code}": {
+            "get": {
+                "description": "Render an HTML page with the destination, its fetched title, description and favicon, the creation date and the safety status of a short link on the domain named by the Host header, with a button to continue. The same page is served at /{short_url}+ and, for browsers that opted in, at /{short_url}.",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "urls"
+                ],
+                "summary": "Preview a short link",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short URL identifier",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "HTML page",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Run the dependency checks and report whether the replica can serve traffic",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Readiness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    }
+                }
+            }
+        },
+        "/shorten": {
+            "post": {
+                "description": "Create a short URL from a long URL, optionally with a title, notes and tags. A request deduplicated to an existing link returns that link with its own metadata.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "urls"
+                ],
+                "summary": "Create a short URL",
+                "parameters": [
+                    {
+                        "description": "URL to shorten",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CreateURLRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Replays the first response for retries with the same key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CreateURLResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/{short_url}": {
+            "get": {
+                "description": "Redirect to the original URL of a short URL on the domain named by the Host header. Warned links serve an HTML interstitial whose continue action requests the link again with a continue token that expires after 10 minutes; blocked links serve it without one, and disabled links are gone.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/html"
+                ],
+                "tags": [
+                    "urls"
+                ],
+                "summary": "Get original URL by short URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short URL identifier",
+                        "name": "short_url",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token from the interstitial that continues past the warning of a flagged link",
+                        "name": "continue",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Interstitial of a warned link",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "302": {
+                        "description": "Found"
+                    },
+                    "308": {
+                        "description": "Permanent Redirect"
+                    },
+                    "403": {
+                        "description": "Interstitial of a blocked link",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "410": {
+                        "description": "Gone",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Problem"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "entities.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.AddWorkspaceDomainRequest": {
+            "type": "object",
+            "required": [
+                "domain"
+            ],
+            "properties": {
+                "domain": {
+                    "type": "string",
+                    "example": "go.example.com"
+                }
+            }
+        },
+        "handlers.BulkCreateResponse": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "failed": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.BulkItemResponse"
+                    }
+                }
+            }
+        },
+        "handlers.BulkItemError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "validation_failed"
+                },
+                "detail": {
+                    "type": "string",
+                    "example": "URL must be an absolute http or https URL"
+                }
+            }
+        },
+        "handlers.BulkItemRequest": {
+            "type": "object",
+            "properties": {
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com"
+                }
+            }
+        },
+        "handlers.BulkItemResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "$ref": "#/definitions/handlers.BulkItemError"
+                },
+                "index": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "original_url": {
+                    "type": "string",
+                    "example": "https://example.com"
+                },
+                "short_code": {
+                    "type": "string",
+                    "example": "abc123"
+                },
+                "short_url": {
+                    "type": "string",
+                    "example": "https://go.example.com/abc123"
+                }
+            }
+        },
+        "handlers.BulkJobResponse": {
+            "type": "object",
+            "properties": {
+                "completed": {
+                    "type": "integer",
+                    "example": 12000
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "domain": {
+                    "type": "string",
+                    "example": "go.example.com"
+                },
+                "failed": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "id": {
+                    "type": "string",
+                    "example": "6f1c2b0e-2f0a-4b8e-9d0c-2f7f1f0f7f3a"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.BulkItemResponse"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "running"
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 50000
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.CreateURLRequest": {
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "domain": {
+                    "description": "Domain is the short domain of the link; the default domain when empty.",
+                    "type": "string",
+                    "example": "go.example.com"
+                },
+                "notes": {
+                    "type": "string",
+                    "example": "Linked from the March newsletter"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "spring-sale",
+                        "newsletter"
+                    ]
+                },
+                "title": {
+                    "description": "Title, Notes and Tags require an owner.",
+                    "type": "string",
+                    "example": "Spring sale landing page"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com"
+                }
+            }
+        },
+        "handlers.CreateURLResponse": {
+            "type": "object",
+            "properties": {
+                "domain": {
+                    "type": "string",
+                    "example": "go.example.com"
+                },
+                "notes": {
+                    "type": "string",
+                    "example": "Linked from the March newsletter"
+                },
+                "original_url": {
+                    "type": "string",
+                    "example": "https://example.com"
+                },
+                "short_code": {
+                    "type": "string",
+                    "example": "abc123"
+                },
+                "short_url": {
+                    "type": "string",
+                    "example": "https://go.example.com/abc123"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "spring-sale",
+                        "newsletter"
+                    ]
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Spring sale landing page"
+                }
+            }
+        },
+        "handlers.CreateWorkspaceRequest": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "id": {
+                    "type": "string",
+                    "example": "marketing"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Marketing"
+                }
+            }
+        },
+        "handlers.LinkPreviewResponse": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "This domain is for use in documentation examples."
+                },
+                "favicon_url": {
+                    "type": "string",
+                    "example": "https://example.com/favicon.ico"
+                },
+                "fetched_at": {
+                    "type": "string"
+                },
+                "image_url": {
+                    "type": "string",
+                    "example": "https://example.com/og.png"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "fetched"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Example Domain"
+                }
+            }
+        },
+        "handlers.LinkResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "domain": {
+                    "type": "string",
+                    "example": "go.example.com"
+                },
+                "notes": {
+                    "type": "string",
+                    "example": "Linked from the March newsletter"
+                },
+                "original_url": {
+                    "type": "string",
+                    "example": "https://example.com"
+                },
+                "preview": {
+                    "description": "Preview is only returned for a single link, once its destination has\nbeen fetched.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/handlers.LinkPreviewResponse"
+                        }
+                    ]
+                },
+                "short_code": {
+                    "type": "string",
+                    "example": "abc123"
+                },
+                "short_url": {
+                    "type": "string",
+                    "example": "https://go.example.com/abc123"
+                },
+                "status": {
+                    "description": "Status is only returned for a single link. StatusReason tells visitors\nwhy a warned or blocked link was flagged.",
+                    "type": "string",
+                    "enum": [
+                        "active",
+                        "warned",
+                        "blocked",
+                        "disabled"
+                    ],
+                    "example": "active"
+                },
+                "status_reason": {
+                    "type": "string",
+                    "example": "Reported as phishing"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "spring-sale",
+                        "newsletter"
+                    ]
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Spring sale landing page"
+                },
+                "updated_at": {
+                    "description": "UpdatedAt is omitted until the link is edited.",
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.ListLinksResponse": {
+            "type": "object",
+            "properties": {
+                "links": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.LinkResponse"
+                    }
+                },
+                "next_cursor": {
+                    "description": "NextCursor fetches the next page; it is omitted on the last page.",
+                    "type": "string",
+                    "example": "q1v0Zf3kS2MAAAAB"
+                }
+            }
+        },
+        "handlers.Problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "not_found"
+                },
+                "detail": {
+                    "type": "string",
+                    "example": "URL not found"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.FieldError"
+                    }
+                },
+                "instance": {
+                    "type": "string",
+                    "example": "/abc123"
+                },
+                "request_id": {
+                    "type": "string",
+                    "example": "5f0c6f1e-7f2a-4c1e-9a57-0d5c3b1f2e44"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Not Found"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "urn:lnk:problem:not_found"
+                }
+            }
+        },
+        "handlers.SetWorkspaceMemberRequest": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "type": "string",
+                    "example": "editor"
+                }
+            }
+        },
+        "handlers.UpdateLinkRequest": {
+            "type": "object",
+            "properties": {
+                "notes": {
+                    "type": "string",
+                    "example": "Linked from the March newsletter"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "spring-sale",
+                        "newsletter"
+                    ]
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Spring sale landing page"
+                }
+            }
+        },
+        "handlers.WorkspaceDomainResponse": {
+            "type": "object",
+            "properties": {
+                "domain": {
+                    "type": "string",
+                    "example": "go.example.com"
+                }
+            }
+        },
+        "handlers.WorkspaceMemberResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string",
+                    "example": "editor"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "alice"
+                }
+            }
+        },
+        "handlers.WorkspaceMembersResponse": {
+            "type": "object",
+            "properties": {
+                "members": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.WorkspaceMemberResponse"
+                    }
+                }
+            }
+        },
+        "handlers.WorkspaceQuotaResponse": {
+            "type": "object",
+            "properties": {
+                "domains": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "links_per_month": {
+                    "type": "integer",
+                    "example": 10000
+                }
+            }
+        },
+        "handlers.WorkspaceResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "domains": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string",
+                    "example": "marketing"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Marketing"
+                },
+                "quota": {
+                    "$ref": "#/definitions/handlers.WorkspaceQuotaResponse"
+                },
+                "role": {
+                    "type": "string",
+                    "example": "owner"
+                },
+                "usage": {
+                    "$ref": "#/definitions/handlers.WorkspaceUsageResponse"
+                }
+            }
+        },
+        "handlers.WorkspaceUsageResponse": {
+            "type": "object",
+            "properties": {
+                "domains": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "links": {
+                    "type": "integer",
+                    "example": 1250
+                },
+                "period": {
+                    "type": "string",
+                    "example": "2026-10"
+                }
+            }
+        },
+        "health.CheckResult": {
+            "type": "object",
+            "properties": {
+                "duration": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "health.Report": {
+            "type": "object",
+            "properties": {
+                "checked_at": {
+                    "type": "string"
+                },
+                "checks": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/health.CheckResult"
+                    }
+                },
+                "status": {
+                    "type": "string"
                 }
             }
         }
@@ -0,0 +1,42 @@
+package entities
+
+import "time"
+
+type BulkJobStatus string
+
+const (
+	BulkJobPending   BulkJobStatus = "pending"
+	BulkJobRunning   BulkJobStatus = "running"
+	BulkJobCompleted BulkJobStatus = "completed"
+	BulkJobFailed    BulkJobStatus = "failed"
+)
+
+// BulkItemResult is the outcome of one item of a bulk creation. Exactly one
+// of ShortCode and Error is set; Index is the item's position in the input.
+type BulkItemResult struct {
+	Error     *Error
+	ShortCode string
+	LongURL   string
+	Index     int
+}
+
+// BulkJob tracks an asynchronous bulk creation. Results are only populated
+// once the job has finished.
+type BulkJob struct {
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	ID        string
+	OwnerID   string
+	// Domain is the short domain of every link created by the job.
+	Domain    string
+	Status    BulkJobStatus
+	Results   []BulkItemResult
+	Total     int
+	Completed int
+	Failed    int
+}
+
+// Done reports whether the job will make no further progress.
+func (j *BulkJob) Done() bool {
+	return j.Status == BulkJobCompleted || j.Status == BulkJobFailed
+}
@@ -0,0 +1,13 @@
+package entities
+
+import "time"
+
+// CounterCheckpoint is the last Redis counter value persisted in Cassandra.
+// HighWaterMark is an upper bound on every ID handed out until the next
+// checkpoint, so the counter can be restored from it after Redis data loss.
+type CounterCheckpoint struct {
+	UpdatedAt     time.Time
+	Name          string
+	ObservedValue int64
+	HighWaterMark int64
+}
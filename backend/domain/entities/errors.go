@@ -0,0 +1,126 @@
+package entities
+
+import "errors"
+
+// ErrorCode is the machine-readable kind of a domain error. Codes are part of
+// the public API and must not change once published.
+type ErrorCode string
+
+const (
+	CodeNotFound   ErrorCode = "not_found"
+	CodeGone       ErrorCode = "gone"
+	CodeConflict   ErrorCode = "conflict"
+	CodeValidation ErrorCode = "validation_failed"
+	// CodeUnprocessable is a well-formed request that conflicts with an
+	// earlier one, such as a reused Idempotency-Key.
+	CodeUnprocessable    ErrorCode = "unprocessable"
+	CodeTooLarge         ErrorCode = "too_large"
+	CodeUnsupportedMedia ErrorCode = "unsupported_media_type"
+	CodeForbidden        ErrorCode = "forbidden"
+	CodeRateLimited      ErrorCode = "rate_limited"
+	// CodeQuotaExceeded is a workspace quota that is used up for the current
+	// period.
+	CodeQuotaExceeded ErrorCode = "quota_exceeded"
+	CodeUnavailable   ErrorCode = "unavailable"
+	CodeInternal      ErrorCode = "internal"
+)
+
+// The catalog of domain errors. Use cases derive specific errors from these
+// with WithMessage so callers can match either the specific error or its kind
+// with errors.Is.
+var (
+	ErrNotFound         = NewError(CodeNotFound, "resource not found")
+	ErrGone             = NewError(CodeGone, "resource is no longer available")
+	ErrConflict         = NewError(CodeConflict, "resource already exists")
+	ErrValidation       = NewError(CodeValidation, "request is invalid")
+	ErrUnprocessable    = NewError(CodeUnprocessable, "request cannot be processed")
+	ErrTooLarge         = NewError(CodeTooLarge, "request is too large")
+	ErrUnsupportedMedia = NewError(CodeUnsupportedMedia, "request content type is not supported")
+	ErrForbidden        = NewError(CodeForbidden, "operation is not allowed")
+	ErrRateLimited      = NewError(CodeRateLimited, "too many requests")
+	ErrQuotaExceeded    = NewError(CodeQuotaExceeded, "quota exceeded")
+	ErrUnavailable      = NewError(CodeUnavailable, "service is temporarily unavailable")
+)
+
+// FieldError describes one invalid input field of a validation error.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error is a domain error whose Message is safe to show to clients. The
+// wrapped cause carries the internal details and is only logged.
+type Error struct {
+	base    *Error
+	cause   error
+	Code    ErrorCode
+	Message string
+	Fields  []FieldError
+}
+
+func NewError(code ErrorCode, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// Wrap returns a copy of e with cause attached. errors.Is still matches e, so
+// catalog errors can be used as sentinels.
+func (e *Error) Wrap(cause error) *Error {
+	wrapped := e.derive()
+	wrapped.cause = cause
+
+	return wrapped
+}
+
+// WithMessage returns a copy of e with a more specific client message.
+func (e *Error) WithMessage(message string) *Error {
+	withMessage := e.derive()
+	withMessage.Message = message
+
+	return withMessage
+}
+
+// WithFields returns a copy of e describing the invalid input fields.
+func (e *Error) WithFields(fields ...FieldError) *Error {
+	withFields := e.derive()
+	withFields.Fields = append(append([]FieldError(nil), e.Fields...), fields...)
+
+	return withFields
+}
+
+func (e *Error) derive() *Error {
+	derived := *e
+	derived.base = e
+
+	return &derived
+}
+
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return e.Message + ": " + e.cause.Error()
+	}
+
+	return e.Message
+}
+
+func (e *Error) Unwrap() []error {
+	errs := make([]error, 0, 2)
+	if e.base != nil {
+		errs = append(errs, e.base)
+	}
+
+	if e.cause != nil {
+		errs = append(errs, e.cause)
+	}
+
+	return errs
+}
+
+// AsError returns the domain error in err's chain, or nil.
+func AsError(err error) *Error {
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return domainErr
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package entities_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"lnk/domain/entities"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Error(t *testing.T) {
+	t.Parallel()
+
+	errLinkNotFound := entities.ErrNotFound.WithMessage("link not found")
+	cause := errors.New("connection refused")
+
+	err := fmt.Errorf("handler: %w", errLinkNotFound.Wrap(cause))
+
+	require.ErrorIs(t, err, errLinkNotFound)
+	require.ErrorIs(t, err, entities.ErrNotFound)
+	require.ErrorIs(t, err, cause)
+	require.NotErrorIs(t, err, entities.ErrGone)
+
+	domainErr := entities.AsError(err)
+	require.NotNil(t, domainErr)
+	require.Equal(t, entities.CodeNotFound, domainErr.Code)
+	require.Equal(t, "link not found", domainErr.Message)
+	require.Equal(t, "handler: link not found: connection refused", err.Error())
+
+	require.Nil(t, entities.AsError(cause))
+}
+
+func Test_Error_WithFields(t *testing.T) {
+	t.Parallel()
+
+	err := entities.ErrValidation.WithFields(entities.FieldError{Field: "url", Message: "is required"})
+
+	require.ErrorIs(t, err, entities.ErrValidation)
+	require.Equal(t, []entities.FieldError{{Field: "url", Message: "is required"}}, err.Fields)
+	require.Empty(t, entities.ErrValidation.Fields, "catalog errors must not be mutated")
+}
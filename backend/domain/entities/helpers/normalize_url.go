@@ -0,0 +1,56 @@
+package helpers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// NormalizeURL returns a canonical form of rawURL for comparing destinations:
+// the scheme and host are lower-cased, default ports, empty user info and the
+// fragment are dropped, and an empty path becomes "/". The path and query are
+// kept as-is because servers may treat them case- and order-sensitively.
+func NormalizeURL(rawURL string) (string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse URL: %w", err)
+	}
+
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	parsed.Fragment = ""
+	parsed.RawFragment = ""
+
+	host := strings.ToLower(parsed.Hostname())
+	if port := parsed.Port(); port != "" && port != defaultPorts[parsed.Scheme] {
+		host += ":" + port
+	} else if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+
+	parsed.Host = host
+
+	if parsed.User != nil && parsed.User.String() == "" {
+		parsed.User = nil
+	}
+
+	if parsed.Path == "" && parsed.Opaque == "" {
+		parsed.Path = "/"
+	}
+
+	return parsed.String(), nil
+}
+
+// HashURL returns the hex SHA-256 of a normalized URL, used as a fixed-size
+// lookup key.
+func HashURL(normalizedURL string) string {
+	sum := sha256.Sum256([]byte(normalizedURL))
+
+	return hex.EncodeToString(sum[:])
+}
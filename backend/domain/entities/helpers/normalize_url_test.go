@@ -0,0 +1,33 @@
+package helpers_test
+
+import (
+	"testing"
+
+	"lnk/domain/entities/helpers"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Helper_NormalizeURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"https://Example.COM", "https://example.com/"},
+		{"HTTPS://example.com:443/Path?b=2&a=1#top", "https://example.com/Path?b=2&a=1"},
+		{"http://example.com:80/", "http://example.com/"},
+		{"http://example.com:8080/a", "http://example.com:8080/a"},
+		{" https://[::1]:443/ ", "https://[::1]/"},
+	}
+
+	for _, test := range tests {
+		got, err := helpers.NormalizeURL(test.in)
+		require.NoError(t, err, test.in)
+		require.Equal(t, test.want, got, test.in)
+	}
+
+	_, err := helpers.NormalizeURL("http://[::1")
+	require.Error(t, err)
+}
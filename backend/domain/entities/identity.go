@@ -0,0 +1,25 @@
+package entities
+
+import "context"
+
+// Identity is the caller of a request as asserted by the trusted gateway in
+// front of the service. Empty fields mean the gateway did not identify the
+// caller.
+type Identity struct {
+	OwnerID string
+	UserID  string
+}
+
+type identityKey struct{}
+
+func WithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFromContext returns the caller stored by WithIdentity, or the zero
+// Identity for anonymous requests.
+func IdentityFromContext(ctx context.Context) Identity {
+	identity, _ := ctx.Value(identityKey{}).(Identity)
+
+	return identity
+}
@@ -0,0 +1,34 @@
+package entities
+
+import "time"
+
+// PageMetadata is what a destination page says about itself.
+type PageMetadata struct {
+	Title       string
+	Description string
+	FaviconURL  string
+	// Favicon is the favicon inlined as a data: URI, empty if it could not be
+	// fetched.
+	Favicon string
+	// ImageURL is the page's Open Graph image.
+	ImageURL string
+}
+
+type PreviewStatus string
+
+const (
+	PreviewFetched PreviewStatus = "fetched"
+	// PreviewFailed is a destination whose page could not be fetched: it is
+	// not HTML, robots.txt disallows it, it resolves to an internal address,
+	// or it kept failing.
+	PreviewFailed PreviewStatus = "failed"
+)
+
+// LinkPreview is the fetched metadata of a link's destination.
+type LinkPreview struct {
+	FetchedAt time.Time
+	Domain    string
+	ShortCode string
+	Status    PreviewStatus
+	Page      PageMetadata
+}
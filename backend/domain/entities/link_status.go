@@ -0,0 +1,24 @@
+package entities
+
+// LinkStatus decides how a short link is served.
+type LinkStatus string
+
+const (
+	// LinkActive links redirect.
+	LinkActive LinkStatus = "active"
+	// LinkWarned links serve a warning that visitors can continue past.
+	LinkWarned LinkStatus = "warned"
+	// LinkBlocked links serve the warning without a way to continue.
+	LinkBlocked LinkStatus = "blocked"
+	// LinkDisabled links are gone.
+	LinkDisabled LinkStatus = "disabled"
+)
+
+func (s LinkStatus) Valid() bool {
+	switch s {
+	case LinkActive, LinkWarned, LinkBlocked, LinkDisabled:
+		return true
+	default:
+		return false
+	}
+}
@@ -6,6 +6,23 @@ import (
 
 type URL struct {
 	CreatedAt time.Time
+	// UpdatedAt is the time of the last edit; it is zero until the link is
+	// edited.
+	UpdatedAt time.Time
+	// Domain is the short domain the link is served from. The same ShortCode
+	// may exist on several domains.
+	Domain    string
 	ShortCode string
 	LongURL   string
+	// OwnerID is the tenant that created the link; empty for anonymous links.
+	OwnerID string
+	// Title, Notes and Tags are set by the owner. Tags are lower-case and
+	// sorted.
+	Title string
+	Notes string
+	// Status decides how the link is served; an empty Status is active.
+	// StatusReason tells visitors why a link is not active.
+	Status       LinkStatus
+	StatusReason string
+	Tags         []string
 }
@@ -0,0 +1,216 @@
+package usecases
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"sync/atomic"
+
+	"lnk/domain/entities"
+	"lnk/extensions/logger"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+const defaultBulkConcurrency = 16
+
+var ErrInvalidURL = entities.ErrValidation.WithMessage("URL must be an absolute http or https URL")
+
+// CreateShortURLs creates a short code on domain, or on the default domain
+// when domain is empty, for every URL in longURLs and returns
+// one result per item, in input order. Items fail individually: an invalid
+// URL or a failed write does not affect the others.
+//
+// Counter values come from the same allocator as single creations, so both
+// draw from one sequence, and the links are written with bounded parallelism. When workspaces are
+// enforced, the batch is reserved against the workspace quota as a whole, so
+// a batch that does not fit fails entirely. When deduplication applies to the
+// caller, every item goes through CreateShortURL instead, so it can reuse an
+// existing code. onProgress, when set, is called concurrently with
+// the number of finished items.
+func (uc *UseCase) CreateShortURLs(ctx context.Context, domain string, longURLs []string, onProgress func(completed int)) []entities.BulkItemResult {
+	ctx, span := otel.Tracer("usecases.CreateShortURLs").Start(ctx, "CreateShortURLsUsecase")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("bulk.items", len(longURLs)))
+
+	results := make([]entities.BulkItemResult, len(longURLs))
+	valid := make([]int, 0, len(longURLs))
+
+	domain, domainErr := uc.domains.Validate(domain)
+
+	for i, longURL := range longURLs {
+		results[i] = entities.BulkItemResult{Index: i, LongURL: longURL}
+
+		if domainErr != nil {
+			results[i].Error = ErrUnknownDomain
+			continue
+		}
+
+		if err := validateLongURL(longURL); err != nil {
+			results[i].Error = err
+			continue
+		}
+
+		valid = append(valid, i)
+	}
+
+	var completed atomic.Int64
+
+	report := func(n int) {
+		done := completed.Add(int64(n))
+		if onProgress != nil {
+			onProgress(int(done))
+		}
+	}
+
+	report(len(longURLs) - len(valid))
+
+	if len(valid) == 0 {
+		return results
+	}
+
+	ownerID := entities.IdentityFromContext(ctx).OwnerID
+
+	if uc.dedup.applies(ownerID) {
+		uc.runBounded(len(valid), func(j int) {
+			result := &results[valid[j]]
+
+			url, err := uc.CreateShortURL(ctx, domain, result.LongURL)
+			if err != nil {
+				result.Error = itemError(err)
+			} else {
+				result.ShortCode = url.ShortCode
+			}
+
+			report(1)
+		})
+
+		uc.logBulkFailures(ctx, results)
+
+		return results
+	}
+
+	admission, err := uc.admitLinks(ctx, domain, len(valid))
+	if err != nil {
+		failure := itemError(err)
+		for _, i := range valid {
+			results[i].Error = failure
+		}
+
+		report(len(valid))
+
+		uc.logBulkFailures(ctx, results)
+
+		return results
+	}
+
+	var failed atomic.Int64
+
+	uc.runBounded(len(valid), func(j int) {
+		result := &results[valid[j]]
+
+		shortCode, err := uc.nextShortCode(ctx)
+		if err != nil {
+			result.Error = itemError(err)
+			failed.Add(1)
+			report(1)
+
+			return
+		}
+
+		url := &entities.URL{Domain: domain, ShortCode: shortCode, LongURL: result.LongURL, OwnerID: ownerID}
+		if err := uc.storeURL(ctx, url); err != nil {
+			result.Error = itemError(err)
+			failed.Add(1)
+		} else {
+			result.ShortCode = shortCode
+		}
+
+		report(1)
+	})
+
+	admission.commit(ctx, len(valid)-int(failed.Load()))
+	admission.release(ctx, int(failed.Load()))
+
+	uc.logBulkFailures(ctx, results)
+
+	return results
+}
+
+// logBulkFailures logs the first server-side item failure, so an outage
+// during a large batch produces one log line rather than one per item.
+func (uc *UseCase) logBulkFailures(ctx context.Context, results []entities.BulkItemResult) {
+	var (
+		failed int
+		first  *entities.Error
+	)
+
+	for _, result := range results {
+		if result.Error == nil || result.Error.Code == entities.CodeValidation {
+			continue
+		}
+
+		failed++
+
+		if first == nil {
+			first = result.Error
+		}
+	}
+
+	if first != nil {
+		logger.FromContext(ctx, uc.logger).Error("Bulk items failed",
+			zap.Int("failed", failed),
+			zap.Int("total", len(results)),
+			zap.Error(first),
+		)
+	}
+}
+
+// runBounded calls fn for 0..n-1 with at most bulkConcurrency calls in
+// flight.
+func (uc *UseCase) runBounded(n int, fn func(j int)) {
+	slots := make(chan struct{}, uc.bulkConcurrency)
+
+	var wg sync.WaitGroup
+
+	for j := range n {
+		slots <- struct{}{}
+
+		wg.Add(1)
+
+		go func() {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+
+			fn(j)
+		}()
+	}
+
+	wg.Wait()
+}
+
+func validateLongURL(longURL string) *entities.Error {
+	parsed, err := url.Parse(longURL)
+	if err != nil {
+		return ErrInvalidURL.Wrap(err)
+	}
+
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return ErrInvalidURL
+	}
+
+	return nil
+}
+
+func itemError(err error) *entities.Error {
+	if domainErr := entities.AsError(err); domainErr != nil {
+		return domainErr
+	}
+
+	return entities.NewError(entities.CodeInternal, "internal server error").Wrap(err)
+}
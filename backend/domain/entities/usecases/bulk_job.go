@@ -0,0 +1,234 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"lnk/domain/entities"
+	"lnk/extensions/logger"
+	"lnk/extensions/redis"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultBulkProgressInterval = time.Second
+	bulkJobSaveTimeout          = 5 * time.Second
+)
+
+var (
+	ErrBulkJobNotFound           = entities.ErrNotFound.WithMessage("bulk job not found")
+	ErrBulkJobStorageUnavailable = entities.ErrUnavailable.WithMessage("bulk job storage is temporarily unavailable")
+	ErrBulkJobsStopped           = entities.ErrUnavailable.WithMessage("bulk jobs are not accepted while the service shuts down")
+)
+
+// BulkJobStore persists bulk jobs so that any replica can report their
+// progress. Get returns redis.ErrKeyNotFound for unknown or expired jobs.
+type BulkJobStore interface {
+	Save(ctx context.Context, job *entities.BulkJob) error
+	Get(ctx context.Context, id string) (*entities.BulkJob, error)
+}
+
+// BulkJobRunner runs bulk creations in the background and records their
+// progress in a BulkJobStore. Jobs run on the replica that accepted them;
+// Stop cancels the jobs still running, which then finish as failed.
+type BulkJobRunner struct {
+	logger           *zap.Logger
+	useCase          *UseCase
+	store            BulkJobStore
+	ctx              context.Context //nolint:containedctx // scopes background jobs to the runner lifetime
+	cancel           context.CancelFunc
+	wg               sync.WaitGroup
+	progressInterval time.Duration
+	mu               sync.Mutex
+	stopped          bool
+}
+
+type NewBulkJobRunnerParams struct {
+	Logger  *zap.Logger
+	UseCase *UseCase
+	Store   BulkJobStore
+	// ProgressInterval is the minimum time between progress updates of a
+	// running job. It defaults to one second.
+	ProgressInterval time.Duration
+}
+
+func NewBulkJobRunner(params NewBulkJobRunnerParams) *BulkJobRunner {
+	progressInterval := params.ProgressInterval
+	if progressInterval <= 0 {
+		progressInterval = defaultBulkProgressInterval
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &BulkJobRunner{
+		logger:           params.Logger,
+		useCase:          params.UseCase,
+		store:            params.Store,
+		ctx:              ctx,
+		cancel:           cancel,
+		progressInterval: progressInterval,
+	}
+}
+
+// Start records a pending job for longURLs on domain and creates the links in
+// the background. The job belongs to the caller's owner and keeps the caller's
+// request ID and trace for its logs and spans.
+func (r *BulkJobRunner) Start(ctx context.Context, domain string, longURLs []string) (*entities.BulkJob, error) {
+	domain, err := r.useCase.ValidateDomain(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	job := &entities.BulkJob{
+		ID:        uuid.NewString(),
+		Domain:    domain,
+		OwnerID:   entities.IdentityFromContext(ctx).OwnerID,
+		Status:    entities.BulkJobPending,
+		Total:     len(longURLs),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stopped {
+		return nil, ErrBulkJobsStopped
+	}
+
+	if err := r.store.Save(ctx, job); err != nil {
+		return nil, ErrBulkJobStorageUnavailable.Wrap(err)
+	}
+
+	jobCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(r.ctx, cancel)
+
+	snapshot := *job
+
+	r.wg.Add(1)
+
+	go func() {
+		defer r.wg.Done()
+		defer stop()
+		defer cancel()
+
+		r.run(jobCtx, job, longURLs)
+	}()
+
+	return &snapshot, nil
+}
+
+// Get returns the caller's job. Jobs of other owners are reported as not
+// found.
+func (r *BulkJobRunner) Get(ctx context.Context, id string) (*entities.BulkJob, error) {
+	job, err := r.store.Get(ctx, id)
+	if errors.Is(err, redis.ErrKeyNotFound) {
+		return nil, ErrBulkJobNotFound
+	}
+
+	if err != nil {
+		return nil, ErrBulkJobStorageUnavailable.Wrap(err)
+	}
+
+	if job.OwnerID != entities.IdentityFromContext(ctx).OwnerID {
+		return nil, ErrBulkJobNotFound
+	}
+
+	return job, nil
+}
+
+// Stop cancels running jobs and waits until they have recorded their final
+// state, or until ctx is done.
+func (r *BulkJobRunner) Stop(ctx context.Context) error {
+	r.mu.Lock()
+	r.stopped = true
+	r.mu.Unlock()
+
+	r.cancel()
+
+	done := make(chan struct{})
+
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to stop bulk jobs: %w", ctx.Err())
+	}
+}
+
+func (r *BulkJobRunner) run(ctx context.Context, job *entities.BulkJob, longURLs []string) {
+	log := logger.FromContext(ctx, r.logger).With(zap.String("job_id", job.ID))
+
+	var (
+		mu        sync.Mutex
+		lastSaved time.Time
+	)
+
+	save := func() {
+		job.UpdatedAt = time.Now().UTC()
+		snapshot := *job
+
+		saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), bulkJobSaveTimeout)
+		defer cancel()
+
+		if err := r.store.Save(saveCtx, &snapshot); err != nil {
+			log.Warn("Failed to save bulk job", zap.Error(err))
+		}
+
+		lastSaved = time.Now()
+	}
+
+	mu.Lock()
+	job.Status = entities.BulkJobRunning
+	save()
+	mu.Unlock()
+
+	results := r.useCase.CreateShortURLs(ctx, job.Domain, longURLs, func(completed int) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		// Progress is reported concurrently, so only ever move it forward.
+		job.Completed = max(job.Completed, completed)
+
+		if time.Since(lastSaved) >= r.progressInterval {
+			save()
+		}
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	job.Results = results
+	job.Completed = len(results)
+	job.Failed = 0
+
+	for _, result := range results {
+		if result.Error != nil {
+			job.Failed++
+		}
+	}
+
+	job.Status = entities.BulkJobCompleted
+	if ctx.Err() != nil {
+		job.Status = entities.BulkJobFailed
+	}
+
+	save()
+
+	log.Info("Bulk job finished",
+		zap.String("status", string(job.Status)),
+		zap.Int("total", job.Total),
+		zap.Int("failed", job.Failed),
+	)
+}
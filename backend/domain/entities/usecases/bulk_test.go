@@ -0,0 +1,108 @@
+package usecases_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"lnk/domain/entities/usecases"
+	"lnk/extensions/gocqltesting"
+	"lnk/extensions/redis/mocks"
+	"lnk/gateways/gocql/repositories"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func Test_UseCase_CreateShortURLs(t *testing.T) {
+	t.Parallel()
+
+	session, err := gocqltesting.NewDB(t, t.Name())
+	require.NoError(t, err)
+
+	logger := zap.NewNop()
+
+	var counter atomic.Int64
+
+	mockRedis := mocks.NewMockRedis(t)
+	mockRedis.On("Incr", mock.Anything, "test").Return(func(context.Context, string) (int64, error) {
+		return counter.Add(1), nil
+	}).Twice()
+
+	useCase := usecases.NewUseCase(usecases.NewUseCaseParams{
+		Logger:     logger,
+		Repository: repositories.NewRepository(logger, session),
+		Redis:      mockRedis,
+		Salt:       "test",
+		CounterKey: "test",
+	})
+
+	var progress atomic.Int64
+
+	results := useCase.CreateShortURLs(context.Background(), "",
+		[]string{"https://a.example", "not a url", "https://b.example"},
+		func(completed int) {
+			for {
+				current := progress.Load()
+				if int64(completed) <= current || progress.CompareAndSwap(current, int64(completed)) {
+					return
+				}
+			}
+		},
+	)
+
+	require.Len(t, results, 3)
+	require.NotEmpty(t, results[0].ShortCode)
+	require.NotEmpty(t, results[2].ShortCode)
+	require.NotEqual(t, results[0].ShortCode, results[2].ShortCode)
+	require.True(t, errors.Is(results[1].Error, usecases.ErrInvalidURL))
+	require.Equal(t, 1, results[1].Index)
+	require.Equal(t, int64(3), progress.Load())
+}
+
+func Test_UseCase_CreateShortURLs_SharesCounterWithSingleCreation(t *testing.T) {
+	t.Parallel()
+
+	session, err := gocqltesting.NewDB(t, t.Name())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	logger := zap.NewNop()
+
+	var counter atomic.Int64
+
+	// Only the hash-tagged key is incremented; the logical key names the
+	// checkpoints and is never used as a Redis key.
+	mockRedis := mocks.NewMockRedis(t)
+	mockRedis.On("Incr", mock.Anything, "{lnk}:counter").Return(func(context.Context, string) (int64, error) {
+		return counter.Add(1), nil
+	})
+
+	useCase := usecases.NewUseCase(usecases.NewUseCaseParams{
+		Logger:          logger,
+		Repository:      repositories.NewRepository(logger, session),
+		Redis:           mockRedis,
+		Salt:            "test",
+		CounterKey:      "counter",
+		CounterRedisKey: "{lnk}:counter",
+	})
+
+	single, err := useCase.CreateShortURL(ctx, "", "https://a.example")
+	require.NoError(t, err)
+
+	results := useCase.CreateShortURLs(ctx, "", []string{"https://b.example", "https://c.example"}, nil)
+
+	again, err := useCase.CreateShortURL(ctx, "", "https://d.example")
+	require.NoError(t, err)
+
+	codes := map[string]bool{single.ShortCode: true, again.ShortCode: true}
+	for _, result := range results {
+		require.Nil(t, result.Error)
+		codes[result.ShortCode] = true
+	}
+
+	require.Len(t, codes, 4)
+	require.Equal(t, int64(4), counter.Load())
+}
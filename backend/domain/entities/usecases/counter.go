@@ -0,0 +1,203 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"lnk/domain/entities"
+	"lnk/extensions/logger"
+	"lnk/extensions/redis"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.uber.org/zap"
+)
+
+// CounterStatus describes the Redis counter relative to its last checkpoint.
+// Drift is the number of IDs handed out since the checkpoint; a negative
+// drift means Redis lost data and the counter went backwards. Key names the
+// checkpoint and RedisKey the counter in Redis, which adds any hash tag.
+type CounterStatus struct {
+	CheckpointedAt  time.Time `json:"checkpointed_at"`
+	Key             string    `json:"key"`
+	RedisKey        string    `json:"redis_key"`
+	RedisValue      int64     `json:"redis_value"`
+	CheckpointValue int64     `json:"checkpoint_value"`
+	HighWaterMark   int64     `json:"high_water_mark"`
+	Drift           int64     `json:"drift"`
+	RedisMissing    bool      `json:"redis_missing"`
+	HasCheckpoint   bool      `json:"has_checkpoint"`
+	Healthy         bool      `json:"healthy"`
+}
+
+// CounterStatus compares the Redis counter with the checkpoint in Cassandra.
+func (uc *UseCase) CounterStatus(ctx context.Context) (*CounterStatus, error) {
+	status := &CounterStatus{Key: uc.counterKey, RedisKey: uc.counterRedisKey}
+
+	value, err := uc.redis.GetInt(ctx, uc.counterRedisKey)
+	switch {
+	case errors.Is(err, redis.ErrKeyNotFound):
+		status.RedisMissing = true
+	case err != nil:
+		return nil, fmt.Errorf("failed to read counter: %w", err)
+	default:
+		status.RedisValue = value
+	}
+
+	checkpoint, err := uc.counterCheckpoint(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if checkpoint != nil {
+		status.HasCheckpoint = true
+		status.CheckpointValue = checkpoint.ObservedValue
+		status.HighWaterMark = checkpoint.HighWaterMark
+		status.CheckpointedAt = checkpoint.UpdatedAt
+		status.Drift = status.RedisValue - checkpoint.ObservedValue
+	}
+
+	status.Healthy = !status.RedisMissing &&
+		(!status.HasCheckpoint || (status.Drift >= 0 && status.RedisValue <= status.HighWaterMark))
+
+	return status, nil
+}
+
+// ReconcileCounter restores the Redis counter from the persisted high-water
+// mark when it went backwards, then checkpoints the current value.
+func (uc *UseCase) ReconcileCounter(ctx context.Context) (*CounterStatus, error) {
+	return uc.reconcileCounter(ctx, false)
+}
+
+// RestoreCounter runs at startup. A Redis restored from an older snapshot can
+// hold a value past the last checkpoint but short of IDs already handed out,
+// which looks like normal drift, so the counter is always moved past the
+// high-water mark plus one more headroom, covering the IDs of a missed
+// checkpoint. Every startup therefore abandons up to twice the headroom.
+func (uc *UseCase) RestoreCounter(ctx context.Context) (*CounterStatus, error) {
+	return uc.reconcileCounter(ctx, true)
+}
+
+func (uc *UseCase) reconcileCounter(ctx context.Context, startup bool) (*CounterStatus, error) {
+	tracer := otel.Tracer("usecases.ReconcileCounter")
+	ctx, span := tracer.Start(ctx, "ReconcileCounterUsecase")
+
+	var err error
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}()
+	defer span.End()
+
+	status, err := uc.CounterStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	target := status.HighWaterMark
+
+	switch {
+	case !status.HasCheckpoint:
+	case startup:
+		target += uc.counterHeadroom
+
+		logger.FromContext(ctx, uc.logger).Info("Moving counter past the high-water mark at startup",
+			zap.String("key", uc.counterRedisKey),
+			zap.Int64("redis_value", status.RedisValue),
+			zap.Int64("high_water_mark", status.HighWaterMark),
+			zap.Int64("target", target),
+		)
+	case status.RedisMissing || status.Drift < 0:
+		logger.FromContext(ctx, uc.logger).Warn("Counter went backwards, restoring from high-water mark",
+			zap.String("key", uc.counterRedisKey),
+			zap.Int64("redis_value", status.RedisValue),
+			zap.Int64("checkpoint_value", status.CheckpointValue),
+			zap.Int64("high_water_mark", status.HighWaterMark),
+		)
+	default:
+		target = 0
+	}
+
+	if target > 0 {
+		_, err = uc.redis.SetIfGreater(ctx, uc.counterRedisKey, target)
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore counter: %w", err)
+		}
+	}
+
+	err = uc.CheckpointCounter(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.CounterStatus(ctx)
+}
+
+// CheckpointCounter persists the current counter value together with a
+// high-water mark one headroom ahead of it. The headroom must exceed the
+// number of IDs issued between two checkpoints.
+func (uc *UseCase) CheckpointCounter(ctx context.Context) error {
+	value, err := uc.redis.GetInt(ctx, uc.counterRedisKey)
+	if err != nil {
+		return fmt.Errorf("failed to read counter: %w", err)
+	}
+
+	_, err = uc.repository.AdvanceCounterCheckpoint(ctx, &entities.CounterCheckpoint{
+		Name:          uc.counterKey,
+		ObservedValue: value,
+		HighWaterMark: value + uc.counterHeadroom,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to checkpoint counter: %w", err)
+	}
+
+	return nil
+}
+
+// counterCheckpoint reads the checkpoint of the counter, or nil when there is
+// none. Releases before the Redis key and the checkpoint name were split named
+// the checkpoint after the hash-tagged key, so that row is read as a fallback.
+func (uc *UseCase) counterCheckpoint(ctx context.Context) (*entities.CounterCheckpoint, error) {
+	names := []string{uc.counterKey}
+	if uc.counterRedisKey != uc.counterKey {
+		names = append(names, uc.counterRedisKey)
+	}
+
+	for _, name := range names {
+		checkpoint, err := uc.repository.GetCounterCheckpoint(ctx, name)
+		if errors.Is(err, gocql.ErrNotFound) {
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read counter checkpoint: %w", err)
+		}
+
+		return checkpoint, nil
+	}
+
+	return nil, nil //nolint:nilnil // no checkpoint yet is a valid state
+}
+
+// RunCounterCheckpoints reconciles and checkpoints the counter every interval
+// until ctx is cancelled.
+func (uc *UseCase) RunCounterCheckpoints(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := uc.ReconcileCounter(ctx); err != nil {
+				logger.FromContext(ctx, uc.logger).Error("Failed to checkpoint counter", zap.Error(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package usecases_test
+
+import (
+	"context"
+	"testing"
+
+	"lnk/domain/entities"
+	"lnk/domain/entities/usecases"
+	"lnk/extensions/gocqltesting"
+	"lnk/extensions/redis/mocks"
+	"lnk/gateways/gocql/repositories"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func Test_UseCase_ReconcileCounter_RestoresFromHighWaterMark(t *testing.T) {
+	t.Parallel()
+
+	session, err := gocqltesting.NewDB(t, t.Name())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	logger := zap.NewNop()
+
+	repository := repositories.NewRepository(logger, session)
+
+	mockRedis := mocks.NewMockRedis(t)
+
+	useCase := usecases.NewUseCase(usecases.NewUseCaseParams{
+		Logger:          logger,
+		Repository:      repository,
+		Redis:           mockRedis,
+		Salt:            "test",
+		CounterKey:      "counter",
+		CounterHeadroom: 100,
+	})
+
+	mockRedis.On("GetInt", mock.Anything, "counter").Return(int64(1000), nil).Once()
+	require.NoError(t, useCase.CheckpointCounter(ctx))
+
+	// Redis lost its data and restarted from a lower value.
+	mockRedis.On("GetInt", mock.Anything, "counter").Return(int64(10), nil).Once()
+	mockRedis.On("SetIfGreater", mock.Anything, "counter", int64(1100)).Return(true, nil).Once()
+	mockRedis.On("GetInt", mock.Anything, "counter").Return(int64(1100), nil)
+
+	status, err := useCase.ReconcileCounter(ctx)
+	require.NoError(t, err)
+	require.True(t, status.Healthy)
+	require.Equal(t, int64(1100), status.RedisValue)
+	require.Equal(t, int64(1200), status.HighWaterMark)
+}
+
+func Test_UseCase_RestoreCounter_SkipsPastHighWaterMark(t *testing.T) {
+	t.Parallel()
+
+	session, err := gocqltesting.NewDB(t, t.Name())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	logger := zap.NewNop()
+
+	mockRedis := mocks.NewMockRedis(t)
+
+	useCase := usecases.NewUseCase(usecases.NewUseCaseParams{
+		Logger:          logger,
+		Repository:      repositories.NewRepository(logger, session),
+		Redis:           mockRedis,
+		Salt:            "test",
+		CounterKey:      "counter",
+		CounterHeadroom: 100,
+	})
+
+	mockRedis.On("GetInt", mock.Anything, "counter").Return(int64(1000), nil).Once()
+	require.NoError(t, useCase.CheckpointCounter(ctx))
+
+	// Redis came back from a snapshot taken after the checkpoint but before
+	// the last IDs were handed out, so the counter did not go backwards.
+	mockRedis.On("GetInt", mock.Anything, "counter").Return(int64(1050), nil).Once()
+	mockRedis.On("SetIfGreater", mock.Anything, "counter", int64(1200)).Return(true, nil).Once()
+	mockRedis.On("GetInt", mock.Anything, "counter").Return(int64(1200), nil)
+
+	status, err := useCase.RestoreCounter(ctx)
+	require.NoError(t, err)
+	require.True(t, status.Healthy)
+	require.Equal(t, int64(1300), status.HighWaterMark)
+}
+
+func Test_UseCase_CounterCheckpoint_NamedAfterLogicalKey(t *testing.T) {
+	t.Parallel()
+
+	session, err := gocqltesting.NewDB(t, t.Name())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	logger := zap.NewNop()
+
+	repository := repositories.NewRepository(logger, session)
+
+	// A release that named the checkpoint after the hash-tagged key left
+	// this row behind.
+	_, err = repository.AdvanceCounterCheckpoint(ctx, &entities.CounterCheckpoint{
+		Name:          "{lnk}:counter",
+		ObservedValue: 1000,
+		HighWaterMark: 1100,
+	})
+	require.NoError(t, err)
+
+	mockRedis := mocks.NewMockRedis(t)
+
+	useCase := usecases.NewUseCase(usecases.NewUseCaseParams{
+		Logger:          logger,
+		Repository:      repository,
+		Redis:           mockRedis,
+		Salt:            "test",
+		CounterKey:      "counter",
+		CounterRedisKey: "{lnk}:counter",
+		CounterHeadroom: 100,
+	})
+
+	// Enabling the hash tag started a fresh counter in Redis.
+	mockRedis.On("GetInt", mock.Anything, "{lnk}:counter").Return(int64(1), nil).Once()
+	mockRedis.On("SetIfGreater", mock.Anything, "{lnk}:counter", int64(1100)).Return(true, nil).Once()
+	mockRedis.On("GetInt", mock.Anything, "{lnk}:counter").Return(int64(1100), nil)
+
+	status, err := useCase.ReconcileCounter(ctx)
+	require.NoError(t, err)
+	require.True(t, status.Healthy)
+	require.Equal(t, "counter", status.Key)
+	require.Equal(t, "{lnk}:counter", status.RedisKey)
+
+	checkpoint, err := repository.GetCounterCheckpoint(ctx, "counter")
+	require.NoError(t, err)
+	require.Equal(t, int64(1200), checkpoint.HighWaterMark)
+}
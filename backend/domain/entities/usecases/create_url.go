@@ -19,7 +19,18 @@ var (
 	counterOnce         sync.Once
 )
 
-func (uc *UseCase) CreateShortURL(ctx context.Context, longURL string) (string, error) {
+// CreateShortURL creates a link to longURL on domain, or on the default domain
+// when domain is empty. When workspaces are enforced, the link counts against
+// the monthly quota of the caller's workspace; a deduplicated link that
+// already exists does not.
+func (uc *UseCase) CreateShortURL(ctx context.Context, domain, longURL string) (*entities.URL, error) {
+	return uc.CreateLink(ctx, domain, longURL, LinkMetadata{})
+}
+
+// CreateLink is CreateShortURL with the title, notes and tags of the new link,
+// which require an owner. A deduplicated link that already exists is returned
+// with its own metadata.
+func (uc *UseCase) CreateLink(ctx context.Context, domain, longURL string, metadata LinkMetadata) (*entities.URL, error) {
 	tracer := otel.Tracer("usecases.CreateShortURL")
 	ctx, span := tracer.Start(ctx, "CreateShortURLUsecase")
 	var err error
@@ -32,26 +43,98 @@ func (uc *UseCase) CreateShortURL(ctx context.Context, longURL string) (string,
 	}()
 	defer span.End()
 
-	id, err := uc.redis.Incr(ctx, uc.counterKey)
+	domain, err = uc.domains.Validate(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	ownerID := entities.IdentityFromContext(ctx).OwnerID
+
+	metadata, err = normalizeMetadata(metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	if ownerID == "" && !metadata.empty() {
+		return nil, ErrMetadataOwnerRequired
+	}
+
+	admission, err := uc.admitLinks(ctx, domain, 1)
 	if err != nil {
-		return "", fmt.Errorf("failed to increment counter: %w", err)
+		return nil, err
+	}
+
+	var created bool
+
+	defer func() {
+		if created {
+			admission.commit(ctx, 1)
+		} else {
+			admission.release(ctx, 1)
+		}
+	}()
+
+	var url *entities.URL
+
+	if uc.dedup.applies(ownerID) {
+		// Unparsable URLs cannot be compared, so they are always created anew.
+		if normalizedURL, normalizeErr := helpers.NormalizeURL(longURL); normalizeErr == nil {
+			url, created, err = uc.createDeduplicated(ctx, &entities.URL{
+				Domain:  domain,
+				LongURL: longURL,
+				OwnerID: ownerID,
+				Title:   metadata.Title,
+				Notes:   metadata.Notes,
+				Tags:    metadata.Tags,
+			}, normalizedURL)
+
+			return url, err
+		}
 	}
 
-	shortCode := helpers.Base62Encode(id, uc.salt)
+	shortCode, err := uc.nextShortCode(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	url := &entities.URL{
+	url = &entities.URL{
+		Domain:    domain,
 		ShortCode: shortCode,
 		LongURL:   longURL,
+		OwnerID:   ownerID,
+		Title:     metadata.Title,
+		Notes:     metadata.Notes,
+		Tags:      metadata.Tags,
+	}
+	if err = uc.storeURL(ctx, url); err != nil {
+		return nil, err
 	}
 
-	err = uc.repository.CreateURL(ctx, url)
+	created = true
+
+	return url, nil
+}
+
+func (uc *UseCase) nextShortCode(ctx context.Context) (string, error) {
+	id, err := uc.ids.Next(ctx)
 	if err != nil {
-		return "", fmt.Errorf("failed to create URL in repository: %w", err)
+		return "", ErrCounterUnavailable.Wrap(fmt.Errorf("failed to allocate ID: %w", err))
+	}
+
+	return helpers.Base62Encode(id, uc.salt), nil
+}
+
+func (uc *UseCase) storeURL(ctx context.Context, url *entities.URL) error {
+	uc.flagThreat(ctx, url)
+
+	if err := uc.repository.CreateURL(ctx, url); err != nil {
+		return ErrStorageUnavailable.Wrap(fmt.Errorf("failed to create URL in repository: %w", err))
 	}
 
 	uc.incrementURLShortenedMetric(ctx)
+	uc.enqueuePreview(url)
 
-	return shortCode, nil
+	return nil
 }
 
 func (uc *UseCase) incrementURLShortenedMetric(ctx context.Context) {
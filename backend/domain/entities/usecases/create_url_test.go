@@ -2,11 +2,15 @@ package usecases_test
 
 import (
 	"context"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	"lnk/domain/entities"
+	"lnk/domain/entities/helpers"
 	"lnk/domain/entities/usecases"
 	"lnk/extensions/gocqltesting"
 	"lnk/extensions/redis/mocks"
@@ -38,7 +42,115 @@ func Test_UseCase_CreateURL(t *testing.T) {
 	useCase := usecases.NewUseCase(params)
 
 	longURL := "https://www.google.com"
-	shortCode, err := useCase.CreateShortURL(ctx, longURL)
+	url, err := useCase.CreateShortURL(ctx, "", longURL)
 	require.NoError(t, err)
-	require.NotEmpty(t, shortCode)
+	require.NotEmpty(t, url.ShortCode)
+	require.Equal(t, "localhost:8080", url.Domain)
+}
+
+func Test_UseCase_CreateURL_DeduplicatesPerOwner(t *testing.T) {
+	t.Parallel()
+
+	session, err := gocqltesting.NewDB(t, t.Name())
+	require.NoError(t, err)
+
+	logger := zap.NewNop()
+
+	mockRedis := mocks.NewMockRedis(t)
+	mockRedis.On("Incr", mock.Anything, mock.Anything).Return(int64(1), nil).Once()
+	mockRedis.On("Incr", mock.Anything, mock.Anything).Return(int64(2), nil).Once()
+
+	useCase := usecases.NewUseCase(usecases.NewUseCaseParams{
+		Logger:     logger,
+		Repository: repositories.NewRepository(logger, session),
+		Redis:      mockRedis,
+		Dedup:      usecases.DedupPolicy{Enabled: true},
+		Salt:       "test",
+		CounterKey: "test",
+	})
+
+	acme := entities.WithIdentity(context.Background(), entities.Identity{OwnerID: "acme"})
+	globex := entities.WithIdentity(context.Background(), entities.Identity{OwnerID: "globex"})
+
+	first, err := useCase.CreateShortURL(acme, "", "https://Example.com")
+	require.NoError(t, err)
+
+	again, err := useCase.CreateShortURL(acme, "", "https://example.com/#section")
+	require.NoError(t, err)
+	require.Equal(t, first.ShortCode, again.ShortCode)
+
+	other, err := useCase.CreateShortURL(globex, "", "https://example.com")
+	require.NoError(t, err)
+	require.NotEqual(t, first.ShortCode, other.ShortCode)
+}
+
+func Test_UseCase_CreateURL_DeduplicatesConcurrently(t *testing.T) {
+	t.Parallel()
+
+	session, err := gocqltesting.NewDB(t, t.Name())
+	require.NoError(t, err)
+
+	logger := zap.NewNop()
+	repository := repositories.NewRepository(logger, session)
+
+	useCase := usecases.NewUseCase(usecases.NewUseCaseParams{
+		Logger:      logger,
+		Repository:  repository,
+		IDAllocator: &sequenceAllocator{},
+		Dedup:       usecases.DedupPolicy{Enabled: true, ClaimGrace: 500 * time.Millisecond},
+		Salt:        "test",
+	})
+
+	acme := entities.WithIdentity(context.Background(), entities.Identity{OwnerID: "acme"})
+
+	t.Run("concurrent requests share one code", func(t *testing.T) {
+		const requests = 8
+
+		var wg sync.WaitGroup
+
+		codes := make([]string, requests)
+		errs := make([]error, requests)
+
+		for i := range requests {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				link, err := useCase.CreateShortURL(acme, "", "https://concurrent.example")
+				if err == nil {
+					codes[i] = link.ShortCode
+				}
+
+				errs[i] = err
+			}()
+		}
+
+		wg.Wait()
+
+		for i := range requests {
+			require.NoError(t, errs[i])
+			require.Equal(t, codes[0], codes[i])
+		}
+	})
+
+	t.Run("a fresh claim is waited on before it is replaced", func(t *testing.T) {
+		normalized, err := helpers.NormalizeURL("https://pending.example")
+		require.NoError(t, err)
+
+		claimed, err := repository.ClaimOwnerURL(acme, "acme", "localhost:8080", helpers.HashURL(normalized), "pending")
+		require.NoError(t, err)
+		require.True(t, claimed)
+
+		started := time.Now()
+
+		link, err := useCase.CreateShortURL(acme, "", "https://pending.example")
+		require.NoError(t, err)
+		require.NotEqual(t, "pending", link.ShortCode)
+		require.GreaterOrEqual(t, time.Since(started), 400*time.Millisecond)
+
+		again, err := useCase.CreateShortURL(acme, "", "https://pending.example")
+		require.NoError(t, err)
+		require.Equal(t, link.ShortCode, again.ShortCode)
+	})
 }
@@ -0,0 +1,163 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"lnk/domain/entities"
+	"lnk/domain/entities/helpers"
+	"lnk/extensions/logger"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+	"go.uber.org/zap"
+)
+
+const (
+	// dedupAttempts bounds how often a lost or stale claim is retried before
+	// the request fails.
+	dedupAttempts = 3
+	// defaultDedupClaimGrace is how long a claim without its link is assumed
+	// to belong to a request that is still writing it.
+	defaultDedupClaimGrace = 10 * time.Second
+	dedupPollInterval      = 50 * time.Millisecond
+)
+
+// errClaimInProgress reports a claim whose link has not been written yet.
+var errClaimInProgress = errors.New("owner URL claim in progress")
+
+// DedupPolicy selects the owners whose identical destinations share one short
+// code. With Enabled and no Owners, it applies to every identified owner.
+// Anonymous links are never deduplicated. A claim whose link is missing is
+// waited on for ClaimGrace, 10 seconds by default, before it is replaced.
+type DedupPolicy struct {
+	Owners     []string
+	ClaimGrace time.Duration
+	Enabled    bool
+}
+
+func (p DedupPolicy) applies(ownerID string) bool {
+	if !p.Enabled || ownerID == "" {
+		return false
+	}
+
+	return len(p.Owners) == 0 || slices.Contains(p.Owners, ownerID)
+}
+
+func (p DedupPolicy) claimGrace() time.Duration {
+	if p.ClaimGrace <= 0 {
+		return defaultDedupClaimGrace
+	}
+
+	return p.ClaimGrace
+}
+
+// createDeduplicated returns the owner's existing link on the domain for the
+// normalized destination, or creates link and reports that it did. The
+// owner's lookup entry is claimed with a lightweight transaction before the
+// link is written, so concurrent requests for the same destination agree on a
+// single code; a request finding a fresh claim without its link waits for the
+// link. A lookup that no longer matches its link, because the link was changed
+// or removed, is treated as stale and replaced.
+func (uc *UseCase) createDeduplicated(ctx context.Context, link *entities.URL, normalizedURL string) (*entities.URL, bool, error) {
+	urlHash := helpers.HashURL(normalizedURL)
+
+	for attempt := 0; attempt < dedupAttempts; {
+		existing, err := uc.existingURL(ctx, link, urlHash, normalizedURL)
+		if errors.Is(err, errClaimInProgress) {
+			// The claim ages past the grace period, so the wait is bounded.
+			select {
+			case <-ctx.Done():
+				return nil, false, ErrStorageUnavailable.Wrap(ctx.Err())
+			case <-time.After(dedupPollInterval):
+			}
+
+			continue
+		}
+
+		if err != nil {
+			return nil, false, err
+		}
+
+		if existing != nil {
+			logger.FromContext(ctx, uc.logger).Debug("Returning existing short code",
+				zap.String("owner_id", link.OwnerID),
+				zap.String("domain", link.Domain),
+				zap.String("short_code", existing.ShortCode),
+			)
+
+			return existing, false, nil
+		}
+
+		shortCode, err := uc.nextShortCode(ctx)
+		if err != nil {
+			return nil, false, err
+		}
+
+		claimed, err := uc.repository.ClaimOwnerURL(ctx, link.OwnerID, link.Domain, urlHash, shortCode)
+		if err != nil {
+			return nil, false, ErrStorageUnavailable.Wrap(err)
+		}
+
+		if !claimed {
+			// A concurrent request won; its code is returned on the next attempt.
+			attempt++
+			continue
+		}
+
+		created := *link
+		created.ShortCode = shortCode
+
+		if err := uc.storeURL(ctx, &created); err != nil {
+			releaseErr := uc.repository.ReleaseOwnerURL(context.WithoutCancel(ctx), link.OwnerID, link.Domain, urlHash, shortCode)
+			if releaseErr != nil {
+				logger.FromContext(ctx, uc.logger).Warn("Failed to release owner URL", zap.Error(releaseErr))
+			}
+
+			return nil, false, err
+		}
+
+		return &created, true, nil
+	}
+
+	return nil, false, ErrStorageUnavailable.Wrap(fmt.Errorf("failed to claim owner URL after %d attempts", dedupAttempts))
+}
+
+// existingURL returns the owner's link on the domain for urlHash, or nil when
+// there is none. Stale lookups are released; a claim younger than the grace
+// period whose link is missing yields errClaimInProgress.
+func (uc *UseCase) existingURL(ctx context.Context, link *entities.URL, urlHash, normalizedURL string) (*entities.URL, error) {
+	shortCode, claimedAt, err := uc.repository.GetShortCodeByOwnerURL(ctx, link.OwnerID, link.Domain, urlHash)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, ErrStorageUnavailable.Wrap(err)
+	}
+
+	existing, err := uc.repository.GetURLByShortCode(ctx, link.Domain, shortCode)
+
+	switch {
+	case errors.Is(err, gocql.ErrNotFound):
+		// A request that claimed the lookup writes its link right after; an
+		// older claim was abandoned by a request that failed in between.
+		if time.Since(claimedAt) < uc.dedup.claimGrace() {
+			return nil, errClaimInProgress
+		}
+	case err != nil:
+		return nil, ErrStorageUnavailable.Wrap(err)
+	default:
+		if normalized, _ := helpers.NormalizeURL(existing.LongURL); existing.OwnerID == link.OwnerID && normalized == normalizedURL {
+			return existing, nil
+		}
+	}
+
+	if err := uc.repository.ReleaseOwnerURL(ctx, link.OwnerID, link.Domain, urlHash, shortCode); err != nil {
+		return nil, ErrStorageUnavailable.Wrap(err)
+	}
+
+	return nil, nil
+}
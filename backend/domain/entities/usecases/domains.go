@@ -0,0 +1,92 @@
+package usecases
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"lnk/domain/entities"
+)
+
+const defaultBaseURL = "http://localhost:8080"
+
+var ErrUnknownDomain = entities.ErrValidation.WithMessage("domain is not a configured short domain")
+
+// Domains is the set of short domains links can be served from. Links without
+// an explicit domain, and requests for hosts that are not configured, use the
+// default domain.
+type Domains struct {
+	allowed       map[string]struct{}
+	scheme        string
+	defaultDomain string
+}
+
+// NewDomains takes the default domain and the URL scheme from baseURL, such
+// as https://lnk.example, and accepts extra as additional domains.
+func NewDomains(baseURL string, extra []string) (*Domains, error) {
+	parsed, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse base URL: %w", err)
+	}
+
+	if parsed.Scheme == "" || parsed.Host == "" {
+		return nil, fmt.Errorf("base URL %q must include a scheme and host", baseURL)
+	}
+
+	domains := &Domains{
+		scheme:        parsed.Scheme,
+		defaultDomain: strings.ToLower(parsed.Host),
+		allowed:       map[string]struct{}{strings.ToLower(parsed.Host): {}},
+	}
+
+	for _, domain := range extra {
+		if domain = strings.ToLower(strings.TrimSpace(domain)); domain != "" {
+			domains.allowed[domain] = struct{}{}
+		}
+	}
+
+	return domains, nil
+}
+
+func (d *Domains) Default() string {
+	return d.defaultDomain
+}
+
+// Resolve maps a request Host header to the domain it serves. Hosts are
+// matched with and without their port; unknown hosts fall back to the default
+// domain, so internal callers reaching the service directly keep working.
+func (d *Domains) Resolve(host string) string {
+	host = strings.ToLower(host)
+	if _, ok := d.allowed[host]; ok {
+		return host
+	}
+
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		if _, ok := d.allowed[hostname]; ok {
+			return hostname
+		}
+	}
+
+	return d.defaultDomain
+}
+
+// Validate returns the configured domain for a client-chosen one, or the
+// default domain when none is chosen.
+func (d *Domains) Validate(domain string) (string, error) {
+	if domain == "" {
+		return d.defaultDomain, nil
+	}
+
+	domain = strings.ToLower(domain)
+	if _, ok := d.allowed[domain]; !ok {
+		return "", ErrUnknownDomain
+	}
+
+	return domain, nil
+}
+
+// ShortURL returns the fully qualified URL of a short code on domain.
+func (d *Domains) ShortURL(domain, shortCode string) string {
+	return d.scheme + "://" + domain + "/" + shortCode
+}
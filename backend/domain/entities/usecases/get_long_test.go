@@ -3,6 +3,7 @@ package usecases_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"lnk/domain/entities/usecases"
 	"lnk/extensions/gocqltesting"
@@ -40,11 +41,11 @@ func Test_UseCase_GetLongURL(t *testing.T) {
 
 	useCase := usecases.NewUseCase(params)
 
-	shortCode, err := useCase.CreateShortURL(ctx, url)
+	created, err := useCase.CreateShortURL(ctx, "", url)
 	require.NoError(t, err)
-	require.NotEmpty(t, shortCode)
+	require.NotEmpty(t, created.ShortCode)
 
-	longURL, err := useCase.GetLongURL(ctx, shortCode)
+	longURL, err := useCase.GetLongURL(ctx, created.Domain, created.ShortCode)
 	require.NoError(t, err)
 	require.NotEmpty(t, longURL)
 	require.Equal(t, url, longURL)
@@ -69,8 +70,121 @@ func Test_UseCase_GetLongURL_NotFound(t *testing.T) {
 	useCase := usecases.NewUseCase(params)
 
 	shortCode := "1234567890"
-	longURL, err := useCase.GetLongURL(ctx, shortCode)
+	longURL, err := useCase.GetLongURL(ctx, "localhost:8080", shortCode)
 	require.Error(t, err)
 	require.ErrorIs(t, err, usecases.ErrURLNotFound)
 	require.Empty(t, longURL)
 }
+
+func Test_UseCase_GetLongURL_PerDomain(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	session, err := gocqltesting.NewDB(t, t.Name())
+	require.NoError(t, err)
+
+	logger := zap.NewNop()
+
+	// Every link gets the same counter value, so both domains hold the same code.
+	mockRedis := mocks.NewMockRedis(t)
+	mockRedis.On("Incr", mock.Anything, mock.Anything).Return(int64(1), nil)
+
+	domains, err := usecases.NewDomains("https://lnk.example", []string{"go.example.com"})
+	require.NoError(t, err)
+
+	useCase := usecases.NewUseCase(usecases.NewUseCaseParams{
+		Logger:     logger,
+		Repository: repositories.NewRepository(logger, session),
+		Redis:      mockRedis,
+		Domains:    domains,
+		Salt:       "test",
+		CounterKey: "test",
+	})
+
+	onDefault, err := useCase.CreateShortURL(ctx, "", "https://a.example")
+	require.NoError(t, err)
+
+	onBrand, err := useCase.CreateShortURL(ctx, "GO.example.com", "https://b.example")
+	require.NoError(t, err)
+	require.Equal(t, onDefault.ShortCode, onBrand.ShortCode)
+	require.Equal(t, "https://go.example.com/"+onBrand.ShortCode, useCase.ShortURL(onBrand.Domain, onBrand.ShortCode))
+
+	longURL, err := useCase.GetLongURL(ctx, useCase.ResolveDomain("go.example.com:443"), onBrand.ShortCode)
+	require.NoError(t, err)
+	require.Equal(t, "https://b.example", longURL)
+
+	longURL, err = useCase.GetLongURL(ctx, useCase.ResolveDomain("backend:8080"), onDefault.ShortCode)
+	require.NoError(t, err)
+	require.Equal(t, "https://a.example", longURL)
+
+	_, err = useCase.CreateShortURL(ctx, "evil.example", "https://c.example")
+	require.ErrorIs(t, err, usecases.ErrUnknownDomain)
+}
+
+func Test_UseCase_GetLongURL_LegacyFallback(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	session, err := gocqltesting.NewDB(t, t.Name())
+	require.NoError(t, err)
+
+	logger := zap.NewNop()
+
+	// A link created before custom domains that the migrator has not copied.
+	err = session.Query("INSERT INTO urls (short_code, long_url, owner_id, created_at) VALUES (?, ?, ?, ?)",
+		"legacy1", "https://old.example", "acme", time.Now().UTC()).ExecContext(ctx)
+	require.NoError(t, err)
+
+	domains, err := usecases.NewDomains("https://lnk.example", []string{"go.example.com"})
+	require.NoError(t, err)
+
+	useCase := usecases.NewUseCase(usecases.NewUseCaseParams{
+		Logger:     logger,
+		Repository: repositories.NewRepository(logger, session),
+		Domains:    domains,
+	})
+
+	longURL, err := useCase.GetLongURL(ctx, "lnk.example", "legacy1")
+	require.NoError(t, err)
+	require.Equal(t, "https://old.example", longURL)
+
+	_, err = useCase.GetLongURL(ctx, "go.example.com", "legacy1")
+	require.ErrorIs(t, err, usecases.ErrURLNotFound)
+}
+
+func Test_UseCase_InspectLink(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	session, err := gocqltesting.NewDB(t, t.Name())
+	require.NoError(t, err)
+
+	logger := zap.NewNop()
+
+	useCase := usecases.NewUseCase(usecases.NewUseCaseParams{
+		Logger:      logger,
+		Repository:  repositories.NewRepository(logger, session),
+		IDAllocator: &sequenceAllocator{},
+		Salt:        "test",
+	})
+
+	secure, err := useCase.CreateShortURL(ctx, "", "https://a.example")
+	require.NoError(t, err)
+
+	insecure, err := useCase.CreateShortURL(ctx, "", "http://b.example")
+	require.NoError(t, err)
+
+	destination, err := useCase.InspectLink(ctx, secure.Domain, secure.ShortCode)
+	require.NoError(t, err)
+	require.Equal(t, "https://a.example", destination.LongURL)
+	require.Equal(t, usecases.SafetyNotFlagged, destination.Safety)
+	require.False(t, destination.CreatedAt.IsZero())
+	require.Nil(t, destination.Preview)
+
+	destination, err = useCase.InspectLink(ctx, insecure.Domain, insecure.ShortCode)
+	require.NoError(t, err)
+	require.Equal(t, usecases.SafetyInsecure, destination.Safety)
+
+	_, err = useCase.InspectLink(ctx, secure.Domain, "missing")
+	require.ErrorIs(t, err, usecases.ErrURLNotFound)
+}
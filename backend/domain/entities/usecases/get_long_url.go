@@ -2,13 +2,19 @@ package usecases
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
+	"lnk/domain/entities"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/codes"
 )
 
-func (uc *UseCase) GetLongURL(ctx context.Context, shortCode string) (string, error) {
+// GetLongURL returns the destination of shortCode on domain. Links that are not
+// active fail with ErrLinkWarned, ErrLinkBlocked or ErrLinkDisabled.
+func (uc *UseCase) GetLongURL(ctx context.Context, domain, shortCode string) (string, error) {
 	tracer := otel.Tracer("usecases.GetLongURL")
 	ctx, span := tracer.Start(ctx, "GetLongURLUsecase")
 
@@ -21,14 +27,37 @@ func (uc *UseCase) GetLongURL(ctx context.Context, shortCode string) (string, er
 	}()
 	defer span.End()
 
-	url, err := uc.repository.GetURLByShortCode(ctx, shortCode)
-	if url == nil {
-		return "", ErrURLNotFound
+	url, err := uc.link(ctx, domain, shortCode)
+	if err != nil {
+		return "", err
 	}
 
-	if err != nil {
-		return "", fmt.Errorf("failed to get URL by short code: %w", err)
+	if err = servable(url, false); err != nil {
+		return "", err
 	}
 
 	return url.LongURL, nil
 }
+
+// link returns the link shortCode on domain whoever owns it. Links on the
+// default domain fall back to the table that predates custom domains, so they
+// keep resolving until the migrator has copied them.
+func (uc *UseCase) link(ctx context.Context, domain, shortCode string) (*entities.URL, error) {
+	url, err := uc.repository.GetURLByShortCode(ctx, domain, shortCode)
+	if errors.Is(err, gocql.ErrNotFound) && domain == uc.domains.Default() {
+		url, err = uc.repository.GetLegacyURL(ctx, shortCode)
+		if err == nil {
+			url.Domain = domain
+		}
+	}
+
+	if err != nil {
+		if errors.Is(err, gocql.ErrNotFound) {
+			return nil, ErrURLNotFound
+		}
+
+		return nil, ErrStorageUnavailable.Wrap(fmt.Errorf("failed to get URL by short code: %w", err))
+	}
+
+	return url, nil
+}
@@ -0,0 +1,95 @@
+package usecases
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"lnk/domain/entities"
+	"lnk/extensions/logger"
+
+	"go.uber.org/zap"
+)
+
+// Safety is what is known about the risk of following a link.
+type Safety string
+
+const (
+	// SafetyNotFlagged is a destination served over HTTPS that nothing has
+	// flagged.
+	SafetyNotFlagged Safety = "not_flagged"
+	// SafetyInsecure is a destination served over plain HTTP, which anyone on
+	// the network path can read or alter.
+	SafetyInsecure Safety = "insecure"
+	// SafetyWarned is a destination that was flagged; visitors are warned
+	// before they continue.
+	SafetyWarned Safety = "warned"
+	// SafetyBlocked is a destination visitors may not continue to.
+	SafetyBlocked Safety = "blocked"
+)
+
+// Destination is what a visitor may learn about a link before following it.
+// The owner's title, notes and tags stay private.
+type Destination struct {
+	CreatedAt time.Time
+	// Preview is nil until the destination has been fetched.
+	Preview *entities.LinkPreview
+	LongURL string
+	// Reason explains why a warned or blocked destination was flagged.
+	Reason string
+	Safety Safety
+}
+
+// InspectLink returns the destination of shortCode on domain without following
+// it. Anyone holding the short link may inspect it, unless it is disabled.
+func (uc *UseCase) InspectLink(ctx context.Context, domain, shortCode string) (*Destination, error) {
+	destination, err := uc.ResolveLink(ctx, domain, shortCode)
+	if err != nil {
+		return nil, err
+	}
+
+	// The preview only decorates the page, so failing to read it does not fail
+	// the inspection.
+	destination.Preview, err = uc.LinkPreview(ctx, domain, shortCode)
+	if err != nil {
+		logger.FromContext(ctx, uc.logger).Warn("Failed to get link preview", zap.Error(err))
+	}
+
+	return destination, nil
+}
+
+// ResolveLink is InspectLink without the preview: it reads the link once, so
+// a redirect can tell a flagged link apart and serve its interstitial from
+// the same read.
+func (uc *UseCase) ResolveLink(ctx context.Context, domain, shortCode string) (*Destination, error) {
+	link, err := uc.link(ctx, domain, shortCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if link.Status == entities.LinkDisabled {
+		return nil, ErrLinkDisabled
+	}
+
+	return &Destination{
+		CreatedAt: link.CreatedAt,
+		LongURL:   link.LongURL,
+		Reason:    link.StatusReason,
+		Safety:    destinationSafety(link),
+	}, nil
+}
+
+func destinationSafety(link *entities.URL) Safety {
+	switch link.Status {
+	case entities.LinkWarned:
+		return SafetyWarned
+	case entities.LinkBlocked:
+		return SafetyBlocked
+	}
+
+	if strings.HasPrefix(strings.ToLower(link.LongURL), "http://") {
+		return SafetyInsecure
+	}
+
+	return SafetyNotFlagged
+}
@@ -0,0 +1,259 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"lnk/domain/entities"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+)
+
+const (
+	MaxTitleLength = 200
+	MaxNotesLength = 2000
+	MaxTagLength   = 50
+	MaxTags        = 20
+
+	// editAttempts bounds how often an edit that lost to a concurrent edit of
+	// the same link is retried before the request fails.
+	editAttempts = 3
+)
+
+var (
+	ErrInvalidTitle          = entities.ErrValidation.WithMessage(fmt.Sprintf("title must be at most %d characters", MaxTitleLength))
+	ErrInvalidNotes          = entities.ErrValidation.WithMessage(fmt.Sprintf("notes must be at most %d characters", MaxNotesLength))
+	ErrInvalidTags           = entities.ErrValidation.WithMessage(fmt.Sprintf("at most %d tags of 1 to %d characters are allowed", MaxTags, MaxTagLength))
+	ErrMetadataOwnerRequired = entities.ErrForbidden.WithMessage("titles, notes and tags require an owner")
+	ErrLinkEditConflict      = entities.ErrConflict.WithMessage("link was edited concurrently; retry the request")
+)
+
+// LinkMetadata is the owner-defined metadata of a new link.
+type LinkMetadata struct {
+	Title string
+	Notes string
+	// Tags are compared ignoring case; duplicates are dropped.
+	Tags []string
+}
+
+// LinkUpdate edits the metadata of a link. Nil fields are left unchanged; an
+// empty, non-nil Tags removes every tag.
+type LinkUpdate struct {
+	Title *string
+	Notes *string
+	Tags  []string
+}
+
+// GetLink returns the caller's link shortCode on domain, or on the default
+// domain when domain is empty. Links of other owners are reported as not
+// found.
+func (uc *UseCase) GetLink(ctx context.Context, domain, shortCode string) (*entities.URL, error) {
+	domain, err := uc.authorizeLinkOwner(ctx, domain, entities.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.ownedLink(ctx, domain, shortCode)
+}
+
+// UpdateLink applies update to the caller's link shortCode on domain and
+// returns the edited link. The link and its listings are written with the
+// time of the edit as their version: an edit only applies to the version it
+// was read from, and is retried on the newer version when a concurrent edit
+// won, so tags removed by one edit are never listed again by another.
+func (uc *UseCase) UpdateLink(ctx context.Context, domain, shortCode string, update LinkUpdate) (*entities.URL, error) {
+	domain, err := uc.authorizeLinkOwner(ctx, domain, entities.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := normalizeUpdate(&update); err != nil {
+		return nil, err
+	}
+
+	for range editAttempts {
+		previous, err := uc.ownedLink(ctx, domain, shortCode)
+		if err != nil {
+			return nil, err
+		}
+
+		updated := *previous
+		if update.Title != nil {
+			updated.Title = *update.Title
+		}
+
+		if update.Notes != nil {
+			updated.Notes = *update.Notes
+		}
+
+		if update.Tags != nil {
+			updated.Tags = update.Tags
+		}
+
+		updated.UpdatedAt = nextVersion(previous)
+
+		applied, err := uc.repository.UpdateURLMetadata(ctx, previous, &updated)
+		if err != nil {
+			return nil, ErrStorageUnavailable.Wrap(err)
+		}
+
+		if applied {
+			return &updated, nil
+		}
+	}
+
+	return nil, ErrLinkEditConflict
+}
+
+// authorizeLinkOwner validates domain and checks that the caller has an owner
+// whose workspace role, when workspaces are enforced, allows required.
+func (uc *UseCase) authorizeLinkOwner(ctx context.Context, domain string, required entities.Role) (string, error) {
+	ownerID := entities.IdentityFromContext(ctx).OwnerID
+	if ownerID == "" {
+		return "", ErrOwnerRequired
+	}
+
+	if uc.workspaces.Enforced {
+		if _, _, err := uc.authorizeWorkspace(ctx, ownerID, required); err != nil {
+			return "", err
+		}
+	}
+
+	return uc.domains.Validate(domain)
+}
+
+func (uc *UseCase) ownedLink(ctx context.Context, domain, shortCode string) (*entities.URL, error) {
+	link, err := uc.repository.GetURLByShortCode(ctx, domain, shortCode)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return nil, ErrURLNotFound
+	}
+
+	if err != nil {
+		return nil, ErrStorageUnavailable.Wrap(err)
+	}
+
+	if link.OwnerID != entities.IdentityFromContext(ctx).OwnerID {
+		return nil, ErrURLNotFound
+	}
+
+	return link, nil
+}
+
+// nextVersion returns the UpdatedAt of the edit following link's current
+// version. Cassandra keeps timestamps to the millisecond, and versions must
+// increase even when the clock does not.
+func nextVersion(link *entities.URL) time.Time {
+	current := link.CreatedAt
+	if !link.UpdatedAt.IsZero() {
+		current = link.UpdatedAt
+	}
+
+	next := time.Now().UTC().Truncate(time.Millisecond)
+	if floor := current.Truncate(time.Millisecond).Add(time.Millisecond); next.Before(floor) {
+		next = floor
+	}
+
+	return next
+}
+
+func normalizeMetadata(metadata LinkMetadata) (LinkMetadata, error) {
+	update := LinkUpdate{Title: &metadata.Title, Notes: &metadata.Notes, Tags: metadata.Tags}
+	if update.Tags == nil {
+		update.Tags = []string{}
+	}
+
+	if err := normalizeUpdate(&update); err != nil {
+		return metadata, err
+	}
+
+	metadata = LinkMetadata{Title: *update.Title, Notes: *update.Notes}
+	if len(update.Tags) > 0 {
+		metadata.Tags = update.Tags
+	}
+
+	return metadata, nil
+}
+
+func (m LinkMetadata) empty() bool {
+	return m.Title == "" && m.Notes == "" && len(m.Tags) == 0
+}
+
+// normalizeUpdate trims the title and notes of update and normalizes its
+// tags in place.
+func normalizeUpdate(update *LinkUpdate) error {
+	var fields []entities.FieldError
+
+	if update.Title != nil {
+		title := strings.TrimSpace(*update.Title)
+		if utf8.RuneCountInString(title) > MaxTitleLength {
+			fields = append(fields, entities.FieldError{Field: "title", Message: ErrInvalidTitle.Message})
+		}
+
+		update.Title = &title
+	}
+
+	if update.Notes != nil {
+		notes := strings.TrimSpace(*update.Notes)
+		if utf8.RuneCountInString(notes) > MaxNotesLength {
+			fields = append(fields, entities.FieldError{Field: "notes", Message: ErrInvalidNotes.Message})
+		}
+
+		update.Notes = &notes
+	}
+
+	if update.Tags != nil {
+		tags, err := normalizeTags(update.Tags)
+		if err != nil {
+			fields = append(fields, entities.FieldError{Field: "tags", Message: ErrInvalidTags.Message})
+		}
+
+		update.Tags = tags
+		if update.Tags == nil {
+			update.Tags = []string{}
+		}
+	}
+
+	if len(fields) > 0 {
+		return entities.ErrValidation.WithFields(fields...)
+	}
+
+	return nil
+}
+
+// normalizeTags trims and lower-cases tags, drops duplicates and sorts them.
+func normalizeTags(tags []string) ([]string, error) {
+	var normalized []string
+
+	for _, tag := range tags {
+		tag, err := normalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+
+		if !slices.Contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+
+	if len(normalized) > MaxTags {
+		return nil, ErrInvalidTags
+	}
+
+	slices.Sort(normalized)
+
+	return normalized, nil
+}
+
+func normalizeTag(tag string) (string, error) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if tag == "" || utf8.RuneCountInString(tag) > MaxTagLength {
+		return "", ErrInvalidTags
+	}
+
+	return tag, nil
+}
@@ -0,0 +1,103 @@
+package usecases_test
+
+import (
+	"context"
+	"testing"
+
+	"lnk/domain/entities"
+	"lnk/domain/entities/usecases"
+	"lnk/extensions/gocqltesting"
+	"lnk/gateways/gocql/repositories"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func Test_UseCase_LinkMetadata(t *testing.T) {
+	t.Parallel()
+
+	session, err := gocqltesting.NewDB(t, t.Name())
+	require.NoError(t, err)
+
+	logger := zap.NewNop()
+
+	useCase := usecases.NewUseCase(usecases.NewUseCaseParams{
+		Logger:      logger,
+		Repository:  repositories.NewRepository(logger, session),
+		IDAllocator: &sequenceAllocator{},
+		Salt:        "test",
+	})
+
+	acme := entities.WithIdentity(context.Background(), entities.Identity{OwnerID: "acme"})
+	globex := entities.WithIdentity(context.Background(), entities.Identity{OwnerID: "globex"})
+
+	link, err := useCase.CreateLink(acme, "", "https://example.com/spring", usecases.LinkMetadata{
+		Title: " Spring sale ",
+		Tags:  []string{"Spring", "newsletter", "spring "},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Spring sale", link.Title)
+	require.Equal(t, []string{"newsletter", "spring"}, link.Tags)
+
+	_, err = useCase.CreateLink(acme, "", "https://example.com/autumn", usecases.LinkMetadata{Tags: []string{"autumn"}})
+	require.NoError(t, err)
+
+	tagged := func(tag string) []string {
+		page, err := useCase.ListLinks(acme, usecases.LinkFilter{Tag: tag}, 10, "")
+		require.NoError(t, err)
+
+		codes := make([]string, 0, len(page.Links))
+		for _, link := range page.Links {
+			codes = append(codes, link.ShortCode)
+		}
+
+		return codes
+	}
+
+	t.Run("lists links by tag", func(t *testing.T) {
+		require.Equal(t, []string{link.ShortCode}, tagged("SPRING"))
+		require.Empty(t, tagged("summer"))
+	})
+
+	t.Run("edits move the link between tags", func(t *testing.T) {
+		notes := "Linked from the March newsletter"
+
+		updated, err := useCase.UpdateLink(acme, "", link.ShortCode, usecases.LinkUpdate{
+			Notes: &notes,
+			Tags:  []string{"newsletter", "summer"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "Spring sale", updated.Title)
+		require.Equal(t, notes, updated.Notes)
+		require.False(t, updated.UpdatedAt.IsZero())
+
+		require.Empty(t, tagged("spring"))
+		require.Equal(t, []string{link.ShortCode}, tagged("summer"))
+
+		stored, err := useCase.GetLink(acme, "", link.ShortCode)
+		require.NoError(t, err)
+		require.Equal(t, []string{"newsletter", "summer"}, stored.Tags)
+		require.Equal(t, updated.UpdatedAt, stored.UpdatedAt)
+
+		page, err := useCase.ListLinks(acme, usecases.LinkFilter{}, 10, "")
+		require.NoError(t, err)
+		require.Equal(t, stored.Tags, page.Links[1].Tags)
+	})
+
+	t.Run("links of other owners cannot be edited", func(t *testing.T) {
+		title := "Hijacked"
+
+		_, err := useCase.UpdateLink(globex, "", link.ShortCode, usecases.LinkUpdate{Title: &title})
+		require.ErrorIs(t, err, usecases.ErrURLNotFound)
+	})
+
+	t.Run("rejects too many tags", func(t *testing.T) {
+		tags := make([]string, usecases.MaxTags+1)
+		for i := range tags {
+			tags[i] = string(rune('a' + i))
+		}
+
+		_, err := useCase.UpdateLink(acme, "", link.ShortCode, usecases.LinkUpdate{Tags: tags})
+		require.ErrorIs(t, err, entities.ErrValidation)
+	})
+}
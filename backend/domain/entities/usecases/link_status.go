@@ -0,0 +1,183 @@
+package usecases
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"lnk/domain/entities"
+	"lnk/extensions/logger"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	MaxStatusReasonLength = 500
+
+	// continueTokenTTL is how long the continue link of a warning works, so a
+	// shared link shows the warning again.
+	continueTokenTTL = 10 * time.Minute
+)
+
+var (
+	ErrLinkWarned        = entities.ErrForbidden.WithMessage("link destination has been flagged; confirm to continue")
+	ErrLinkBlocked       = entities.ErrForbidden.WithMessage("link destination has been blocked")
+	ErrLinkDisabled      = entities.ErrGone.WithMessage("link has been disabled")
+	ErrInvalidLinkStatus = entities.ErrValidation.WithMessage("status must be active, warned, blocked or disabled")
+	ErrInvalidReason     = entities.ErrValidation.WithMessage("reason must be at most 500 characters")
+	ErrContinueExpired   = entities.ErrForbidden.WithMessage("continue link is invalid or has expired")
+)
+
+// InterstitialChoice is what a visitor did with the warning of a link.
+type InterstitialChoice string
+
+const (
+	// InterstitialShown is a warning served to a visitor.
+	InterstitialShown InterstitialChoice = "shown"
+	// InterstitialContinued is a visitor that continued past the warning.
+	InterstitialContinued InterstitialChoice = "continued"
+)
+
+// SetLinkStatus moderates the link shortCode on domain. Warned and blocked
+// links serve a warning with reason instead of redirecting, and disabled links
+// are gone.
+func (uc *UseCase) SetLinkStatus(ctx context.Context, domain, shortCode string, status entities.LinkStatus, reason string) error {
+	if !status.Valid() {
+		return ErrInvalidLinkStatus
+	}
+
+	reason = strings.TrimSpace(reason)
+	if utf8.RuneCountInString(reason) > MaxStatusReasonLength {
+		return ErrInvalidReason
+	}
+
+	found, err := uc.repository.SetURLStatus(ctx, domain, shortCode, status, reason)
+	if err != nil {
+		return ErrStorageUnavailable.Wrap(err)
+	}
+
+	if !found {
+		return ErrURLNotFound
+	}
+
+	logger.FromContext(ctx, uc.logger).Info("Link status changed",
+		zap.String("domain", domain),
+		zap.String("short_code", shortCode),
+		zap.String("status", string(status)),
+		zap.String("reason", reason),
+	)
+
+	return nil
+}
+
+// ContinueToLongURL returns the destination of shortCode on domain for a
+// visitor who confirmed its warning with a token from ContinueToken. Blocked
+// and disabled links still fail, and warned links fail with
+// ErrContinueExpired unless the token is valid.
+func (uc *UseCase) ContinueToLongURL(ctx context.Context, domain, shortCode, token string) (string, error) {
+	link, err := uc.link(ctx, domain, shortCode)
+	if err != nil {
+		return "", err
+	}
+
+	if err := servable(link, true); err != nil {
+		return "", err
+	}
+
+	if link.Status == entities.LinkWarned && !uc.validContinueToken(domain, shortCode, token, time.Now()) {
+		return "", ErrContinueExpired
+	}
+
+	return link.LongURL, nil
+}
+
+// ContinueToken returns the token that lets a visitor shown the warning of
+// shortCode on domain continue past it for the next few minutes.
+func (uc *UseCase) ContinueToken(domain, shortCode string) string {
+	expires := strconv.FormatInt(time.Now().Add(continueTokenTTL).Unix(), 10)
+
+	return expires + "." + uc.continueSignature(domain, shortCode, expires)
+}
+
+func (uc *UseCase) validContinueToken(domain, shortCode, token string, now time.Time) bool {
+	expires, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || now.Unix() > unix {
+		return false
+	}
+
+	return hmac.Equal([]byte(signature), []byte(uc.continueSignature(domain, shortCode, expires)))
+}
+
+func (uc *UseCase) continueSignature(domain, shortCode, expires string) string {
+	mac := hmac.New(sha256.New, uc.continueKey)
+	mac.Write([]byte(domain + "\n" + shortCode + "\n" + expires))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// RecordInterstitialChoice counts what a visitor did with the warning of a
+// link, and adds it to the request's trace. Failing to count it is logged and
+// does not stop the visitor.
+func (uc *UseCase) RecordInterstitialChoice(ctx context.Context, domain, shortCode string, choice InterstitialChoice) {
+	trace.SpanFromContext(ctx).AddEvent("interstitial_choice", trace.WithAttributes(
+		attribute.String("domain", domain),
+		attribute.String("short_code", shortCode),
+		attribute.String("choice", string(choice)),
+	))
+
+	if err := uc.repository.RecordInterstitialChoice(ctx, domain, shortCode, string(choice)); err != nil {
+		logger.FromContext(ctx, uc.logger).Warn("Failed to record interstitial choice",
+			zap.String("domain", domain),
+			zap.String("short_code", shortCode),
+			zap.String("choice", string(choice)),
+			zap.Error(err),
+		)
+	}
+}
+
+// InterstitialChoices returns how many times the warning of shortCode on
+// domain was shown and continued past.
+func (uc *UseCase) InterstitialChoices(ctx context.Context, domain, shortCode string) (map[InterstitialChoice]int64, error) {
+	counts, err := uc.repository.GetInterstitialChoices(ctx, domain, shortCode)
+	if err != nil {
+		return nil, ErrStorageUnavailable.Wrap(err)
+	}
+
+	choices := make(map[InterstitialChoice]int64, len(counts))
+	for choice, count := range counts {
+		choices[InterstitialChoice(choice)] = count
+	}
+
+	return choices, nil
+}
+
+// servable returns the error that is served instead of redirecting to the
+// destination of link, or nil. A confirmed warning no longer stops the visitor.
+func servable(link *entities.URL, confirmed bool) error {
+	switch link.Status {
+	case entities.LinkWarned:
+		if confirmed {
+			return nil
+		}
+
+		return ErrLinkWarned
+	case entities.LinkBlocked:
+		return ErrLinkBlocked
+	case entities.LinkDisabled:
+		return ErrLinkDisabled
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,107 @@
+package usecases_test
+
+import (
+	"context"
+	"testing"
+
+	"lnk/domain/entities"
+	"lnk/domain/entities/usecases"
+	"lnk/extensions/gocqltesting"
+	"lnk/gateways/gocql/repositories"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func Test_UseCase_LinkStatus(t *testing.T) {
+	t.Parallel()
+
+	session, err := gocqltesting.NewDB(t, t.Name())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	logger := zap.NewNop()
+
+	useCase := usecases.NewUseCase(usecases.NewUseCaseParams{
+		Logger:      logger,
+		Repository:  repositories.NewRepository(logger, session),
+		IDAllocator: &sequenceAllocator{},
+		Salt:        "test",
+	})
+
+	link, err := useCase.CreateShortURL(ctx, "", "https://phish.example")
+	require.NoError(t, err)
+
+	t.Run("warned links need confirmation", func(t *testing.T) {
+		require.NoError(t, useCase.SetLinkStatus(ctx, link.Domain, link.ShortCode, entities.LinkWarned, " Reported as phishing "))
+
+		_, err := useCase.GetLongURL(ctx, link.Domain, link.ShortCode)
+		require.ErrorIs(t, err, usecases.ErrLinkWarned)
+
+		longURL, err := useCase.ContinueToLongURL(ctx, link.Domain, link.ShortCode, useCase.ContinueToken(link.Domain, link.ShortCode))
+		require.NoError(t, err)
+		require.Equal(t, "https://phish.example", longURL)
+
+		for _, token := range []string{"", "true", "1.forged", useCase.ContinueToken(link.Domain, "other")} {
+			_, err = useCase.ContinueToLongURL(ctx, link.Domain, link.ShortCode, token)
+			require.ErrorIs(t, err, usecases.ErrContinueExpired, token)
+		}
+
+		useCase.RecordInterstitialChoice(ctx, link.Domain, link.ShortCode, usecases.InterstitialShown)
+		useCase.RecordInterstitialChoice(ctx, link.Domain, link.ShortCode, usecases.InterstitialShown)
+		useCase.RecordInterstitialChoice(ctx, link.Domain, link.ShortCode, usecases.InterstitialContinued)
+
+		choices, err := useCase.InterstitialChoices(ctx, link.Domain, link.ShortCode)
+		require.NoError(t, err)
+		require.Equal(t, map[usecases.InterstitialChoice]int64{usecases.InterstitialShown: 2, usecases.InterstitialContinued: 1}, choices)
+
+		destination, err := useCase.InspectLink(ctx, link.Domain, link.ShortCode)
+		require.NoError(t, err)
+		require.Equal(t, usecases.SafetyWarned, destination.Safety)
+		require.Equal(t, "Reported as phishing", destination.Reason)
+	})
+
+	t.Run("blocked links cannot be continued", func(t *testing.T) {
+		require.NoError(t, useCase.SetLinkStatus(ctx, link.Domain, link.ShortCode, entities.LinkBlocked, ""))
+
+		_, err := useCase.GetLongURL(ctx, link.Domain, link.ShortCode)
+		require.ErrorIs(t, err, usecases.ErrLinkBlocked)
+
+		destination, err := useCase.ResolveLink(ctx, link.Domain, link.ShortCode)
+		require.NoError(t, err)
+		require.Equal(t, usecases.SafetyBlocked, destination.Safety)
+		require.Equal(t, "https://phish.example", destination.LongURL)
+
+		_, err = useCase.ContinueToLongURL(ctx, link.Domain, link.ShortCode, useCase.ContinueToken(link.Domain, link.ShortCode))
+		require.ErrorIs(t, err, usecases.ErrLinkBlocked)
+	})
+
+	t.Run("disabled links are gone", func(t *testing.T) {
+		require.NoError(t, useCase.SetLinkStatus(ctx, link.Domain, link.ShortCode, entities.LinkDisabled, ""))
+
+		_, err := useCase.GetLongURL(ctx, link.Domain, link.ShortCode)
+		require.ErrorIs(t, err, usecases.ErrLinkDisabled)
+
+		_, err = useCase.InspectLink(ctx, link.Domain, link.ShortCode)
+		require.ErrorIs(t, err, usecases.ErrLinkDisabled)
+
+		_, err = useCase.ResolveLink(ctx, link.Domain, link.ShortCode)
+		require.ErrorIs(t, err, usecases.ErrLinkDisabled)
+	})
+
+	t.Run("reactivated links redirect", func(t *testing.T) {
+		require.NoError(t, useCase.SetLinkStatus(ctx, link.Domain, link.ShortCode, entities.LinkActive, ""))
+
+		longURL, err := useCase.GetLongURL(ctx, link.Domain, link.ShortCode)
+		require.NoError(t, err)
+		require.Equal(t, "https://phish.example", longURL)
+	})
+
+	t.Run("invalid statuses and unknown links", func(t *testing.T) {
+		err := useCase.SetLinkStatus(ctx, link.Domain, link.ShortCode, "quarantined", "")
+		require.ErrorIs(t, err, usecases.ErrInvalidLinkStatus)
+
+		err = useCase.SetLinkStatus(ctx, link.Domain, "missing", entities.LinkWarned, "")
+		require.ErrorIs(t, err, usecases.ErrURLNotFound)
+	})
+}
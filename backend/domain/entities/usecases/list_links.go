@@ -0,0 +1,208 @@
+package usecases
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+	"strings"
+	"time"
+
+	"lnk/domain/entities"
+)
+
+const (
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+
+	// maxListFetches bounds the pages read for one listing request, so a
+	// filter matching few links cannot make a request scan the whole owner.
+	// The listing then returns fewer links than asked for, with a cursor.
+	maxListFetches = 10
+	// cursorFingerprintSize is the length of the query fingerprint that
+	// prefixes the paging state in a cursor.
+	cursorFingerprintSize = 8
+)
+
+var (
+	ErrOwnerRequired = entities.ErrForbidden.WithMessage("managing links requires an owner")
+	ErrInvalidCursor = entities.ErrValidation.WithMessage("cursor is invalid or belongs to a different query")
+)
+
+// listEnd is the exclusive upper bound of listings without CreatedTo.
+var listEnd = time.Date(9999, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// LinkFilter selects the links of a listing. Zero fields match every link.
+type LinkFilter struct {
+	// CreatedFrom is inclusive and CreatedTo exclusive.
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	Domain      string
+	// Tag selects the links with this tag; it is read from the tag's listing
+	// rather than filtered.
+	Tag string
+	// Search matches a prefix of the short code or of the destination host,
+	// ignoring case and a leading "www.".
+	Search string
+}
+
+// LinkPage is one page of a listing, newest link first.
+type LinkPage struct {
+	// Cursor resumes the listing after Links. It is empty on the last page.
+	Cursor string
+	Links  []entities.URL
+}
+
+// ListLinks returns up to limit links of the caller's owner that match
+// filter, resuming after cursor when it is set.
+//
+// Links are read from the owner's listing in creation order and filtered as
+// they are read. Each read asks for no more rows than are still missing, so
+// the paging state always points right after the last link returned. A page
+// may hold fewer than limit links while Cursor is set.
+func (uc *UseCase) ListLinks(ctx context.Context, filter LinkFilter, limit int, cursor string) (*LinkPage, error) {
+	ownerID := entities.IdentityFromContext(ctx).OwnerID
+	if ownerID == "" {
+		return nil, ErrOwnerRequired
+	}
+
+	if uc.workspaces.Enforced {
+		if _, _, err := uc.authorizeWorkspace(ctx, ownerID, entities.RoleViewer); err != nil {
+			return nil, err
+		}
+	}
+
+	if limit <= 0 || limit > MaxListLimit {
+		limit = DefaultListLimit
+	}
+
+	filter, err := uc.normalizeFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	fingerprint := filter.fingerprint(ownerID)
+
+	pageState, err := decodeCursor(cursor, fingerprint)
+	if err != nil {
+		return nil, err
+	}
+
+	links := make([]entities.URL, 0, limit)
+
+	for range maxListFetches {
+		urls, next, err := uc.listPage(ctx, ownerID, filter, limit-len(links), pageState)
+		if err != nil {
+			return nil, ErrStorageUnavailable.Wrap(err)
+		}
+
+		for i := range urls {
+			if filter.matches(&urls[i]) {
+				links = append(links, urls[i])
+			}
+		}
+
+		pageState = next
+
+		if len(pageState) == 0 || len(links) == limit {
+			break
+		}
+	}
+
+	page := &LinkPage{Links: links}
+	if len(pageState) > 0 {
+		page.Cursor = base64.RawURLEncoding.EncodeToString(append(fingerprint, pageState...))
+	}
+
+	return page, nil
+}
+
+func (uc *UseCase) listPage(ctx context.Context, ownerID string, filter LinkFilter, pageSize int, pageState []byte) ([]entities.URL, []byte, error) {
+	if filter.Tag != "" {
+		return uc.repository.ListTagURLs(ctx, ownerID, filter.Tag, filter.CreatedFrom, filter.CreatedTo, pageSize, pageState)
+	}
+
+	return uc.repository.ListOwnerURLs(ctx, ownerID, filter.CreatedFrom, filter.CreatedTo, pageSize, pageState)
+}
+
+func (uc *UseCase) normalizeFilter(filter LinkFilter) (LinkFilter, error) {
+	if filter.Domain != "" {
+		domain, err := uc.domains.Validate(filter.Domain)
+		if err != nil {
+			return filter, err
+		}
+
+		filter.Domain = domain
+	}
+
+	if filter.Tag != "" {
+		tag, err := normalizeTag(filter.Tag)
+		if err != nil {
+			return filter, err
+		}
+
+		filter.Tag = tag
+	}
+
+	if filter.CreatedTo.IsZero() {
+		filter.CreatedTo = listEnd
+	}
+
+	filter.CreatedFrom = filter.CreatedFrom.UTC()
+	filter.CreatedTo = filter.CreatedTo.UTC()
+	filter.Search = strings.ToLower(filter.Search)
+
+	return filter, nil
+}
+
+func (f LinkFilter) matches(link *entities.URL) bool {
+	if f.Domain != "" && link.Domain != f.Domain {
+		return false
+	}
+
+	if f.Search == "" || strings.HasPrefix(strings.ToLower(link.ShortCode), f.Search) {
+		return true
+	}
+
+	parsed, err := url.Parse(link.LongURL)
+	if err != nil {
+		return false
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+
+	return strings.HasPrefix(host, f.Search) || strings.HasPrefix(strings.TrimPrefix(host, "www."), f.Search)
+}
+
+// fingerprint identifies the query a cursor was issued for. Cassandra paging
+// state is only valid for the query that produced it.
+func (f LinkFilter) fingerprint(ownerID string) []byte {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		ownerID,
+		f.Domain,
+		f.Tag,
+		f.Search,
+		f.CreatedFrom.Format(time.RFC3339Nano),
+		f.CreatedTo.Format(time.RFC3339Nano),
+	}, "\x00")))
+
+	return sum[:cursorFingerprintSize]
+}
+
+func decodeCursor(cursor string, fingerprint []byte) ([]byte, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor.Wrap(err)
+	}
+
+	if len(decoded) <= cursorFingerprintSize || !bytes.Equal(decoded[:cursorFingerprintSize], fingerprint) {
+		return nil, ErrInvalidCursor
+	}
+
+	return decoded[cursorFingerprintSize:], nil
+}
@@ -0,0 +1,92 @@
+package usecases_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"lnk/domain/entities"
+	"lnk/domain/entities/usecases"
+	"lnk/extensions/gocqltesting"
+	"lnk/gateways/gocql/repositories"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type sequenceAllocator struct {
+	last atomic.Int64
+}
+
+func (a *sequenceAllocator) Next(context.Context) (int64, error) {
+	return a.last.Add(1), nil
+}
+
+func Test_UseCase_ListLinks(t *testing.T) {
+	t.Parallel()
+
+	session, err := gocqltesting.NewDB(t, t.Name())
+	require.NoError(t, err)
+
+	logger := zap.NewNop()
+
+	useCase := usecases.NewUseCase(usecases.NewUseCaseParams{
+		Logger:      logger,
+		Repository:  repositories.NewRepository(logger, session),
+		IDAllocator: &sequenceAllocator{},
+		Salt:        "test",
+	})
+
+	acme := entities.WithIdentity(context.Background(), entities.Identity{OwnerID: "acme"})
+	globex := entities.WithIdentity(context.Background(), entities.Identity{OwnerID: "globex"})
+
+	for _, longURL := range []string{
+		"https://www.example.com/1",
+		"https://docs.example.org/2",
+		"https://example.com/3",
+		"https://other.example/4",
+	} {
+		_, err := useCase.CreateShortURL(acme, "", longURL)
+		require.NoError(t, err)
+	}
+
+	_, err = useCase.CreateShortURL(globex, "", "https://example.com/globex")
+	require.NoError(t, err)
+
+	t.Run("pages through the owner's links", func(t *testing.T) {
+		first, err := useCase.ListLinks(acme, usecases.LinkFilter{}, 3, "")
+		require.NoError(t, err)
+		require.Len(t, first.Links, 3)
+		require.NotEmpty(t, first.Cursor)
+
+		second, err := useCase.ListLinks(acme, usecases.LinkFilter{}, 3, first.Cursor)
+		require.NoError(t, err)
+		require.Len(t, second.Links, 1)
+		require.Empty(t, second.Cursor)
+
+		seen := map[string]bool{}
+		for _, link := range append(first.Links, second.Links...) {
+			require.Equal(t, "acme", link.OwnerID)
+			seen[link.ShortCode] = true
+		}
+
+		require.Len(t, seen, 4)
+	})
+
+	t.Run("searches destination hosts", func(t *testing.T) {
+		page, err := useCase.ListLinks(acme, usecases.LinkFilter{Search: "Example.com"}, 10, "")
+		require.NoError(t, err)
+		require.Len(t, page.Links, 2)
+	})
+
+	t.Run("rejects a cursor from another query", func(t *testing.T) {
+		page, err := useCase.ListLinks(acme, usecases.LinkFilter{}, 1, "")
+		require.NoError(t, err)
+
+		_, err = useCase.ListLinks(acme, usecases.LinkFilter{Search: "docs"}, 1, page.Cursor)
+		require.ErrorIs(t, err, usecases.ErrInvalidCursor)
+
+		_, err = useCase.ListLinks(globex, usecases.LinkFilter{}, 1, page.Cursor)
+		require.ErrorIs(t, err, usecases.ErrInvalidCursor)
+	})
+}
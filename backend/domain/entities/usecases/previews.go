@@ -0,0 +1,202 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"lnk/domain/entities"
+	"lnk/extensions/pagemeta"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultPreviewWorkers   = 4
+	defaultPreviewQueueSize = 1000
+	defaultPreviewAttempts  = 3
+	defaultPreviewBackoff   = 2 * time.Second
+	// previewDropInterval is how often skipped fetches are logged, so a full
+	// queue during a bulk creation logs once instead of once per link.
+	previewDropInterval = time.Minute
+)
+
+var ErrPreviewUnavailable = entities.ErrUnavailable.WithMessage("link previews are temporarily unavailable")
+
+// PageFetcher fetches the metadata of destination pages. Errors with a
+// Temporary method that reports true are retried.
+type PageFetcher interface {
+	Fetch(ctx context.Context, rawURL string) (*entities.PageMetadata, error)
+}
+
+// PreviewPolicy configures fetching the destination metadata of new links in
+// the background. Without a Fetcher nothing is fetched.
+type PreviewPolicy struct {
+	Fetcher PageFetcher
+	// Backoff is the wait before the first retry; it doubles with every
+	// further retry.
+	Backoff   time.Duration
+	Workers   int
+	QueueSize int
+	// Attempts bounds the fetches of one destination, including the first.
+	Attempts int
+}
+
+func (p PreviewPolicy) withDefaults() PreviewPolicy {
+	if p.Workers <= 0 {
+		p.Workers = defaultPreviewWorkers
+	}
+
+	if p.QueueSize <= 0 {
+		p.QueueSize = defaultPreviewQueueSize
+	}
+
+	if p.Attempts <= 0 {
+		p.Attempts = defaultPreviewAttempts
+	}
+
+	if p.Backoff <= 0 {
+		p.Backoff = defaultPreviewBackoff
+	}
+
+	return p
+}
+
+// LinkPreview returns the fetched metadata of the destination of shortCode on
+// domain, or nil when it has not been fetched yet.
+func (uc *UseCase) LinkPreview(ctx context.Context, domain, shortCode string) (*entities.LinkPreview, error) {
+	preview, err := uc.repository.GetLinkPreview(ctx, domain, shortCode)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, ErrPreviewUnavailable.Wrap(err)
+	}
+
+	return preview, nil
+}
+
+// RunPreviewFetches fetches the destinations of new links until ctx is done,
+// then returns once the fetches in progress have been stored. Links are queued
+// in memory when they are created, so links still queued at shutdown, or
+// created while the queue is full, are not fetched.
+func (uc *UseCase) RunPreviewFetches(ctx context.Context) {
+	if uc.previewQueue == nil {
+		return
+	}
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(previewDropInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				uc.logPreviewDrops()
+				return
+			case <-ticker.C:
+				uc.logPreviewDrops()
+			}
+		}
+	}()
+
+	for range uc.previews.Workers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case link := <-uc.previewQueue:
+					uc.fetchPreview(ctx, &link)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+// enqueuePreview queues link for a preview fetch without blocking. Flagged
+// links are skipped, so the service never requests a destination on a threat
+// list.
+func (uc *UseCase) enqueuePreview(link *entities.URL) {
+	if uc.previewQueue == nil || link.Status != "" {
+		return
+	}
+
+	select {
+	case uc.previewQueue <- *link:
+	default:
+		uc.previewDrops.Add(1)
+	}
+}
+
+// logPreviewDrops logs the links skipped since the last call.
+func (uc *UseCase) logPreviewDrops() {
+	if dropped := uc.previewDrops.Swap(0); dropped > 0 {
+		uc.logger.Warn("Preview queue was full; skipped destination metadata", zap.Int64("skipped", dropped))
+	}
+}
+
+// fetchPreview fetches the destination of link, retrying temporary failures
+// with exponential backoff, and stores the outcome. An attempt under way when
+// ctx is done still finishes within the fetcher's timeout and is stored, so
+// shutdown drains it; only further retries are abandoned.
+func (uc *UseCase) fetchPreview(ctx context.Context, link *entities.URL) {
+	log := uc.logger.With(zap.String("domain", link.Domain), zap.String("short_code", link.ShortCode))
+	preview := entities.LinkPreview{Domain: link.Domain, ShortCode: link.ShortCode, Status: entities.PreviewFailed}
+
+	backoff := uc.previews.Backoff
+	work := context.WithoutCancel(ctx)
+
+	for attempt := 1; ; attempt++ {
+		page, err := uc.previews.Fetcher.Fetch(work, link.LongURL)
+		if err == nil {
+			preview.Status = entities.PreviewFetched
+			preview.Page = *page
+
+			break
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		if !pagemeta.Temporary(err) || attempt == uc.previews.Attempts {
+			log.Debug("Failed to fetch destination metadata", zap.Int("attempt", attempt), zap.Error(err))
+
+			break
+		}
+
+		// Jitter spreads out retries of destinations that failed together.
+		wait := backoff/2 + rand.N(backoff/2+1)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+
+		backoff *= 2
+	}
+
+	preview.FetchedAt = time.Now().UTC()
+
+	if err := uc.repository.PutLinkPreview(work, &preview); err != nil {
+		log.Warn("Failed to store link preview", zap.Error(err))
+	}
+}
@@ -0,0 +1,114 @@
+package usecases_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"lnk/domain/entities"
+	"lnk/domain/entities/usecases"
+	"lnk/extensions/gocqltesting"
+	"lnk/gateways/gocql/repositories"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type temporaryError struct{}
+
+func (temporaryError) Error() string   { return "service unavailable" }
+func (temporaryError) Temporary() bool { return true }
+
+// flakyFetcher fails every destination once with a temporary error.
+type flakyFetcher struct {
+	calls atomic.Int64
+}
+
+func (f *flakyFetcher) Fetch(_ context.Context, rawURL string) (*entities.PageMetadata, error) {
+	if f.calls.Add(1)%2 == 1 {
+		return nil, temporaryError{}
+	}
+
+	if rawURL == "https://example.com/private" {
+		return nil, errors.New("disallowed by robots.txt")
+	}
+
+	return &entities.PageMetadata{Title: "Example Domain"}, nil
+}
+
+func Test_UseCase_Previews(t *testing.T) {
+	t.Parallel()
+
+	session, err := gocqltesting.NewDB(t, t.Name())
+	require.NoError(t, err)
+
+	logger := zap.NewNop()
+	fetcher := &flakyFetcher{}
+	matcher := &hostMatcher{}
+	matcher.list("bad.example")
+
+	useCase := usecases.NewUseCase(usecases.NewUseCaseParams{
+		Logger:      logger,
+		Repository:  repositories.NewRepository(logger, session),
+		IDAllocator: &sequenceAllocator{},
+		Salt:        "test",
+		Threats:     usecases.ThreatPolicy{Matcher: matcher},
+		Previews: usecases.PreviewPolicy{
+			Fetcher: fetcher,
+			Workers: 1,
+			Backoff: time.Millisecond,
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	go useCase.RunPreviewFetches(ctx)
+
+	// The flagged link is queued first, so the single worker would have
+	// fetched it before the others.
+	flagged, err := useCase.CreateShortURL(context.Background(), "", "https://bad.example")
+	require.NoError(t, err)
+
+	fetched, err := useCase.CreateShortURL(context.Background(), "", "https://example.com")
+	require.NoError(t, err)
+
+	failed, err := useCase.CreateShortURL(context.Background(), "", "https://example.com/private")
+	require.NoError(t, err)
+
+	preview := func(link *entities.URL) *entities.LinkPreview {
+		var preview *entities.LinkPreview
+
+		require.Eventually(t, func() bool {
+			var err error
+
+			preview, err = useCase.LinkPreview(context.Background(), link.Domain, link.ShortCode)
+
+			return err == nil && preview != nil
+		}, 10*time.Second, 10*time.Millisecond)
+
+		return preview
+	}
+
+	t.Run("retries temporary failures", func(t *testing.T) {
+		result := preview(fetched)
+		require.Equal(t, entities.PreviewFetched, result.Status)
+		require.Equal(t, "Example Domain", result.Page.Title)
+	})
+
+	t.Run("records permanent failures", func(t *testing.T) {
+		result := preview(failed)
+		require.Equal(t, entities.PreviewFailed, result.Status)
+		require.Empty(t, result.Page.Title)
+	})
+
+	t.Run("skips flagged links", func(t *testing.T) {
+		preview(failed)
+
+		result, err := useCase.LinkPreview(context.Background(), flagged.Domain, flagged.ShortCode)
+		require.NoError(t, err)
+		require.Nil(t, result)
+	})
+}
@@ -0,0 +1,192 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"lnk/domain/entities"
+	"lnk/extensions/logger"
+
+	"go.uber.org/zap"
+)
+
+const defaultThreatScanInterval = time.Hour
+
+// ThreatMatcher reports whether a destination is on a threat list, and names
+// the list. Lists of short hash prefixes can match destinations that are not
+// on them, which is why matches only warn and never block.
+type ThreatMatcher interface {
+	Match(rawURL string) (string, bool)
+}
+
+// ScanLease lets one replica at a time scan the stored links.
+type ScanLease interface {
+	// Acquire takes the lease for ttl unless another replica holds it, and
+	// reports whether it was taken.
+	Acquire(ctx context.Context, ttl time.Duration) (bool, error)
+}
+
+// ThreatPolicy flags links whose destination is on a threat list, so they
+// serve the interstitial. Without a Matcher nothing is checked.
+type ThreatPolicy struct {
+	Matcher ThreatMatcher
+	// Lease lets a single replica scan each ScanInterval. Without one, every
+	// replica scans.
+	Lease ScanLease
+	// ScanInterval is the time between checks of every stored link, which
+	// catch destinations added to the list after their links were created.
+	ScanInterval time.Duration
+}
+
+func (p ThreatPolicy) withDefaults() ThreatPolicy {
+	if p.ScanInterval <= 0 {
+		p.ScanInterval = defaultThreatScanInterval
+	}
+
+	return p
+}
+
+// ThreatScan is the outcome of one check of every stored link.
+type ThreatScan struct {
+	Scanned int
+	Flagged int
+}
+
+// flagThreat warns about url before it is stored when its destination is on a
+// threat list.
+func (uc *UseCase) flagThreat(ctx context.Context, url *entities.URL) {
+	if uc.threats.Matcher == nil {
+		return
+	}
+
+	list, matched := uc.threats.Matcher.Match(url.LongURL)
+	if !matched {
+		return
+	}
+
+	url.Status = entities.LinkWarned
+	url.StatusReason = threatReason(list)
+
+	logger.FromContext(ctx, uc.logger).Warn("New link matches a threat list",
+		zap.String("domain", url.Domain),
+		zap.String("list", list),
+	)
+}
+
+// ScanThreats checks the destination of every link against the threat lists
+// and warns about the links that match. Links whose status was ever set, by an
+// operator or an earlier scan, are left alone, so an operator can clear a
+// false positive by setting the link active. When ctx is done the scan stops
+// between links, finishing the flag it is writing, and returns ctx's error.
+func (uc *UseCase) ScanThreats(ctx context.Context) (ThreatScan, error) {
+	var scan ThreatScan
+
+	if uc.threats.Matcher == nil {
+		return scan, nil
+	}
+
+	err := uc.repository.EachURLStatus(ctx, func(url *entities.URL) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		scan.Scanned++
+
+		if url.Status != "" {
+			return nil
+		}
+
+		list, matched := uc.threats.Matcher.Match(url.LongURL)
+		if !matched {
+			return nil
+		}
+
+		flagged, err := uc.repository.FlagURL(context.WithoutCancel(ctx), url, entities.LinkWarned, threatReason(list))
+		if err != nil {
+			return err
+		}
+
+		if flagged {
+			scan.Flagged++
+
+			logger.FromContext(ctx, uc.logger).Warn("Link matches a threat list",
+				zap.String("domain", url.Domain),
+				zap.String("short_code", url.ShortCode),
+				zap.String("list", list),
+			)
+		}
+
+		return nil
+	})
+	if ctx.Err() != nil {
+		return scan, ctx.Err()
+	}
+
+	if err != nil {
+		return scan, ErrStorageUnavailable.Wrap(err)
+	}
+
+	return scan, nil
+}
+
+// RunThreatScans checks every link each ScanInterval until ctx is done, unless
+// another replica holds the scan lease. A scan under way at shutdown stops
+// after the link it is on; the next scan covers the rest.
+func (uc *UseCase) RunThreatScans(ctx context.Context) {
+	if uc.threats.Matcher == nil {
+		return
+	}
+
+	ticker := time.NewTicker(uc.threats.ScanInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !uc.acquireScanLease(ctx) {
+				continue
+			}
+
+			scan, err := uc.ScanThreats(ctx)
+			if ctx.Err() != nil {
+				logger.FromContext(ctx, uc.logger).Info("Stopped threat scan for shutdown", zap.Int("scanned", scan.Scanned), zap.Int("flagged", scan.Flagged))
+				return
+			}
+
+			if err != nil {
+				logger.FromContext(ctx, uc.logger).Error("Failed to scan links for threats", zap.Int("scanned", scan.Scanned), zap.Error(err))
+				continue
+			}
+
+			logger.FromContext(ctx, uc.logger).Info("Scanned links for threats", zap.Int("scanned", scan.Scanned), zap.Int("flagged", scan.Flagged))
+		}
+	}
+}
+
+// acquireScanLease reports whether this replica runs the next scan. The lease
+// ends a little before the next tick, so the replica that scanned keeps
+// scanning and another takes over within an interval when it stops. A lease
+// that cannot be checked skips the scan rather than risk every replica
+// scanning.
+func (uc *UseCase) acquireScanLease(ctx context.Context) bool {
+	if uc.threats.Lease == nil {
+		return true
+	}
+
+	ttl := uc.threats.ScanInterval - uc.threats.ScanInterval/10
+
+	acquired, err := uc.threats.Lease.Acquire(ctx, ttl)
+	if err != nil {
+		logger.FromContext(ctx, uc.logger).Warn("Failed to acquire threat scan lease", zap.Error(err))
+		return false
+	}
+
+	return acquired
+}
+
+func threatReason(list string) string {
+	return fmt.Sprintf("The destination is on the %s threat list.", list)
+}
@@ -0,0 +1,148 @@
+package usecases_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"lnk/domain/entities"
+	"lnk/domain/entities/usecases"
+	"lnk/extensions/gocqltesting"
+	"lnk/gateways/gocql/repositories"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// hostMatcher lists every destination containing its listed host.
+type hostMatcher struct {
+	listed atomic.Pointer[string]
+}
+
+func (m *hostMatcher) Match(rawURL string) (string, bool) {
+	listed := m.listed.Load()
+	if listed == nil || !strings.Contains(rawURL, *listed) {
+		return "", false
+	}
+
+	return "malware", true
+}
+
+func (m *hostMatcher) list(host string) {
+	m.listed.Store(&host)
+}
+
+// switchLease is held by another replica until it is freed.
+type switchLease struct {
+	free  atomic.Bool
+	calls atomic.Int64
+}
+
+func (l *switchLease) Acquire(context.Context, time.Duration) (bool, error) {
+	l.calls.Add(1)
+
+	return l.free.Load(), nil
+}
+
+func Test_UseCase_Threats(t *testing.T) {
+	t.Parallel()
+
+	session, err := gocqltesting.NewDB(t, t.Name())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	logger := zap.NewNop()
+	matcher := &hostMatcher{}
+	matcher.list("bad.example")
+
+	useCase := usecases.NewUseCase(usecases.NewUseCaseParams{
+		Logger:      logger,
+		Repository:  repositories.NewRepository(logger, session),
+		IDAllocator: &sequenceAllocator{},
+		Salt:        "test",
+		Threats:     usecases.ThreatPolicy{Matcher: matcher},
+	})
+
+	t.Run("new links are flagged when created", func(t *testing.T) {
+		link, err := useCase.CreateShortURL(ctx, "", "https://bad.example/login")
+		require.NoError(t, err)
+
+		_, err = useCase.GetLongURL(ctx, link.Domain, link.ShortCode)
+		require.ErrorIs(t, err, usecases.ErrLinkWarned)
+
+		destination, err := useCase.InspectLink(ctx, link.Domain, link.ShortCode)
+		require.NoError(t, err)
+		require.Equal(t, usecases.SafetyWarned, destination.Safety)
+		require.Equal(t, "The destination is on the malware threat list.", destination.Reason)
+	})
+
+	t.Run("scans flag links listed after creation", func(t *testing.T) {
+		link, err := useCase.CreateShortURL(ctx, "", "https://later.example")
+		require.NoError(t, err)
+
+		cleared, err := useCase.CreateShortURL(ctx, "", "https://later.example/safe")
+		require.NoError(t, err)
+		require.NoError(t, useCase.SetLinkStatus(ctx, cleared.Domain, cleared.ShortCode, entities.LinkActive, ""))
+
+		matcher.list("later.example")
+
+		scan, err := useCase.ScanThreats(ctx)
+		require.NoError(t, err)
+		require.Equal(t, usecases.ThreatScan{Scanned: 3, Flagged: 1}, scan)
+
+		_, err = useCase.GetLongURL(ctx, link.Domain, link.ShortCode)
+		require.ErrorIs(t, err, usecases.ErrLinkWarned)
+
+		longURL, err := useCase.GetLongURL(ctx, cleared.Domain, cleared.ShortCode)
+		require.NoError(t, err)
+		require.Equal(t, "https://later.example/safe", longURL)
+
+		scan, err = useCase.ScanThreats(ctx)
+		require.NoError(t, err)
+		require.Zero(t, scan.Flagged)
+	})
+
+	t.Run("scheduled scans wait for the lease", func(t *testing.T) {
+		link, err := useCase.CreateShortURL(ctx, "", "https://leased.example")
+		require.NoError(t, err)
+
+		matcher.list("leased.example")
+
+		lease := &switchLease{}
+		scanner := usecases.NewUseCase(usecases.NewUseCaseParams{
+			Logger:     logger,
+			Repository: repositories.NewRepository(logger, session),
+			Salt:       "test",
+			Threats:    usecases.ThreatPolicy{Matcher: matcher, Lease: lease, ScanInterval: 10 * time.Millisecond},
+		})
+
+		scanCtx, cancel := context.WithCancel(ctx)
+		t.Cleanup(cancel)
+
+		go scanner.RunThreatScans(scanCtx)
+
+		require.Eventually(t, func() bool { return lease.calls.Load() >= 3 }, 5*time.Second, 10*time.Millisecond)
+
+		_, err = useCase.GetLongURL(ctx, link.Domain, link.ShortCode)
+		require.NoError(t, err)
+
+		lease.free.Store(true)
+
+		require.Eventually(t, func() bool {
+			_, err := useCase.GetLongURL(ctx, link.Domain, link.ShortCode)
+
+			return errors.Is(err, usecases.ErrLinkWarned)
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+	t.Run("scans stop at shutdown", func(t *testing.T) {
+		stopped, cancel := context.WithCancel(ctx)
+		cancel()
+
+		scan, err := useCase.ScanThreats(stopped)
+		require.ErrorIs(t, err, context.Canceled)
+		require.Zero(t, scan.Flagged)
+	})
+}
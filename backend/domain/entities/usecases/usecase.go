@@ -1,38 +1,150 @@
 package usecases
 
 import (
-	"errors"
+	"context"
+	"crypto/sha256"
+	"sync/atomic"
 
+	"lnk/domain/entities"
 	"lnk/extensions/redis"
 	"lnk/gateways/gocql/repositories"
 
 	"go.uber.org/zap"
 )
 
-var ErrURLNotFound = errors.New("URL not found")
+var (
+	ErrURLNotFound        = entities.ErrNotFound.WithMessage("URL not found")
+	ErrStorageUnavailable = entities.ErrUnavailable.WithMessage("URL storage is temporarily unavailable")
+	ErrCounterUnavailable = entities.ErrUnavailable.WithMessage("short code allocation is temporarily unavailable")
+)
+
+// IDAllocator hands out unique counter values for new short codes.
+type IDAllocator interface {
+	Next(ctx context.Context) (int64, error)
+}
 
 type UseCase struct {
-	logger     *zap.Logger
-	repository *repositories.Repository
-	redis      redis.Redis
-	salt       string
-	counterKey string
+	redis           redis.Redis
+	ids             IDAllocator
+	logger          *zap.Logger
+	repository      *repositories.Repository
+	domains         *Domains
+	previewQueue    chan entities.URL
+	threats         ThreatPolicy
+	salt            string
+	continueKey     []byte
+	counterKey      string
+	counterRedisKey string
+	workspaces      WorkspacePolicy
+	dedup           DedupPolicy
+	previews        PreviewPolicy
+	counterHeadroom int64
+	bulkConcurrency int
+	// previewDrops counts links skipped because the preview queue was full,
+	// logged in aggregate by RunPreviewFetches.
+	previewDrops atomic.Int64
 }
 
 type NewUseCaseParams struct {
-	Logger     *zap.Logger
-	Repository *repositories.Repository
-	Redis      redis.Redis
-	Salt       string
-	CounterKey string
+	Redis       redis.Redis
+	IDAllocator IDAllocator
+	Logger      *zap.Logger
+	Repository  *repositories.Repository
+	// Domains are the short domains links are served from. They default to
+	// http://localhost:8080 alone.
+	Domains         *Domains
+	Threats         ThreatPolicy
+	Salt            string
+	ContinueSecret  string
+	CounterKey      string
+	CounterRedisKey string
+	Workspaces      WorkspacePolicy
+	Dedup           DedupPolicy
+	Previews        PreviewPolicy
+	CounterHeadroom int64
+	// BulkConcurrency bounds the concurrent writes of one bulk creation.
+	BulkConcurrency int
 }
 
+// NewUseCase builds the use case. Without an IDAllocator, every new short code
+// increments the Redis counter directly. CounterKey names the counter and its
+// checkpoints; CounterRedisKey, which defaults to CounterKey, is where the
+// counter is kept in Redis, such as CounterKey in a hash tag. ContinueSecret
+// signs the continue links of warnings and defaults to a key derived from
+// Salt.
 func NewUseCase(params NewUseCaseParams) *UseCase {
+	if params.CounterRedisKey == "" {
+		params.CounterRedisKey = params.CounterKey
+	}
+
+	ids := params.IDAllocator
+	if ids == nil {
+		ids = &incrAllocator{redis: params.Redis, key: params.CounterRedisKey}
+	}
+
+	domains := params.Domains
+	if domains == nil {
+		domains, _ = NewDomains(defaultBaseURL, nil)
+	}
+
+	if params.BulkConcurrency <= 0 {
+		params.BulkConcurrency = defaultBulkConcurrency
+	}
+
+	continueKey := []byte(params.ContinueSecret)
+	if len(continueKey) == 0 {
+		derived := sha256.Sum256([]byte("lnk continue\n" + params.Salt))
+		continueKey = derived[:]
+	}
+
+	var previewQueue chan entities.URL
+
+	previews := params.Previews.withDefaults()
+	if previews.Fetcher != nil {
+		previewQueue = make(chan entities.URL, previews.QueueSize)
+	}
+
 	return &UseCase{
-		logger:     params.Logger,
-		repository: params.Repository,
-		redis:      params.Redis,
-		salt:       params.Salt,
-		counterKey: params.CounterKey,
+		logger:          params.Logger,
+		repository:      params.Repository,
+		redis:           params.Redis,
+		ids:             ids,
+		dedup:           params.Dedup,
+		workspaces:      params.Workspaces,
+		previews:        previews,
+		previewQueue:    previewQueue,
+		threats:         params.Threats.withDefaults(),
+		domains:         domains,
+		salt:            params.Salt,
+		continueKey:     continueKey,
+		counterKey:      params.CounterKey,
+		counterRedisKey: params.CounterRedisKey,
+		counterHeadroom: params.CounterHeadroom,
+		bulkConcurrency: params.BulkConcurrency,
 	}
 }
+
+// ResolveDomain maps a request Host header to the short domain it serves.
+func (uc *UseCase) ResolveDomain(host string) string {
+	return uc.domains.Resolve(host)
+}
+
+// ValidateDomain returns the configured domain for a client-chosen one, or the
+// default domain when none is chosen.
+func (uc *UseCase) ValidateDomain(domain string) (string, error) {
+	return uc.domains.Validate(domain)
+}
+
+// ShortURL returns the fully qualified URL of a short code on domain.
+func (uc *UseCase) ShortURL(domain, shortCode string) string {
+	return uc.domains.ShortURL(domain, shortCode)
+}
+
+type incrAllocator struct {
+	redis redis.Redis
+	key   string
+}
+
+func (a *incrAllocator) Next(ctx context.Context) (int64, error) {
+	return a.redis.Incr(ctx, a.key)
+}
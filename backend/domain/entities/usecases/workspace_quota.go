@@ -0,0 +1,184 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"lnk/domain/entities"
+	"lnk/extensions/logger"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrWorkspaceRequired    = entities.ErrForbidden.WithMessage("links must be created in a workspace")
+	ErrDomainNotInWorkspace = entities.ErrForbidden.WithMessage("domain is not assigned to your workspace")
+	ErrLinkQuotaExceeded    = entities.ErrQuotaExceeded.WithMessage("monthly link quota of the workspace is used up")
+	ErrUsageUnavailable     = entities.ErrUnavailable.WithMessage("usage metering is temporarily unavailable")
+)
+
+// UsageMeter counts the links each workspace creates per period. Its counters
+// may be lost, so a period without one reports ok false until it is seeded
+// from the durable usage.
+type UsageMeter interface {
+	// Reserve counts amount links unless the total would exceed limit, where
+	// 0 means unlimited, and reports whether they were counted.
+	Reserve(ctx context.Context, workspaceID, period string, amount, limit int64) (reserved, ok bool, err error)
+	// Release returns reserved links that were not created.
+	Release(ctx context.Context, workspaceID, period string, amount int64) error
+	// Seed creates the counter of the period with used links unless it
+	// exists.
+	Seed(ctx context.Context, workspaceID, period string, used int64) error
+	Used(ctx context.Context, workspaceID, period string) (used int64, ok bool, err error)
+}
+
+// WorkspacePolicy controls how link creation is tied to workspaces. When
+// Enforced, every link is created in the caller's workspace, which is the
+// identity's OwnerID: the caller must be an editor or owner of it, custom
+// domains must be assigned to it, and its monthly link quota is metered with
+// Usage.
+type WorkspacePolicy struct {
+	Usage UsageMeter
+	// DefaultQuota is given to new workspaces.
+	DefaultQuota entities.WorkspaceQuota
+	Enforced     bool
+}
+
+// linkAdmission is quota reserved for links that are about to be created. A
+// nil admission means nothing was reserved.
+type linkAdmission struct {
+	uc          *UseCase
+	workspaceID string
+	period      string
+}
+
+// commit records amount created links in the durable usage of the workspace.
+// The Redis meter enforces the quota but expires; this is the lasting record.
+func (a *linkAdmission) commit(ctx context.Context, amount int) {
+	if a == nil || amount == 0 {
+		return
+	}
+
+	err := a.uc.repository.RecordWorkspaceUsage(context.WithoutCancel(ctx), a.workspaceID, a.period, int64(amount))
+	if err != nil {
+		logger.FromContext(ctx, a.uc.logger).Warn("Failed to record workspace usage",
+			zap.String("workspace_id", a.workspaceID),
+			zap.Int("amount", amount),
+			zap.Error(err),
+		)
+	}
+}
+
+// release returns the quota of amount links that were not created.
+func (a *linkAdmission) release(ctx context.Context, amount int) {
+	if a == nil || amount == 0 {
+		return
+	}
+
+	err := a.uc.workspaces.Usage.Release(context.WithoutCancel(ctx), a.workspaceID, a.period, int64(amount))
+	if err != nil {
+		logger.FromContext(ctx, a.uc.logger).Warn("Failed to release workspace usage",
+			zap.String("workspace_id", a.workspaceID),
+			zap.Int("amount", amount),
+			zap.Error(err),
+		)
+	}
+}
+
+// AuthorizeLinks checks that the caller may create links on domain, without
+// reserving quota. It lets callers reject a whole batch up front.
+func (uc *UseCase) AuthorizeLinks(ctx context.Context, domain string) error {
+	_, err := uc.authorizeLinks(ctx, domain)
+
+	return err
+}
+
+// authorizeLinks returns the caller's workspace when the caller may create
+// links on domain, or nil when workspaces are not enforced.
+func (uc *UseCase) authorizeLinks(ctx context.Context, domain string) (*entities.Workspace, error) {
+	if !uc.workspaces.Enforced {
+		return nil, nil
+	}
+
+	workspaceID := entities.IdentityFromContext(ctx).OwnerID
+	if workspaceID == "" {
+		return nil, ErrWorkspaceRequired
+	}
+
+	workspace, _, err := uc.authorizeWorkspace(ctx, workspaceID, entities.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	if domain == uc.domains.Default() {
+		return workspace, nil
+	}
+
+	holder, err := uc.repository.GetDomainWorkspace(ctx, domain)
+
+	switch {
+	case errors.Is(err, gocql.ErrNotFound):
+		return nil, ErrDomainNotInWorkspace
+	case err != nil:
+		return nil, ErrWorkspaceUnavailable.Wrap(err)
+	case holder != workspace.ID:
+		return nil, ErrDomainNotInWorkspace
+	}
+
+	return workspace, nil
+}
+
+// admitLinks authorizes the caller to create amount links on domain and
+// reserves them against the monthly quota of the caller's workspace.
+func (uc *UseCase) admitLinks(ctx context.Context, domain string, amount int) (*linkAdmission, error) {
+	workspace, err := uc.authorizeLinks(ctx, domain)
+	if err != nil || workspace == nil || uc.workspaces.Usage == nil {
+		return nil, err
+	}
+
+	period := usagePeriod(time.Now())
+
+	reserved, ok, err := uc.workspaces.Usage.Reserve(ctx, workspace.ID, period, int64(amount), workspace.Quota.LinksPerMonth)
+	if err == nil && !ok {
+		if _, err = uc.seedUsage(ctx, workspace.ID, period); err == nil {
+			reserved, ok, err = uc.workspaces.Usage.Reserve(ctx, workspace.ID, period, int64(amount), workspace.Quota.LinksPerMonth)
+		}
+	}
+
+	if err != nil {
+		return nil, ErrUsageUnavailable.Wrap(err)
+	}
+
+	if !ok {
+		return nil, ErrUsageUnavailable
+	}
+
+	if !reserved {
+		return nil, ErrLinkQuotaExceeded
+	}
+
+	return &linkAdmission{uc: uc, workspaceID: workspace.ID, period: period}, nil
+}
+
+// seedUsage creates the usage counter of the workspace in period from the
+// durable usage, which outlives the meter's counters, and returns it.
+func (uc *UseCase) seedUsage(ctx context.Context, workspaceID, period string) (int64, error) {
+	used, err := uc.repository.GetWorkspaceUsage(ctx, workspaceID, period)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := uc.workspaces.Usage.Seed(ctx, workspaceID, period, used); err != nil {
+		return 0, err
+	}
+
+	logger.FromContext(ctx, uc.logger).Info("Seeded workspace usage from the durable record",
+		zap.String("workspace_id", workspaceID),
+		zap.String("period", period),
+		zap.Int64("links", used),
+	)
+
+	return used, nil
+}
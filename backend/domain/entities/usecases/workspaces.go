@@ -0,0 +1,450 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"time"
+
+	"lnk/domain/entities"
+	"lnk/extensions/logger"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrWorkspaceNotFound       = entities.ErrNotFound.WithMessage("workspace not found")
+	ErrWorkspaceExists         = entities.ErrConflict.WithMessage("workspace already exists")
+	ErrWorkspaceIDTaken        = entities.ErrConflict.WithMessage("workspace ID already owns links")
+	ErrWorkspaceUnavailable    = entities.ErrUnavailable.WithMessage("workspace storage is temporarily unavailable")
+	ErrInvalidWorkspaceID      = entities.ErrValidation.WithMessage("workspace ID must be 1 to 64 lowercase letters, digits or hyphens")
+	ErrInvalidRole             = entities.ErrValidation.WithMessage("role must be owner, editor or viewer")
+	ErrUserRequired            = entities.ErrForbidden.WithMessage("request does not identify a user")
+	ErrRoleForbidden           = entities.ErrForbidden.WithMessage("your workspace role does not allow this operation")
+	ErrOwnMembership           = entities.ErrForbidden.WithMessage("owners cannot change their own membership")
+	ErrMemberNotFound          = entities.ErrNotFound.WithMessage("workspace member not found")
+	ErrDefaultDomain           = entities.ErrValidation.WithMessage("the default domain is shared and cannot be assigned")
+	ErrDomainTaken             = entities.ErrConflict.WithMessage("domain is assigned to another workspace")
+	ErrWorkspaceDomainNotFound = entities.ErrNotFound.WithMessage("domain is not assigned to the workspace")
+	ErrDomainQuotaExceeded     = entities.ErrQuotaExceeded.WithMessage("custom domain quota of the workspace is used up")
+	ErrMembershipChanged       = entities.ErrConflict.WithMessage("membership was changed concurrently; retry the request")
+)
+
+var workspaceIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,63}$`)
+
+// WorkspaceSummary is a workspace with its domains, its usage in the current
+// period and the role of the caller.
+type WorkspaceSummary struct {
+	Workspace *entities.Workspace
+	Role      entities.Role
+	Domains   []string
+	Usage     entities.WorkspaceUsage
+}
+
+// CreateWorkspace creates a workspace with the default quota and makes the
+// calling user its owner. Workspace IDs are the OwnerIDs the gateway sets, so
+// an ID that already owns links is refused rather than handed to the caller.
+func (uc *UseCase) CreateWorkspace(ctx context.Context, id, name string) (*entities.Workspace, error) {
+	userID := entities.IdentityFromContext(ctx).UserID
+	if userID == "" {
+		return nil, ErrUserRequired
+	}
+
+	if !workspaceIDPattern.MatchString(id) {
+		return nil, ErrInvalidWorkspaceID.WithFields(entities.FieldError{Field: "id", Message: ErrInvalidWorkspaceID.Message})
+	}
+
+	existing, err := uc.repository.GetWorkspace(ctx, id)
+
+	switch {
+	case errors.Is(err, gocql.ErrNotFound):
+	case err != nil:
+		return nil, ErrWorkspaceUnavailable.Wrap(err)
+	default:
+		return uc.resumeWorkspace(ctx, existing, userID)
+	}
+
+	owned, err := uc.repository.OwnerHasLinks(ctx, id)
+	if err != nil {
+		return nil, ErrWorkspaceUnavailable.Wrap(err)
+	}
+
+	if owned {
+		return nil, ErrWorkspaceIDTaken
+	}
+
+	workspace := &entities.Workspace{
+		ID:        id,
+		Name:      name,
+		CreatedBy: userID,
+		Quota:     uc.workspaces.DefaultQuota,
+	}
+
+	created, err := uc.repository.CreateWorkspace(ctx, workspace)
+	if err != nil {
+		return nil, ErrWorkspaceUnavailable.Wrap(err)
+	}
+
+	if !created {
+		// A concurrent request created it first.
+		existing, err := uc.repository.GetWorkspace(ctx, id)
+		if err != nil {
+			return nil, ErrWorkspaceUnavailable.Wrap(err)
+		}
+
+		return uc.resumeWorkspace(ctx, existing, userID)
+	}
+
+	owner := &entities.WorkspaceMember{WorkspaceID: id, UserID: userID, Role: entities.RoleOwner}
+	if _, err := uc.repository.AddWorkspaceMember(ctx, owner); err != nil {
+		return nil, ErrWorkspaceUnavailable.Wrap(err)
+	}
+
+	logger.FromContext(ctx, uc.logger).Info("Workspace created", zap.String("workspace_id", id))
+
+	return workspace, nil
+}
+
+// resumeWorkspace answers a create request for a workspace that exists. Its
+// creator may complete a creation that stopped before the owner was recorded,
+// which is the case while the workspace has no members, and repeating a
+// completed creation returns the workspace to its owner. Once the workspace
+// has members, a creator who was demoted or removed cannot regain it.
+func (uc *UseCase) resumeWorkspace(ctx context.Context, workspace *entities.Workspace, userID string) (*entities.Workspace, error) {
+	if workspace.CreatedBy != userID {
+		return nil, ErrWorkspaceExists
+	}
+
+	members, err := uc.repository.ListWorkspaceMembers(ctx, workspace.ID)
+	if err != nil {
+		return nil, ErrWorkspaceUnavailable.Wrap(err)
+	}
+
+	if len(members) > 0 {
+		for _, member := range members {
+			if member.UserID == userID && member.Role == entities.RoleOwner {
+				return workspace, nil
+			}
+		}
+
+		return nil, ErrWorkspaceExists
+	}
+
+	owner := &entities.WorkspaceMember{WorkspaceID: workspace.ID, UserID: userID, Role: entities.RoleOwner}
+
+	added, err := uc.repository.AddWorkspaceMember(ctx, owner)
+	if err != nil {
+		return nil, ErrWorkspaceUnavailable.Wrap(err)
+	}
+
+	if !added {
+		return nil, ErrWorkspaceExists
+	}
+
+	logger.FromContext(ctx, uc.logger).Info("Workspace created", zap.String("workspace_id", workspace.ID))
+
+	return workspace, nil
+}
+
+// GetWorkspace returns the workspace to any of its members.
+func (uc *UseCase) GetWorkspace(ctx context.Context, id string) (*WorkspaceSummary, error) {
+	workspace, member, err := uc.authorizeWorkspace(ctx, id, entities.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	domains, err := uc.repository.ListWorkspaceDomains(ctx, id)
+	if err != nil {
+		return nil, ErrWorkspaceUnavailable.Wrap(err)
+	}
+
+	period := usagePeriod(time.Now())
+
+	var (
+		links int64
+		ok    bool
+	)
+
+	if uc.workspaces.Usage != nil {
+		links, ok, err = uc.workspaces.Usage.Used(ctx, id, period)
+		if err == nil && !ok {
+			links, err = uc.seedUsage(ctx, id, period)
+		}
+
+		if err != nil {
+			return nil, ErrUsageUnavailable.Wrap(err)
+		}
+	}
+
+	return &WorkspaceSummary{
+		Workspace: workspace,
+		Role:      member.Role,
+		Domains:   domains,
+		Usage:     entities.WorkspaceUsage{Period: period, Links: links, Domains: len(domains)},
+	}, nil
+}
+
+// ListWorkspaceMembers returns the members of the workspace to any of its
+// members.
+func (uc *UseCase) ListWorkspaceMembers(ctx context.Context, id string) ([]entities.WorkspaceMember, error) {
+	if _, _, err := uc.authorizeWorkspace(ctx, id, entities.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	members, err := uc.repository.ListWorkspaceMembers(ctx, id)
+	if err != nil {
+		return nil, ErrWorkspaceUnavailable.Wrap(err)
+	}
+
+	return members, nil
+}
+
+// SetWorkspaceMember adds a user to the workspace or changes their role. Only
+// owners manage members, and they cannot change their own role, so a
+// workspace always keeps an owner.
+func (uc *UseCase) SetWorkspaceMember(ctx context.Context, id, userID string, role entities.Role) (*entities.WorkspaceMember, error) {
+	if !role.Valid() {
+		return nil, ErrInvalidRole.WithFields(entities.FieldError{Field: "role", Message: ErrInvalidRole.Message})
+	}
+
+	_, caller, err := uc.authorizeWorkspace(ctx, id, entities.RoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	if userID == caller.UserID {
+		return nil, ErrOwnMembership
+	}
+
+	member, err := uc.repository.GetWorkspaceMember(ctx, id, userID)
+
+	var written bool
+
+	switch {
+	case errors.Is(err, gocql.ErrNotFound):
+		member = &entities.WorkspaceMember{WorkspaceID: id, UserID: userID, Role: role}
+		written, err = uc.repository.AddWorkspaceMember(ctx, member)
+	case err != nil:
+		return nil, ErrWorkspaceUnavailable.Wrap(err)
+	default:
+		member.Role = role
+		written, err = uc.repository.SetWorkspaceMemberRole(ctx, member)
+	}
+
+	if err != nil {
+		return nil, ErrWorkspaceUnavailable.Wrap(err)
+	}
+
+	// Another request added or removed the member between the read and the
+	// write.
+	if !written {
+		return nil, ErrMembershipChanged
+	}
+
+	return member, nil
+}
+
+// RemoveWorkspaceMember removes a user from the workspace. Only owners manage
+// members, and they cannot remove themselves.
+func (uc *UseCase) RemoveWorkspaceMember(ctx context.Context, id, userID string) error {
+	_, caller, err := uc.authorizeWorkspace(ctx, id, entities.RoleOwner)
+	if err != nil {
+		return err
+	}
+
+	if userID == caller.UserID {
+		return ErrOwnMembership
+	}
+
+	_, err = uc.repository.GetWorkspaceMember(ctx, id, userID)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return ErrMemberNotFound
+	}
+
+	if err != nil {
+		return ErrWorkspaceUnavailable.Wrap(err)
+	}
+
+	if err := uc.repository.DeleteWorkspaceMember(ctx, id, userID); err != nil {
+		return ErrWorkspaceUnavailable.Wrap(err)
+	}
+
+	return nil
+}
+
+// AddWorkspaceDomain assigns a configured custom domain to the workspace
+// within its domain quota. A domain belongs to at most one workspace. The
+// quota is reserved on the workspace's domain count before the domain is
+// claimed, so concurrent requests cannot exceed it.
+func (uc *UseCase) AddWorkspaceDomain(ctx context.Context, id, domain string) (string, error) {
+	domain, err := uc.customDomain(domain)
+	if err != nil {
+		return "", err
+	}
+
+	workspace, _, err := uc.authorizeWorkspace(ctx, id, entities.RoleOwner)
+	if err != nil {
+		return "", err
+	}
+
+	domains, err := uc.repository.ListWorkspaceDomains(ctx, id)
+	if err != nil {
+		return "", ErrWorkspaceUnavailable.Wrap(err)
+	}
+
+	for _, held := range domains {
+		if held == domain {
+			return domain, nil
+		}
+	}
+
+	reserved, err := uc.repository.AdjustWorkspaceDomainCount(ctx, id, 1, workspace.Quota.Domains, len(domains))
+	if err != nil {
+		return "", ErrWorkspaceUnavailable.Wrap(err)
+	}
+
+	if !reserved {
+		return "", ErrDomainQuotaExceeded
+	}
+
+	claimed, err := uc.repository.ClaimWorkspaceDomain(ctx, id, domain)
+	if err != nil || !claimed {
+		uc.releaseDomainSlot(ctx, id, len(domains)+1)
+	}
+
+	if err != nil {
+		return "", ErrWorkspaceUnavailable.Wrap(err)
+	}
+
+	if !claimed {
+		return "", ErrDomainTaken
+	}
+
+	return domain, nil
+}
+
+// RemoveWorkspaceDomain unassigns a custom domain from the workspace. Links
+// already created on it keep working.
+func (uc *UseCase) RemoveWorkspaceDomain(ctx context.Context, id, domain string) error {
+	domain, err := uc.customDomain(domain)
+	if err != nil {
+		return err
+	}
+
+	if _, _, err := uc.authorizeWorkspace(ctx, id, entities.RoleOwner); err != nil {
+		return err
+	}
+
+	holder, err := uc.repository.GetDomainWorkspace(ctx, domain)
+
+	switch {
+	case errors.Is(err, gocql.ErrNotFound):
+		return ErrWorkspaceDomainNotFound
+	case err != nil:
+		return ErrWorkspaceUnavailable.Wrap(err)
+	case holder != id:
+		return ErrWorkspaceDomainNotFound
+	}
+
+	domains, err := uc.repository.ListWorkspaceDomains(ctx, id)
+	if err != nil {
+		return ErrWorkspaceUnavailable.Wrap(err)
+	}
+
+	if err := uc.repository.ReleaseWorkspaceDomain(ctx, id, domain); err != nil {
+		return ErrWorkspaceUnavailable.Wrap(err)
+	}
+
+	uc.releaseDomainSlot(ctx, id, len(domains))
+
+	return nil
+}
+
+// releaseDomainSlot returns a custom domain to the quota of the workspace,
+// which held that many domains before.
+func (uc *UseCase) releaseDomainSlot(ctx context.Context, id string, held int) {
+	if _, err := uc.repository.AdjustWorkspaceDomainCount(context.WithoutCancel(ctx), id, -1, 0, held); err != nil {
+		logger.FromContext(ctx, uc.logger).Warn("Failed to release workspace domain slot",
+			zap.String("workspace_id", id),
+			zap.Error(err),
+		)
+	}
+}
+
+// SetWorkspaceQuota replaces the quota of a workspace. It is an operator
+// action and does not check the caller.
+func (uc *UseCase) SetWorkspaceQuota(ctx context.Context, id string, quota entities.WorkspaceQuota) (*entities.Workspace, error) {
+	updated, err := uc.repository.SetWorkspaceQuota(ctx, id, quota)
+	if err != nil {
+		return nil, ErrWorkspaceUnavailable.Wrap(err)
+	}
+
+	if !updated {
+		return nil, ErrWorkspaceNotFound
+	}
+
+	workspace, err := uc.repository.GetWorkspace(ctx, id)
+	if err != nil {
+		return nil, ErrWorkspaceUnavailable.Wrap(err)
+	}
+
+	return workspace, nil
+}
+
+// authorizeWorkspace returns the workspace and the calling member when the
+// caller's role allows required. Workspaces the caller is not a member of are
+// reported as not found, so their existence is not revealed.
+func (uc *UseCase) authorizeWorkspace(ctx context.Context, id string, required entities.Role) (*entities.Workspace, *entities.WorkspaceMember, error) {
+	userID := entities.IdentityFromContext(ctx).UserID
+	if userID == "" {
+		return nil, nil, ErrUserRequired
+	}
+
+	member, err := uc.repository.GetWorkspaceMember(ctx, id, userID)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return nil, nil, ErrWorkspaceNotFound
+	}
+
+	if err != nil {
+		return nil, nil, ErrWorkspaceUnavailable.Wrap(err)
+	}
+
+	if !member.Role.Allows(required) {
+		return nil, nil, ErrRoleForbidden
+	}
+
+	workspace, err := uc.repository.GetWorkspace(ctx, id)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return nil, nil, ErrWorkspaceNotFound
+	}
+
+	if err != nil {
+		return nil, nil, ErrWorkspaceUnavailable.Wrap(err)
+	}
+
+	return workspace, member, nil
+}
+
+// customDomain validates a domain that can be assigned to a workspace.
+func (uc *UseCase) customDomain(domain string) (string, error) {
+	if domain == "" {
+		return "", ErrUnknownDomain
+	}
+
+	domain, err := uc.domains.Validate(domain)
+	if err != nil {
+		return "", err
+	}
+
+	if domain == uc.domains.Default() {
+		return "", ErrDefaultDomain
+	}
+
+	return domain, nil
+}
+
+// usagePeriod is the calendar month in UTC that usage at t is counted in.
+func usagePeriod(t time.Time) string {
+	return t.UTC().Format("2006-01")
+}
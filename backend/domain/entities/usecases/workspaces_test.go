@@ -0,0 +1,266 @@
+package usecases_test
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+
+	"lnk/domain/entities"
+	"lnk/domain/entities/usecases"
+	"lnk/extensions/gocqltesting"
+	"lnk/extensions/redis/mocks"
+	"lnk/gateways/gocql/repositories"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type memoryUsageMeter struct {
+	used map[string]int64
+	mu   sync.Mutex
+}
+
+func (m *memoryUsageMeter) Reserve(_ context.Context, workspaceID, period string, amount, limit int64) (reserved, ok bool, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := workspaceID + ":" + period
+
+	used, ok := m.used[key]
+	if !ok {
+		return false, false, nil
+	}
+
+	if limit > 0 && used+amount > limit {
+		return false, true, nil
+	}
+
+	m.used[key] += amount
+
+	return true, true, nil
+}
+
+func (m *memoryUsageMeter) Release(_ context.Context, workspaceID, period string, amount int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.used[workspaceID+":"+period] -= amount
+
+	return nil
+}
+
+func (m *memoryUsageMeter) Seed(_ context.Context, workspaceID, period string, used int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.used[workspaceID+":"+period]; !ok {
+		m.used[workspaceID+":"+period] = used
+	}
+
+	return nil
+}
+
+func (m *memoryUsageMeter) Used(_ context.Context, workspaceID, period string) (used int64, ok bool, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	used, ok = m.used[workspaceID+":"+period]
+
+	return used, ok, nil
+}
+
+// forget drops every counter, as a Redis restart without persistence would.
+func (m *memoryUsageMeter) forget() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	clear(m.used)
+}
+
+func as(workspaceID, userID string) context.Context {
+	return entities.WithIdentity(context.Background(), entities.Identity{OwnerID: workspaceID, UserID: userID})
+}
+
+func Test_UseCase_Workspaces(t *testing.T) {
+	t.Parallel()
+
+	session, err := gocqltesting.NewDB(t, t.Name())
+	require.NoError(t, err)
+
+	logger := zap.NewNop()
+	usage := &memoryUsageMeter{used: map[string]int64{}}
+
+	mockRedis := mocks.NewMockRedis(t)
+	mockRedis.On("Incr", mock.Anything, mock.Anything).Return(int64(1), nil).Once()
+	mockRedis.On("Incr", mock.Anything, mock.Anything).Return(int64(2), nil).Once()
+
+	domains, err := usecases.NewDomains("https://lnk.example", []string{"go.acme.example", "a.globex.example", "b.globex.example"})
+	require.NoError(t, err)
+
+	useCase := usecases.NewUseCase(usecases.NewUseCaseParams{
+		Logger:     logger,
+		Repository: repositories.NewRepository(logger, session),
+		Redis:      mockRedis,
+		Domains:    domains,
+		Workspaces: usecases.WorkspacePolicy{
+			Enforced:     true,
+			Usage:        usage,
+			DefaultQuota: entities.WorkspaceQuota{LinksPerMonth: 2, Domains: 1},
+		},
+		Salt:       "test",
+		CounterKey: "test",
+	})
+
+	_, err = useCase.CreateWorkspace(as("", "alice"), "acme", "Acme")
+	require.NoError(t, err)
+
+	_, err = useCase.CreateWorkspace(as("", "bob"), "acme", "Not Acme")
+	require.ErrorIs(t, err, usecases.ErrWorkspaceExists)
+
+	_, err = useCase.CreateWorkspace(as("", "bob"), "globex", "Globex")
+	require.NoError(t, err)
+
+	_, err = useCase.SetWorkspaceMember(as("", "alice"), "acme", "carol", entities.RoleViewer)
+	require.NoError(t, err)
+
+	t.Run("creators cannot regain a workspace", func(t *testing.T) {
+		again, err := useCase.CreateWorkspace(as("", "alice"), "acme", "Acme")
+		require.NoError(t, err, "repeating a completed creation returns the workspace")
+		require.Equal(t, "acme", again.ID)
+
+		_, err = useCase.CreateWorkspace(as("", "erin"), "initech", "Initech")
+		require.NoError(t, err)
+
+		_, err = useCase.SetWorkspaceMember(as("", "erin"), "initech", "frank", entities.RoleOwner)
+		require.NoError(t, err)
+
+		_, err = useCase.SetWorkspaceMember(as("", "frank"), "initech", "erin", entities.RoleViewer)
+		require.NoError(t, err)
+
+		_, err = useCase.CreateWorkspace(as("", "erin"), "initech", "Initech")
+		require.ErrorIs(t, err, usecases.ErrWorkspaceExists)
+
+		_, err = useCase.SetWorkspaceMember(as("", "erin"), "initech", "grace", entities.RoleEditor)
+		require.ErrorIs(t, err, usecases.ErrRoleForbidden)
+	})
+
+	t.Run("IDs that own links cannot become workspaces", func(t *testing.T) {
+		unenforced := usecases.NewUseCase(usecases.NewUseCaseParams{
+			Logger:      logger,
+			Repository:  repositories.NewRepository(logger, session),
+			IDAllocator: &sequenceAllocator{},
+			Domains:     domains,
+			Salt:        "other",
+		})
+
+		_, err := unenforced.CreateShortURL(as("hooli", ""), "", "https://hooli.example")
+		require.NoError(t, err)
+
+		_, err = useCase.CreateWorkspace(as("", "mallory"), "hooli", "Hooli")
+		require.ErrorIs(t, err, usecases.ErrWorkspaceIDTaken)
+	})
+
+	t.Run("members of other workspaces cannot see the workspace", func(t *testing.T) {
+		_, err := useCase.GetWorkspace(as("", "bob"), "acme")
+		require.ErrorIs(t, err, usecases.ErrWorkspaceNotFound)
+	})
+
+	t.Run("only owners manage members", func(t *testing.T) {
+		_, err := useCase.SetWorkspaceMember(as("", "carol"), "acme", "dave", entities.RoleEditor)
+		require.ErrorIs(t, err, usecases.ErrRoleForbidden)
+
+		err = useCase.RemoveWorkspaceMember(as("", "alice"), "acme", "alice")
+		require.ErrorIs(t, err, usecases.ErrOwnMembership)
+	})
+
+	t.Run("custom domains belong to one workspace", func(t *testing.T) {
+		_, err := useCase.AddWorkspaceDomain(as("", "alice"), "acme", "go.acme.example")
+		require.NoError(t, err)
+
+		_, err = useCase.AddWorkspaceDomain(as("", "bob"), "globex", "go.acme.example")
+		require.ErrorIs(t, err, usecases.ErrDomainTaken)
+
+		_, err = useCase.CreateShortURL(as("globex", "bob"), "go.acme.example", "https://example.com")
+		require.ErrorIs(t, err, usecases.ErrDomainNotInWorkspace)
+	})
+
+	t.Run("concurrent domain additions stay within the quota", func(t *testing.T) {
+		var wg sync.WaitGroup
+
+		errs := make([]error, 2)
+
+		for i, domain := range []string{"a.globex.example", "b.globex.example"} {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				_, errs[i] = useCase.AddWorkspaceDomain(as("", "bob"), "globex", domain)
+			}()
+		}
+
+		wg.Wait()
+
+		require.Len(t, slices.DeleteFunc(slices.Clone(errs), func(err error) bool { return err != nil }), 1)
+		require.Contains(t, errs, error(usecases.ErrDomainQuotaExceeded))
+
+		summary, err := useCase.GetWorkspace(as("", "bob"), "globex")
+		require.NoError(t, err)
+		require.Len(t, summary.Domains, 1)
+
+		require.NoError(t, useCase.RemoveWorkspaceDomain(as("", "bob"), "globex", summary.Domains[0]))
+
+		_, err = useCase.AddWorkspaceDomain(as("", "bob"), "globex", "a.globex.example")
+		require.NoError(t, err, "removing a domain frees its slot")
+	})
+
+	t.Run("link creation needs an editor", func(t *testing.T) {
+		_, err := useCase.CreateShortURL(as("", "alice"), "", "https://example.com")
+		require.ErrorIs(t, err, usecases.ErrWorkspaceRequired)
+
+		_, err = useCase.CreateShortURL(as("acme", "carol"), "", "https://example.com")
+		require.ErrorIs(t, err, usecases.ErrRoleForbidden)
+	})
+
+	t.Run("links are metered against the monthly quota", func(t *testing.T) {
+		ctx := as("acme", "alice")
+
+		_, err := useCase.CreateShortURL(ctx, "go.acme.example", "https://example.com/1")
+		require.NoError(t, err)
+
+		_, err = useCase.CreateShortURL(ctx, "", "https://example.com/2")
+		require.NoError(t, err)
+
+		_, err = useCase.CreateShortURL(ctx, "", "https://example.com/3")
+		require.ErrorIs(t, err, usecases.ErrLinkQuotaExceeded)
+
+		summary, err := useCase.GetWorkspace(ctx, "acme")
+		require.NoError(t, err)
+		require.Equal(t, int64(2), summary.Usage.Links)
+		require.Equal(t, []string{"go.acme.example"}, summary.Domains)
+
+		var recorded int64
+
+		err = session.Query("SELECT links FROM workspace_usage WHERE workspace_id = ? AND period = ?", "acme", summary.Usage.Period).
+			ScanContext(context.Background(), &recorded)
+		require.NoError(t, err)
+		require.Equal(t, int64(2), recorded, "usage is recorded durably")
+	})
+
+	t.Run("lost usage counters are seeded from the durable usage", func(t *testing.T) {
+		ctx := as("acme", "alice")
+
+		usage.forget()
+
+		summary, err := useCase.GetWorkspace(ctx, "acme")
+		require.NoError(t, err)
+		require.Equal(t, int64(2), summary.Usage.Links)
+
+		usage.forget()
+
+		_, err = useCase.CreateShortURL(ctx, "", "https://example.com/4")
+		require.ErrorIs(t, err, usecases.ErrLinkQuotaExceeded)
+	})
+}
@@ -0,0 +1,66 @@
+package entities
+
+import "time"
+
+// Role is a member's permission level in a workspace. Each role includes the
+// permissions of the roles below it.
+type Role string
+
+const (
+	RoleViewer Role = "viewer"
+	RoleEditor Role = "editor"
+	RoleOwner  Role = "owner"
+)
+
+var roleRanks = map[Role]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleOwner:  3,
+}
+
+func (r Role) Valid() bool {
+	_, ok := roleRanks[r]
+
+	return ok
+}
+
+// Allows reports whether r grants the permissions of required.
+func (r Role) Allows(required Role) bool {
+	return r.Valid() && roleRanks[r] >= roleRanks[required]
+}
+
+// WorkspaceQuota limits what a workspace may use. Zero means unlimited.
+type WorkspaceQuota struct {
+	// LinksPerMonth bounds the links created per calendar month in UTC.
+	LinksPerMonth int64
+	// Domains bounds the custom short domains assigned to the workspace. The
+	// default domain is shared and never counted.
+	Domains int
+}
+
+// Workspace is a tenant. Its ID is the OwnerID of the links, bulk jobs and
+// domains that belong to it.
+type Workspace struct {
+	CreatedAt time.Time
+	ID        string
+	Name      string
+	// CreatedBy is the user that created the workspace and became its first
+	// owner.
+	CreatedBy string
+	Quota     WorkspaceQuota
+}
+
+type WorkspaceMember struct {
+	CreatedAt   time.Time
+	WorkspaceID string
+	UserID      string
+	Role        Role
+}
+
+// WorkspaceUsage is what a workspace has used in the current period.
+type WorkspaceUsage struct {
+	// Period is the metered month, formatted as 2006-01.
+	Period  string
+	Links   int64
+	Domains int
+}
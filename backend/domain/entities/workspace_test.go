@@ -0,0 +1,35 @@
+package entities_test
+
+import (
+	"testing"
+
+	"lnk/domain/entities"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Role_Allows(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		role     entities.Role
+		required entities.Role
+		allowed  bool
+	}{
+		{role: entities.RoleOwner, required: entities.RoleOwner, allowed: true},
+		{role: entities.RoleOwner, required: entities.RoleViewer, allowed: true},
+		{role: entities.RoleEditor, required: entities.RoleEditor, allowed: true},
+		{role: entities.RoleEditor, required: entities.RoleOwner, allowed: false},
+		{role: entities.RoleViewer, required: entities.RoleEditor, allowed: false},
+		{role: entities.Role("admin"), required: entities.RoleViewer, allowed: false},
+		{role: entities.Role(""), required: entities.RoleViewer, allowed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.role)+"/"+string(tt.required), func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.allowed, tt.role.Allows(tt.required))
+		})
+	}
+}
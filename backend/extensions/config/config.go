@@ -1,11 +1,16 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"slices"
+	"time"
 
 	"lnk/extensions/logger"
 	"lnk/extensions/opentelemetry"
+	"lnk/extensions/pagemeta"
 	"lnk/extensions/redis"
+	"lnk/extensions/threatlist"
 	"lnk/gateways/gocql"
 
 	"github.com/joho/godotenv"
@@ -13,18 +18,41 @@ import (
 )
 
 type Config struct {
-	App    App
-	OTel   opentelemetry.Config
-	Logger logger.Config
-	Gocql  gocql.Config
-	Redis  redis.Config
+	App      App
+	OTel     opentelemetry.Config
+	PageMeta pagemeta.Config
+	Threats  threatlist.Config
+	Logger   logger.Config
+	Gocql    gocql.Config
+	Redis    redis.Config
 }
 
 type App struct {
-	ENV        string `envconfig:"ENV" default:"development"`
-	Port       string `envconfig:"PORT" default:"8080"`
-	GinMode    string `envconfig:"GIN_MODE" default:"debug"`
-	Base62Salt string `envconfig:"BASE62_SALT" required:"true"`
+	ENV                string        `envconfig:"ENV" default:"development"`
+	Port               string        `envconfig:"PORT" default:"8080"`
+	GinMode            string        `envconfig:"GIN_MODE" default:"debug"`
+	Base62Salt         string        `envconfig:"BASE62_SALT" required:"true"`
+	AdminAddr          string        `envconfig:"ADMIN_ADDR" default:"127.0.0.1:9090"`
+	PublicBaseURL      string        `envconfig:"PUBLIC_BASE_URL" default:"http://localhost:8080"`
+	QRLogoPath         string        `envconfig:"QR_LOGO_PATH"`
+	InterstitialPath   string        `envconfig:"INTERSTITIAL_TEMPLATE_PATH"`
+	InterstitialSecret string        `envconfig:"INTERSTITIAL_SECRET"`
+	ShortDomains       []string      `envconfig:"SHORT_DOMAINS"`
+	DedupOwners        []string      `envconfig:"DEDUP_OWNERS"`
+	ShutdownTimeout    time.Duration `envconfig:"SHUTDOWN_TIMEOUT" default:"15s"`
+	ShutdownDrainDelay time.Duration `envconfig:"SHUTDOWN_DRAIN_DELAY" default:"3s"`
+	ReadinessTimeout   time.Duration `envconfig:"READINESS_TIMEOUT" default:"2s"`
+	ReadinessCacheTTL  time.Duration `envconfig:"READINESS_CACHE_TTL" default:"1s"`
+	BulkMaxItems       int           `envconfig:"BULK_MAX_ITEMS" default:"1000"`
+	BulkMaxAsyncItems  int           `envconfig:"BULK_MAX_ASYNC_ITEMS" default:"50000"`
+	BulkConcurrency    int           `envconfig:"BULK_CONCURRENCY" default:"16"`
+	// WorkspaceLinksPerMonth and WorkspaceMaxDomains are the quota of new
+	// workspaces; 0 is unlimited.
+	WorkspaceLinksPerMonth int64 `envconfig:"WORKSPACE_LINKS_PER_MONTH" default:"10000"`
+	WorkspaceMaxDomains    int   `envconfig:"WORKSPACE_MAX_DOMAINS" default:"1"`
+	AdminEnabled           bool  `envconfig:"ADMIN_ENABLED" default:"true"`
+	DedupEnabled           bool  `envconfig:"DEDUP_ENABLED" default:"false"`
+	WorkspacesEnforced     bool  `envconfig:"WORKSPACES_ENFORCED" default:"false"`
 }
 
 func LoadConfig() (*Config, error) {
@@ -42,5 +70,10 @@ func LoadConfig() (*Config, error) {
 		return nil, fmt.Errorf("failed to process gocql config: %w", err)
 	}
 
+	// The Prometheus exporter is only scraped on the admin listener.
+	if !config.App.AdminEnabled && slices.Contains(config.OTel.MetricsExporters, opentelemetry.ExporterPrometheus) {
+		return nil, errors.New("the prometheus metrics exporter requires ADMIN_ENABLED, which serves /metrics")
+	}
+
 	return config, nil
 }
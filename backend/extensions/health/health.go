@@ -0,0 +1,148 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusOK       = "ok"
+	StatusFailing  = "failing"
+	StatusDraining = "draining"
+)
+
+// CheckFunc reports whether a dependency is usable. It must honour ctx.
+type CheckFunc func(ctx context.Context) error
+
+// CheckResult is the outcome of a single readiness check.
+type CheckResult struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// Report is the readiness report served by /readyz.
+type Report struct {
+	CheckedAt time.Time              `json:"checked_at"`
+	Checks    map[string]CheckResult `json:"checks"`
+	Status    string                 `json:"status"`
+}
+
+// Ready reports whether every check passed and the server is not draining.
+func (r *Report) Ready() bool {
+	return r.Status == StatusOK
+}
+
+type namedCheck struct {
+	check CheckFunc
+	name  string
+}
+
+// Checker runs the registered readiness checks concurrently, each bounded by
+// a timeout, and caches the report so frequent probes do not hammer
+// dependencies.
+type Checker struct {
+	cachedAt time.Time
+	cached   *Report
+	checks   []namedCheck
+	timeout  time.Duration
+	cacheTTL time.Duration
+	mu       sync.Mutex
+	draining atomic.Bool
+}
+
+type NewCheckerParams struct {
+	Timeout  time.Duration
+	CacheTTL time.Duration
+}
+
+func NewChecker(params NewCheckerParams) *Checker {
+	return &Checker{
+		timeout:  params.Timeout,
+		cacheTTL: params.CacheTTL,
+	}
+}
+
+// Register adds a readiness check. It must be called before serving traffic.
+func (c *Checker) Register(name string, check CheckFunc) {
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// SetDraining marks the server as shutting down so readiness fails
+// immediately and load balancers stop sending new requests.
+func (c *Checker) SetDraining() {
+	c.draining.Store(true)
+}
+
+// Check returns the cached report or runs every check when it has expired.
+func (c *Checker) Check(ctx context.Context) *Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.draining.Load() {
+		return &Report{Status: StatusDraining, CheckedAt: time.Now().UTC(), Checks: map[string]CheckResult{}}
+	}
+
+	if c.cached != nil && time.Since(c.cachedAt) < c.cacheTTL {
+		return c.cached
+	}
+
+	// A cancelled probe must not poison the cached report for other callers.
+	c.cached = c.run(context.WithoutCancel(ctx))
+	c.cachedAt = time.Now()
+
+	return c.cached
+}
+
+func (c *Checker) run(ctx context.Context) *Report {
+	report := &Report{
+		Status:    StatusOK,
+		CheckedAt: time.Now().UTC(),
+		Checks:    make(map[string]CheckResult, len(c.checks)),
+	}
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+
+	for _, check := range c.checks {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			result := c.runOne(ctx, check)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			report.Checks[check.name] = result
+			if result.Status != StatusOK {
+				report.Status = StatusFailing
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return report
+}
+
+func (c *Checker) runOne(ctx context.Context, check namedCheck) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.check(ctx)
+	result := CheckResult{Status: StatusOK, Duration: time.Since(start).String()}
+
+	if err != nil {
+		result.Status = StatusFailing
+		result.Error = err.Error()
+	}
+
+	return result
+}
@@ -0,0 +1,66 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"lnk/extensions/health"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Checker_Check(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	checker := health.NewChecker(health.NewCheckerParams{Timeout: time.Second, CacheTTL: time.Minute})
+	checker.Register("ok", func(context.Context) error {
+		calls.Add(1)
+		return nil
+	})
+	checker.Register("broken", func(context.Context) error {
+		return errors.New("connection refused")
+	})
+
+	report := checker.Check(context.Background())
+	require.False(t, report.Ready())
+	require.Equal(t, health.StatusOK, report.Checks["ok"].Status)
+	require.Equal(t, health.StatusFailing, report.Checks["broken"].Status)
+	require.Equal(t, "connection refused", report.Checks["broken"].Error)
+
+	checker.Check(context.Background())
+	require.Equal(t, int32(1), calls.Load(), "report should be cached")
+}
+
+func Test_Checker_Timeout(t *testing.T) {
+	t.Parallel()
+
+	checker := health.NewChecker(health.NewCheckerParams{Timeout: 10 * time.Millisecond})
+	checker.Register("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	report := checker.Check(context.Background())
+	require.False(t, report.Ready())
+	require.Equal(t, health.StatusFailing, report.Checks["slow"].Status)
+}
+
+func Test_Checker_Draining(t *testing.T) {
+	t.Parallel()
+
+	checker := health.NewChecker(health.NewCheckerParams{Timeout: time.Second})
+	checker.Register("ok", func(context.Context) error { return nil })
+
+	require.True(t, checker.Check(context.Background()).Ready())
+
+	checker.SetDraining()
+
+	report := checker.Check(context.Background())
+	require.False(t, report.Ready())
+	require.Equal(t, health.StatusDraining, report.Status)
+}
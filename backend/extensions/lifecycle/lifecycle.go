@@ -0,0 +1,140 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Component is a unit managed by the Manager. Run, when set, blocks until the
+// component fails or its context is cancelled. Stop, when set, releases the
+// component's resources. Resource-only components such as database sessions
+// only set Stop.
+type Component struct {
+	Run  func(ctx context.Context) error
+	Stop func(ctx context.Context) error
+	Name string
+}
+
+type runningComponent struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+	Component
+}
+
+// Manager starts components in registration order and stops them in reverse
+// order within a single shutdown budget, so resources registered first, such
+// as telemetry, are released last.
+type Manager struct {
+	logger          *zap.Logger
+	errs            chan error
+	components      []*runningComponent
+	shutdownTimeout time.Duration
+	mu              sync.Mutex
+	stopped         bool
+}
+
+func NewManager(logger *zap.Logger, shutdownTimeout time.Duration) *Manager {
+	return &Manager{
+		logger:          logger,
+		shutdownTimeout: shutdownTimeout,
+		errs:            make(chan error, 1),
+	}
+}
+
+// Add registers a component and, if it has a Run function, starts it right
+// away. A Run error before shutdown makes Wait return with that error.
+func (m *Manager) Add(component Component) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	running := &runningComponent{Component: component, cancel: cancel, done: make(chan struct{})}
+	m.components = append(m.components, running)
+
+	if component.Run == nil {
+		close(running.done)
+		return
+	}
+
+	go func() {
+		defer close(running.done)
+
+		err := component.Run(ctx)
+		if err != nil && ctx.Err() == nil {
+			select {
+			case m.errs <- fmt.Errorf("%s failed: %w", component.Name, err):
+			default:
+			}
+		}
+	}()
+}
+
+// Wait blocks until ctx is cancelled or a component fails, then shuts
+// everything down. It returns the component failure, if any, joined with any
+// shutdown errors.
+func (m *Manager) Wait(ctx context.Context) error {
+	var runErr error
+
+	select {
+	case <-ctx.Done():
+		m.logger.Info("Received shutdown signal")
+	case runErr = <-m.errs:
+		m.logger.Error("Component failed, shutting down", zap.Error(runErr))
+	}
+
+	return errors.Join(runErr, m.Shutdown(context.WithoutCancel(ctx)))
+}
+
+// Shutdown stops every registered component in reverse order. The whole
+// sequence shares one shutdown budget; it is safe to call more than once.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.stopped {
+		return nil
+	}
+
+	m.stopped = true
+
+	ctx, cancel := context.WithTimeout(ctx, m.shutdownTimeout)
+	defer cancel()
+
+	var errs []error
+
+	for i := len(m.components) - 1; i >= 0; i-- {
+		if err := m.stop(ctx, m.components[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (m *Manager) stop(ctx context.Context, component *runningComponent) error {
+	m.logger.Info("Stopping component", zap.String("component", component.Name))
+
+	var err error
+	if component.Stop != nil {
+		err = component.Stop(ctx)
+	}
+
+	component.cancel()
+
+	select {
+	case <-component.done:
+	case <-ctx.Done():
+		return fmt.Errorf("%s did not stop in time: %w", component.Name, ctx.Err())
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to stop %s: %w", component.Name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package lifecycle_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"lnk/extensions/lifecycle"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func Test_Manager_ShutdownOrder(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu      sync.Mutex
+		stopped []string
+	)
+
+	record := func(name string) func(context.Context) error {
+		return func(context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+
+			stopped = append(stopped, name)
+
+			return nil
+		}
+	}
+
+	manager := lifecycle.NewManager(zap.NewNop(), time.Second)
+	manager.Add(lifecycle.Component{Name: "telemetry", Stop: record("telemetry")})
+	manager.Add(lifecycle.Component{Name: "database", Stop: record("database")})
+	manager.Add(lifecycle.Component{
+		Name: "worker",
+		Run: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Stop: record("worker"),
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	require.NoError(t, manager.Wait(ctx))
+	require.Equal(t, []string{"worker", "database", "telemetry"}, stopped)
+	require.NoError(t, manager.Shutdown(context.Background()), "second shutdown should be a no-op")
+}
+
+func Test_Manager_ComponentFailure(t *testing.T) {
+	t.Parallel()
+
+	errListen := errors.New("address already in use")
+
+	manager := lifecycle.NewManager(zap.NewNop(), time.Second)
+	manager.Add(lifecycle.Component{
+		Name: "http-server",
+		Run: func(context.Context) error {
+			return errListen
+		},
+	})
+
+	err := manager.Wait(context.Background())
+	require.ErrorIs(t, err, errListen)
+}
+
+func Test_Manager_ShutdownTimeout(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+
+	manager := lifecycle.NewManager(zap.NewNop(), 10*time.Millisecond)
+	manager.Add(lifecycle.Component{
+		Name: "stuck",
+		Run: func(context.Context) error {
+			<-release
+			return nil
+		},
+	})
+
+	err := manager.Shutdown(context.Background())
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -1,5 +1,20 @@
 package logger
 
+const (
+	FormatJSON    = "json"
+	FormatConsole = "console"
+)
+
 type Config struct {
 	Level string `envconfig:"LOG_LEVEL" default:"info"`
+	// Format is json or console. When empty, debug logs use the console
+	// encoder and every other level uses JSON.
+	Format           string   `envconfig:"LOG_FORMAT"`
+	OutputPaths      []string `envconfig:"LOG_OUTPUT_PATHS" default:"stderr"`
+	ErrorOutputPaths []string `envconfig:"LOG_ERROR_OUTPUT_PATHS" default:"stderr"`
+	// Within each second, the first SamplingInitial entries with the same
+	// level and message are logged, then every SamplingThereafter-th one.
+	// A SamplingInitial of zero disables sampling.
+	SamplingInitial    int `envconfig:"LOG_SAMPLING_INITIAL" default:"100"`
+	SamplingThereafter int `envconfig:"LOG_SAMPLING_THEREAFTER" default:"100"`
 }
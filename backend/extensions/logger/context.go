@@ -0,0 +1,46 @@
+package logger
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type requestIDKey struct{}
+
+// WithRequestID stores the request ID in ctx for FromContext.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns the request ID stored in ctx, if any.
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+
+	return requestID
+}
+
+// FromContext returns logger annotated with the trace_id, span_id and
+// request_id found in ctx, so log lines can be joined with traces and with
+// the client's X-Request-ID.
+func FromContext(ctx context.Context, logger *zap.Logger) *zap.Logger {
+	fields := make([]zap.Field, 0, 3)
+
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		fields = append(fields,
+			zap.String("trace_id", spanContext.TraceID().String()),
+			zap.String("span_id", spanContext.SpanID().String()),
+		)
+	}
+
+	if requestID := RequestID(ctx); requestID != "" {
+		fields = append(fields, zap.String("request_id", requestID))
+	}
+
+	if len(fields) == 0 {
+		return logger
+	}
+
+	return logger.With(fields...)
+}
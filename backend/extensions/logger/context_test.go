@@ -0,0 +1,50 @@
+package logger_test
+
+import (
+	"context"
+	"testing"
+
+	"lnk/extensions/logger"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func Test_FromContext(t *testing.T) {
+	t.Parallel()
+
+	core, logs := observer.New(zap.InfoLevel)
+	base := zap.New(core)
+
+	traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	require.NoError(t, err)
+	spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+	require.NoError(t, err)
+
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+	ctx = logger.WithRequestID(ctx, "req-1")
+
+	logger.FromContext(ctx, base).Info("annotated")
+	logger.FromContext(context.Background(), base).Info("plain")
+
+	entries := logs.All()
+	require.Len(t, entries, 2)
+	require.Equal(t, map[string]any{
+		"trace_id":   "4bf92f3577b34da6a3ce929d0e0e4736",
+		"span_id":    "00f067aa0ba902b7",
+		"request_id": "req-1",
+	}, entries[0].ContextMap())
+	require.Empty(t, entries[1].Context)
+}
+
+func Test_NewLogger_UnknownFormat(t *testing.T) {
+	t.Parallel()
+
+	_, err := logger.NewLogger(logger.Config{Level: "info", Format: "xml"})
+	require.ErrorContains(t, err, `unknown log format "xml"`)
+}
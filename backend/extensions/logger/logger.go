@@ -7,19 +7,69 @@ import (
 )
 
 func NewLogger(config Config) (*zap.Logger, error) {
-	if config.Level == "debug" {
-		logger, err := zap.NewDevelopment()
-		if err != nil {
-			return nil, fmt.Errorf("failed to create development logger: %w", err)
-		}
+	logger, _, err := NewLoggerWithLevel(config)
+
+	return logger, err
+}
+
+// NewLoggerWithLevel builds the logger around an AtomicLevel so the level can
+// be changed at runtime from the admin listener.
+func NewLoggerWithLevel(config Config) (*zap.Logger, zap.AtomicLevel, error) {
+	level, err := zap.ParseAtomicLevel(config.Level)
+	if err != nil {
+		return nil, level, fmt.Errorf("failed to parse log level: %w", err)
+	}
 
-		return logger, nil
+	zapConfig, err := zapConfig(config)
+	if err != nil {
+		return nil, level, err
 	}
 
-	logger, err := zap.NewProduction()
+	zapConfig.Level = level
+
+	logger, err := zapConfig.Build()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create production logger: %w", err)
+		return nil, level, fmt.Errorf("failed to create logger: %w", err)
+	}
+
+	return logger, level, nil
+}
+
+func zapConfig(config Config) (zap.Config, error) {
+	format := config.Format
+	if format == "" {
+		format = FormatJSON
+		if config.Level == "debug" {
+			format = FormatConsole
+		}
+	}
+
+	var zapConfig zap.Config
+
+	switch format {
+	case FormatJSON:
+		zapConfig = zap.NewProductionConfig()
+	case FormatConsole:
+		zapConfig = zap.NewDevelopmentConfig()
+	default:
+		return zapConfig, fmt.Errorf("unknown log format %q", config.Format)
+	}
+
+	zapConfig.Sampling = nil
+	if config.SamplingInitial > 0 {
+		zapConfig.Sampling = &zap.SamplingConfig{
+			Initial:    config.SamplingInitial,
+			Thereafter: config.SamplingThereafter,
+		}
+	}
+
+	if len(config.OutputPaths) > 0 {
+		zapConfig.OutputPaths = config.OutputPaths
+	}
+
+	if len(config.ErrorOutputPaths) > 0 {
+		zapConfig.ErrorOutputPaths = config.ErrorOutputPaths
 	}
 
-	return logger, nil
+	return zapConfig, nil
 }
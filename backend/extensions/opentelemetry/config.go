@@ -1,6 +1,48 @@
 package opentelemetry
 
+import "time"
+
+const (
+	ExporterOTLP       = "otlp"
+	ExporterPrometheus = "prometheus"
+	ExporterStdout     = "stdout"
+	ExporterNone       = "none"
+
+	ProtocolGRPC = "grpc"
+	ProtocolHTTP = "http/protobuf"
+)
+
 type Config struct {
 	ServiceName string `envconfig:"SERVICE_NAME" default:"lnk-backend"`
+	// Environment and InstanceID become the deployment.environment.name and
+	// service.instance.id resource attributes. InstanceID defaults to the
+	// hostname, which is the container ID under docker compose.
+	Environment string `envconfig:"ENV" default:"development"`
+	InstanceID  string `envconfig:"OTEL_SERVICE_INSTANCE_ID"`
 	Endpoint    string `envconfig:"OTEL_EXPORTER_OTLP_ENDPOINT" default:"localhost:4317"`
+	// Protocol is grpc or http/protobuf.
+	Protocol string `envconfig:"OTEL_EXPORTER_OTLP_PROTOCOL" default:"grpc"`
+	// Headers are sent with every OTLP export, as comma-separated key=value
+	// pairs with URL-encoded values, e.g. "authorization=Bearer%20token".
+	Headers string `envconfig:"OTEL_EXPORTER_OTLP_HEADERS"`
+	// TLS settings apply when Insecure is false. CAPath defaults to the
+	// system roots.
+	CAPath         string `envconfig:"OTEL_EXPORTER_OTLP_CERTIFICATE"`
+	ClientCertPath string `envconfig:"OTEL_EXPORTER_OTLP_CLIENT_CERTIFICATE"`
+	ClientKeyPath  string `envconfig:"OTEL_EXPORTER_OTLP_CLIENT_KEY"`
+	// TracesExporter is otlp, stdout or none.
+	TracesExporter string `envconfig:"OTEL_TRACES_EXPORTER" default:"otlp"`
+	// MetricsExporters lists the metric readers attached to the meter
+	// provider: otlp, prometheus, stdout or none.
+	MetricsExporters []string `envconfig:"OTEL_METRICS_EXPORTER" default:"otlp"`
+	// SampleRatio is the fraction of new traces sampled; child spans follow
+	// their parent's decision.
+	SampleRatio      float64       `envconfig:"OTEL_TRACES_SAMPLER_ARG" default:"1"`
+	ExportTimeout    time.Duration `envconfig:"OTEL_EXPORTER_OTLP_TIMEOUT" default:"10s"`
+	MetricInterval   time.Duration `envconfig:"OTEL_METRIC_EXPORT_INTERVAL" default:"10s"`
+	BatchTimeout     time.Duration `envconfig:"OTEL_BSP_SCHEDULE_DELAY" default:"1s"`
+	MaxQueueSize     int           `envconfig:"OTEL_BSP_MAX_QUEUE_SIZE" default:"2048"`
+	MaxExportBatch   int           `envconfig:"OTEL_BSP_MAX_EXPORT_BATCH_SIZE" default:"512"`
+	Insecure         bool          `envconfig:"OTEL_EXPORTER_OTLP_INSECURE" default:"true"`
+	SampleErrorSpans bool          `envconfig:"OTEL_TRACES_SAMPLE_ERRORS" default:"true"`
 }
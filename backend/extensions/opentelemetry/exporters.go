@@ -0,0 +1,207 @@
+package opentelemetry
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc/credentials"
+)
+
+// newSpanExporter returns the configured trace exporter, or nil when traces
+// are not exported.
+func newSpanExporter(ctx context.Context, cfg *Config) (sdktrace.SpanExporter, error) {
+	switch cfg.TracesExporter {
+	case ExporterOTLP:
+		client, err := newTraceClient(cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		exporter, err := otlptrace.New(ctx, client)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+		}
+
+		return exporter, nil
+	case ExporterStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+		if err != nil {
+			return nil, fmt.Errorf("failed to create stdout trace exporter: %w", err)
+		}
+
+		return exporter, nil
+	case ExporterNone, "":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown traces exporter %q", cfg.TracesExporter)
+	}
+}
+
+func newTraceClient(cfg *Config) (otlptrace.Client, error) {
+	headers, tlsConfig, err := transportSettings(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	switch cfg.Protocol {
+	case "", ProtocolGRPC:
+		options := []otlptracegrpc.Option{
+			otlptracegrpc.WithEndpoint(cfg.Endpoint),
+			otlptracegrpc.WithTimeout(cfg.ExportTimeout),
+			otlptracegrpc.WithHeaders(headers),
+		}
+
+		if tlsConfig == nil {
+			options = append(options, otlptracegrpc.WithInsecure())
+		} else {
+			options = append(options, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+		}
+
+		return otlptracegrpc.NewClient(options...), nil
+	case ProtocolHTTP:
+		options := []otlptracehttp.Option{
+			otlptracehttp.WithEndpoint(cfg.Endpoint),
+			otlptracehttp.WithTimeout(cfg.ExportTimeout),
+			otlptracehttp.WithHeaders(headers),
+		}
+
+		if tlsConfig == nil {
+			options = append(options, otlptracehttp.WithInsecure())
+		} else {
+			options = append(options, otlptracehttp.WithTLSClientConfig(tlsConfig))
+		}
+
+		return otlptracehttp.NewClient(options...), nil
+	default:
+		return nil, fmt.Errorf("unknown OTLP protocol %q", cfg.Protocol)
+	}
+}
+
+func newOTLPMetricExporter(ctx context.Context, cfg *Config) (sdkmetric.Exporter, error) {
+	headers, tlsConfig, err := transportSettings(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	switch cfg.Protocol {
+	case "", ProtocolGRPC:
+		options := []otlpmetricgrpc.Option{
+			otlpmetricgrpc.WithEndpoint(cfg.Endpoint),
+			otlpmetricgrpc.WithTimeout(cfg.ExportTimeout),
+			otlpmetricgrpc.WithHeaders(headers),
+		}
+
+		if tlsConfig == nil {
+			options = append(options, otlpmetricgrpc.WithInsecure())
+		} else {
+			options = append(options, otlpmetricgrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+		}
+
+		return otlpmetricgrpc.New(ctx, options...)
+	case ProtocolHTTP:
+		options := []otlpmetrichttp.Option{
+			otlpmetrichttp.WithEndpoint(cfg.Endpoint),
+			otlpmetrichttp.WithTimeout(cfg.ExportTimeout),
+			otlpmetrichttp.WithHeaders(headers),
+		}
+
+		if tlsConfig == nil {
+			options = append(options, otlpmetrichttp.WithInsecure())
+		} else {
+			options = append(options, otlpmetrichttp.WithTLSClientConfig(tlsConfig))
+		}
+
+		return otlpmetrichttp.New(ctx, options...)
+	default:
+		return nil, fmt.Errorf("unknown OTLP protocol %q", cfg.Protocol)
+	}
+}
+
+// transportSettings returns the export headers and, unless Insecure is set,
+// the TLS configuration shared by the trace and metric exporters.
+func transportSettings(cfg *Config) (map[string]string, *tls.Config, error) {
+	headers, err := parseHeaders(cfg.Headers)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if cfg.Insecure {
+		return headers, nil, nil
+	}
+
+	tlsConfig, err := newTLSConfig(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return headers, tlsConfig, nil
+}
+
+// parseHeaders parses the OTLP headers format: comma-separated key=value
+// pairs with URL-encoded values.
+func parseHeaders(raw string) (map[string]string, error) {
+	headers := map[string]string{}
+
+	for pair := range strings.SplitSeq(raw, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		key, value, found := strings.Cut(pair, "=")
+		if !found || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("invalid OTLP header %q, expected key=value", pair)
+		}
+
+		decoded, err := url.PathUnescape(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid OTLP header value for %q: %w", key, err)
+		}
+
+		headers[strings.TrimSpace(key)] = decoded
+	}
+
+	return headers, nil
+}
+
+func newTLSConfig(cfg *Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if cfg.CAPath != "" {
+		caPEM, err := os.ReadFile(cfg.CAPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read OTLP CA: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in %s", cfg.CAPath)
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.ClientCertPath != "" || cfg.ClientKeyPath != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCertPath, cfg.ClientKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load OTLP client certificate: %w", err)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,69 @@
+package opentelemetry
+
+import (
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// newSampler samples a SampleRatio fraction of new traces and follows the
+// parent's decision otherwise. With SampleErrorSpans, spans that are not
+// sampled are still recorded so errorSpanProcessor can export the ones that
+// end in error.
+func newSampler(cfg *Config) sdktrace.Sampler {
+	sampler := sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))
+	if !cfg.SampleErrorSpans {
+		return sampler
+	}
+
+	return recordingSampler{Sampler: sampler}
+}
+
+// recordingSampler turns drop decisions into record-only ones. Recorded spans
+// are not exported unless errorSpanProcessor promotes them.
+type recordingSampler struct {
+	sdktrace.Sampler
+}
+
+func (s recordingSampler) ShouldSample(params sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	result := s.Sampler.ShouldSample(params)
+	if result.Decision == sdktrace.Drop {
+		result.Decision = sdktrace.RecordOnly
+	}
+
+	return result
+}
+
+func (s recordingSampler) Description() string {
+	return "RecordingSampler{" + s.Sampler.Description() + "}"
+}
+
+// errorSpanProcessor forwards sampled spans unchanged and promotes recorded
+// but unsampled spans that ended in error, so failures are always exported
+// whatever the sampling ratio.
+type errorSpanProcessor struct {
+	sdktrace.SpanProcessor
+}
+
+func (p errorSpanProcessor) OnEnd(span sdktrace.ReadOnlySpan) {
+	if span.SpanContext().IsSampled() {
+		p.SpanProcessor.OnEnd(span)
+		return
+	}
+
+	if span.Status().Code == codes.Error {
+		p.SpanProcessor.OnEnd(sampledSpan{ReadOnlySpan: span})
+	}
+}
+
+// sampledSpan reports a recorded span as sampled so the batch processor
+// exports it.
+type sampledSpan struct {
+	sdktrace.ReadOnlySpan
+}
+
+func (s sampledSpan) SpanContext() trace.SpanContext {
+	spanContext := s.ReadOnlySpan.SpanContext()
+
+	return spanContext.WithTraceFlags(spanContext.TraceFlags().WithSampled(true))
+}
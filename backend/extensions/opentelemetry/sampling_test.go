@@ -0,0 +1,47 @@
+package opentelemetry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func Test_ErrorSpansAreAlwaysExported(t *testing.T) {
+	t.Parallel()
+
+	exporter := tracetest.NewInMemoryExporter()
+	cfg := &Config{SampleRatio: 0, SampleErrorSpans: true}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(newSampler(cfg)),
+		sdktrace.WithSpanProcessor(errorSpanProcessor{SpanProcessor: sdktrace.NewSimpleSpanProcessor(exporter)}),
+	)
+	tracer := provider.Tracer("test")
+
+	_, ok := tracer.Start(context.Background(), "ok")
+	ok.End()
+
+	_, failed := tracer.Start(context.Background(), "failed")
+	failed.SetStatus(codes.Error, "boom")
+	failed.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	require.Equal(t, "failed", spans[0].Name)
+	require.True(t, spans[0].SpanContext.IsSampled())
+}
+
+func Test_ParseHeaders(t *testing.T) {
+	t.Parallel()
+
+	headers, err := parseHeaders("authorization=Bearer%20token, x-tenant = lnk")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"authorization": "Bearer token", "x-tenant": "lnk"}, headers)
+
+	_, err = parseHeaders("missing-separator")
+	require.Error(t, err)
+}
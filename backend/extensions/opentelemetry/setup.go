@@ -2,15 +2,21 @@ package opentelemetry
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
-	"time"
 
+	"lnk/extensions/buildinfo"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	promexporter "go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
 	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
@@ -21,29 +27,50 @@ import (
 var Tracer trace.Tracer
 var Meter metric.Meter
 
-func SetupOTelSDK(ctx context.Context, cfg *Config) (func(context.Context) error, error) {
-	traceExp, err := newTraceExporter(ctx, cfg.Endpoint)
+// SDK is the installed OpenTelemetry SDK.
+type SDK struct {
+	// MetricsHandler serves the Prometheus scrape endpoint. It is nil unless
+	// the prometheus metrics exporter is enabled.
+	MetricsHandler http.Handler
+	// Shutdown flushes and stops the tracer and meter providers.
+	Shutdown func(ctx context.Context) error
+}
+
+// SetupOTelSDK installs the global tracer and meter providers. Exporters
+// connect lazily, so an unreachable collector does not fail or delay startup;
+// export errors are reported through the OpenTelemetry error handler instead.
+func SetupOTelSDK(ctx context.Context, cfg *Config) (*SDK, error) {
+	res, err := newResource(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize trace exporter: %w", err)
+		return nil, fmt.Errorf("failed to create resource: %w", err)
 	}
 
-	metricReader, err := newMetricExporter(ctx, cfg.Endpoint)
+	tp, err := newTracerProvider(ctx, cfg, res)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize metric exporter: %w", err)
+		return nil, fmt.Errorf("failed to initialize tracer provider: %w", err)
 	}
 
-	tp := newTracerProvider(traceExp, cfg.ServiceName)
-	mp := newMeterProvider(metricReader, cfg.ServiceName)
+	mp, metricsHandler, err := newMeterProvider(ctx, cfg, res)
+	if err != nil {
+		return nil, errors.Join(
+			fmt.Errorf("failed to initialize meter provider: %w", err),
+			tp.Shutdown(ctx),
+		)
+	}
 
 	otel.SetTracerProvider(tp)
 	otel.SetMeterProvider(mp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
 
 	_ = os.Stdout.Sync()
 
 	Tracer = tp.Tracer(cfg.ServiceName)
 	Meter = mp.Meter(cfg.ServiceName)
 
-	return func(ctx context.Context) error {
+	shutdown := func(ctx context.Context) error {
 		var errs []error
 		if err := tp.Shutdown(ctx); err != nil {
 			errs = append(errs, fmt.Errorf("failed to shutdown tracer provider: %w", err))
@@ -55,74 +82,133 @@ func SetupOTelSDK(ctx context.Context, cfg *Config) (func(context.Context) error
 			return fmt.Errorf("shutdown errors: %v", errs)
 		}
 		return nil
-	}, nil
+	}
+
+	return &SDK{MetricsHandler: metricsHandler, Shutdown: shutdown}, nil
 }
 
-func newTraceExporter(ctx context.Context, endpoint string) (sdktrace.SpanExporter, error) {
-	client := otlptracegrpc.NewClient(
-		otlptracegrpc.WithEndpoint(endpoint),
-		otlptracegrpc.WithInsecure(),
+// newResource describes this replica. OTEL_RESOURCE_ATTRIBUTES, read by
+// resource.Default, can add further attributes.
+func newResource(cfg *Config) (*resource.Resource, error) {
+	instanceID := cfg.InstanceID
+	if instanceID == "" {
+		instanceID, _ = os.Hostname()
+	}
+
+	return resource.Merge(
+		resource.Default(),
+		resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceNameKey.String(cfg.ServiceName),
+			semconv.ServiceVersion(buildinfo.Get().Version),
+			semconv.ServiceInstanceID(instanceID),
+			semconv.DeploymentEnvironmentName(cfg.Environment),
+		),
 	)
+}
 
-	traceExporter, err := otlptrace.New(ctx, client)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+// newMetricReaders builds the configured readers, and the scrape handler when
+// the prometheus exporter is one of them.
+func newMetricReaders(ctx context.Context, cfg *Config) ([]sdkmetric.Reader, http.Handler, error) {
+	var (
+		readers        []sdkmetric.Reader
+		metricsHandler http.Handler
+	)
+
+	for _, name := range cfg.MetricsExporters {
+		switch name {
+		case ExporterOTLP:
+			exporter, err := newOTLPMetricExporter(ctx, cfg)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to create OTLP metric exporter: %w", err)
+			}
+
+			readers = append(readers, sdkmetric.NewPeriodicReader(exporter,
+				sdkmetric.WithInterval(cfg.MetricInterval),
+			))
+		case ExporterPrometheus:
+			reader, handler, err := newPrometheusReader()
+			if err != nil {
+				return nil, nil, err
+			}
+
+			metricsHandler = handler
+			readers = append(readers, reader)
+		case ExporterStdout:
+			exporter, err := stdoutmetric.New(stdoutmetric.WithPrettyPrint())
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to create stdout metric exporter: %w", err)
+			}
+
+			readers = append(readers, sdkmetric.NewPeriodicReader(exporter,
+				sdkmetric.WithInterval(cfg.MetricInterval),
+			))
+		case ExporterNone, "":
+		default:
+			return nil, nil, fmt.Errorf("unknown metrics exporter %q", name)
+		}
 	}
-	return traceExporter, nil
+
+	return readers, metricsHandler, nil
 }
 
-func newMetricExporter(ctx context.Context, endpoint string) (sdkmetric.Reader, error) {
-	exporter, err := otlpmetricgrpc.New(
-		ctx,
-		otlpmetricgrpc.WithEndpoint(endpoint),
-		otlpmetricgrpc.WithInsecure(),
+// newPrometheusReader registers the OpenTelemetry metrics, together with the
+// Go runtime and process collectors, in a dedicated registry served by the
+// returned handler.
+func newPrometheusReader() (sdkmetric.Reader, http.Handler, error) {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
 	)
+
+	exporter, err := promexporter.New(promexporter.WithRegisterer(registry))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create OTLP metric exporter: %w", err)
+		return nil, nil, fmt.Errorf("failed to create Prometheus exporter: %w", err)
 	}
 
-	return sdkmetric.NewPeriodicReader(exporter,
-		sdkmetric.WithInterval(10*time.Second),
-	), nil
+	return exporter, promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry}), nil
 }
 
-func newMeterProvider(reader sdkmetric.Reader, serviceName string) *sdkmetric.MeterProvider {
-	r, err := resource.Merge(
-		resource.Default(),
-		resource.NewWithAttributes(
-			semconv.SchemaURL,
-			semconv.ServiceNameKey.String(serviceName),
-		),
-	)
-
+func newMeterProvider(ctx context.Context, cfg *Config, res *resource.Resource) (*sdkmetric.MeterProvider, http.Handler, error) {
+	readers, metricsHandler, err := newMetricReaders(ctx, cfg)
 	if err != nil {
-		panic(err)
+		return nil, nil, err
 	}
 
-	return sdkmetric.NewMeterProvider(
-		sdkmetric.WithReader(reader),
-		sdkmetric.WithResource(r),
-	)
+	options := []sdkmetric.Option{sdkmetric.WithResource(res)}
+	for _, reader := range readers {
+		options = append(options, sdkmetric.WithReader(reader))
+	}
+
+	return sdkmetric.NewMeterProvider(options...), metricsHandler, nil
 }
 
-func newTracerProvider(exp sdktrace.SpanExporter, serviceName string) *sdktrace.TracerProvider {
-	r, err := resource.Merge(
-		resource.Default(),
-		resource.NewWithAttributes(
-			semconv.SchemaURL,
-			semconv.ServiceNameKey.String(serviceName),
-		),
-	)
+func newTracerProvider(ctx context.Context, cfg *Config, res *resource.Resource) (*sdktrace.TracerProvider, error) {
+	options := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(newSampler(cfg)),
+	}
 
+	exp, err := newSpanExporter(ctx, cfg)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
-	return sdktrace.NewTracerProvider(
-		sdktrace.WithBatcher(exp,
-			sdktrace.WithBatchTimeout(1*time.Second),
-			sdktrace.WithMaxExportBatchSize(512),
-		),
-		sdktrace.WithResource(r),
-	)
+	if exp != nil {
+		var processor sdktrace.SpanProcessor = sdktrace.NewBatchSpanProcessor(exp,
+			sdktrace.WithBatchTimeout(cfg.BatchTimeout),
+			sdktrace.WithMaxQueueSize(cfg.MaxQueueSize),
+			sdktrace.WithMaxExportBatchSize(cfg.MaxExportBatch),
+			sdktrace.WithExportTimeout(cfg.ExportTimeout),
+		)
+
+		if cfg.SampleErrorSpans {
+			processor = errorSpanProcessor{SpanProcessor: processor}
+		}
+
+		options = append(options, sdktrace.WithSpanProcessor(processor))
+	}
+
+	return sdktrace.NewTracerProvider(options...), nil
 }
@@ -0,0 +1,58 @@
+package opentelemetry_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"lnk/extensions/opentelemetry"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+)
+
+func Test_SetupOTelSDK(t *testing.T) {
+	cfg := &opentelemetry.Config{
+		ServiceName:      "lnk-test",
+		Endpoint:         "127.0.0.1:1",
+		Protocol:         opentelemetry.ProtocolGRPC,
+		Insecure:         true,
+		MetricsExporters: []string{opentelemetry.ExporterOTLP, opentelemetry.ExporterPrometheus},
+		TracesExporter:   opentelemetry.ExporterOTLP,
+		ExportTimeout:    100 * time.Millisecond,
+		MetricInterval:   time.Hour,
+	}
+
+	start := time.Now()
+	sdk, err := opentelemetry.SetupOTelSDK(context.Background(), cfg)
+	require.NoError(t, err, "an unreachable collector must not fail startup")
+	require.Less(t, time.Since(start), time.Second, "an unreachable collector must not delay startup")
+
+	counter, err := otel.Meter("test").Int64Counter("test_requests_total")
+	require.NoError(t, err)
+	counter.Add(context.Background(), 3)
+
+	require.NotNil(t, sdk.MetricsHandler)
+
+	recorder := httptest.NewRecorder()
+	sdk.MetricsHandler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Regexp(t, `test_requests_total\{[^}]*\} 3`, recorder.Body.String())
+	require.Contains(t, recorder.Body.String(), "go_goroutines")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	_ = sdk.Shutdown(ctx)
+}
+
+func Test_SetupOTelSDK_UnknownExporter(t *testing.T) {
+	_, err := opentelemetry.SetupOTelSDK(context.Background(), &opentelemetry.Config{
+		ServiceName:      "lnk-test",
+		MetricsExporters: []string{"statsd"},
+		TracesExporter:   opentelemetry.ExporterNone,
+	})
+	require.ErrorContains(t, err, `unknown metrics exporter "statsd"`)
+}
@@ -0,0 +1,68 @@
+package pagemeta
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"syscall"
+)
+
+// reservedPrefixes are ranges that are not publicly routable but are not
+// covered by the netip predicates.
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+	netip.MustParsePrefix("100::/64"),
+	netip.MustParsePrefix("2001:db8::/32"),
+}
+
+// PublicAddress reports whether addr is a publicly routable unicast address.
+// Loopback, private, link-local, carrier-grade NAT, documentation and other
+// reserved ranges are not, including their IPv4-mapped IPv6 forms.
+func PublicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	if !addr.IsValid() || addr.IsUnspecified() || addr.IsLoopback() || addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() || addr.IsMulticast() || !addr.IsGlobalUnicast() {
+		return false
+	}
+
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// dialControl refuses connections to addresses allow rejects. It runs for the
+// resolved address of every connection, so names that resolve to internal
+// addresses and redirects to them are refused as well.
+func dialControl(allow func(netip.Addr) bool) func(network, address string, c syscall.RawConn) error {
+	return func(_, address string, _ syscall.RawConn) error {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrBlockedAddress, err)
+		}
+
+		addr, err := netip.ParseAddr(host)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrBlockedAddress, err)
+		}
+
+		if !allow(addr) {
+			return fmt.Errorf("%w: %s", ErrBlockedAddress, addr)
+		}
+
+		return nil
+	}
+}
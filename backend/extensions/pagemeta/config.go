@@ -0,0 +1,19 @@
+package pagemeta
+
+import "time"
+
+type Config struct {
+	// UserAgent is sent with every request. Its product token, the part before
+	// the first "/", selects the robots.txt group that applies.
+	UserAgent string `envconfig:"METADATA_FETCH_USER_AGENT" default:"lnk-preview/1.0"`
+	// Timeout bounds one attempt, including redirects and robots.txt.
+	Timeout      time.Duration `envconfig:"METADATA_FETCH_TIMEOUT" default:"10s"`
+	RobotsTTL    time.Duration `envconfig:"METADATA_FETCH_ROBOTS_TTL" default:"1h"`
+	Backoff      time.Duration `envconfig:"METADATA_FETCH_BACKOFF" default:"2s"`
+	MaxBodyBytes int64         `envconfig:"METADATA_FETCH_MAX_BYTES" default:"1048576"`
+	MaxRedirects int           `envconfig:"METADATA_FETCH_MAX_REDIRECTS" default:"5"`
+	Workers      int           `envconfig:"METADATA_FETCH_WORKERS" default:"4"`
+	QueueSize    int           `envconfig:"METADATA_FETCH_QUEUE_SIZE" default:"1000"`
+	Attempts     int           `envconfig:"METADATA_FETCH_ATTEMPTS" default:"3"`
+	Enabled      bool          `envconfig:"METADATA_FETCH_ENABLED" default:"false"`
+}
@@ -0,0 +1,310 @@
+// Package pagemeta fetches the title, description, favicon and Open Graph
+// image of web pages without letting the caller reach internal networks.
+package pagemeta
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+
+	"lnk/domain/entities"
+)
+
+const (
+	defaultUserAgent    = "lnk-preview/1.0"
+	defaultTimeout      = 10 * time.Second
+	defaultMaxBodyBytes = 1 << 20
+	defaultMaxRedirects = 5
+	defaultRobotsTTL    = time.Hour
+
+	// maxFaviconBytes bounds the favicon inlined into the preview page.
+	maxFaviconBytes = 32 << 10
+)
+
+// faviconTypes are the sniffed image types inlined as favicons. SVG is left
+// out because it can carry script.
+var faviconTypes = map[string]bool{
+	"image/x-icon": true,
+	"image/png":    true,
+	"image/gif":    true,
+	"image/jpeg":   true,
+	"image/webp":   true,
+	"image/bmp":    true,
+}
+
+var (
+	// ErrBlockedAddress is a destination, or a redirect target, that resolves
+	// to an address the fetcher may not connect to.
+	ErrBlockedAddress     = errors.New("destination address is not allowed")
+	ErrDisallowedByRobots = errors.New("destination is disallowed by robots.txt")
+	ErrNotHTML            = errors.New("destination is not an HTML page")
+	ErrUnsupportedScheme  = errors.New("destination must be an http or https URL")
+	ErrTooManyRedirects   = errors.New("destination redirects too often")
+)
+
+// StatusError is an unsuccessful HTTP response.
+type StatusError struct {
+	URL  string
+	Code int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s returned status %d", e.URL, e.Code)
+}
+
+// Temporary reports whether a later attempt may succeed.
+func (e *StatusError) Temporary() bool {
+	return e.Code == http.StatusTooManyRequests || e.Code == http.StatusRequestTimeout || e.Code >= http.StatusInternalServerError
+}
+
+// temporaryError marks network failures that a later attempt may not hit.
+type temporaryError struct {
+	err error
+}
+
+func (e *temporaryError) Error() string   { return e.err.Error() }
+func (e *temporaryError) Unwrap() error   { return e.err }
+func (e *temporaryError) Temporary() bool { return true }
+
+// fetchError marks a failed request as temporary unless the address was
+// refused, robots.txt disallowed a redirect target or it redirected too often,
+// which a retry would only repeat.
+func fetchError(err error) error {
+	if errors.Is(err, ErrBlockedAddress) || errors.Is(err, ErrTooManyRedirects) || errors.Is(err, ErrUnsupportedScheme) ||
+		errors.Is(err, ErrDisallowedByRobots) {
+		return err
+	}
+
+	return &temporaryError{err: err}
+}
+
+// Temporary reports whether err is a failure that a later attempt may not
+// hit, such as a timeout or a server error.
+func Temporary(err error) bool {
+	var temporary interface{ Temporary() bool }
+
+	return errors.As(err, &temporary) && temporary.Temporary()
+}
+
+// Fetcher fetches page metadata. It only connects to addresses its policy
+// allows, checked for the resolved address of every connection including
+// redirects, honours robots.txt for the page and every redirect target, and
+// reads at most MaxBodyBytes of a page.
+type Fetcher struct {
+	client       *http.Client
+	robots       *robotsCache
+	userAgent    string
+	agent        string
+	maxBytes     int64
+	maxRedirects int
+}
+
+type NewFetcherParams struct {
+	// AllowAddress reports whether the fetcher may connect to an address. It
+	// defaults to PublicAddress; tests allow loopback to reach httptest
+	// servers.
+	AllowAddress func(netip.Addr) bool
+	UserAgent    string
+	Timeout      time.Duration
+	RobotsTTL    time.Duration
+	MaxBodyBytes int64
+	MaxRedirects int
+}
+
+func NewFetcher(params NewFetcherParams) *Fetcher {
+	if params.AllowAddress == nil {
+		params.AllowAddress = PublicAddress
+	}
+
+	if params.UserAgent == "" {
+		params.UserAgent = defaultUserAgent
+	}
+
+	if params.Timeout <= 0 {
+		params.Timeout = defaultTimeout
+	}
+
+	if params.RobotsTTL <= 0 {
+		params.RobotsTTL = defaultRobotsTTL
+	}
+
+	if params.MaxBodyBytes <= 0 {
+		params.MaxBodyBytes = defaultMaxBodyBytes
+	}
+
+	if params.MaxRedirects <= 0 {
+		params.MaxRedirects = defaultMaxRedirects
+	}
+
+	dialer := &net.Dialer{
+		Timeout: params.Timeout,
+		Control: dialControl(params.AllowAddress),
+	}
+
+	// Proxies are not used: the address check must see the destination, not
+	// the proxy.
+	transport := &http.Transport{
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   params.Timeout,
+		ResponseHeaderTimeout: params.Timeout,
+		MaxIdleConns:          16,
+		IdleConnTimeout:       30 * time.Second,
+	}
+
+	agent, _, _ := strings.Cut(params.UserAgent, "/")
+
+	fetcher := &Fetcher{
+		robots:       newRobotsCache(params.RobotsTTL),
+		userAgent:    params.UserAgent,
+		agent:        strings.ToLower(agent),
+		maxBytes:     params.MaxBodyBytes,
+		maxRedirects: params.MaxRedirects,
+	}
+
+	fetcher.client = &http.Client{
+		Transport:     transport,
+		Timeout:       params.Timeout,
+		CheckRedirect: fetcher.checkRedirect,
+	}
+
+	return fetcher
+}
+
+// checkRedirect bounds redirects and applies robots.txt to every redirect
+// target, whose host may differ from the page's. Redirects of robots.txt
+// itself are followed without a check.
+func (f *Fetcher) checkRedirect(request *http.Request, via []*http.Request) error {
+	if len(via) > f.maxRedirects {
+		return ErrTooManyRedirects
+	}
+
+	if request.URL.Scheme != "http" && request.URL.Scheme != "https" {
+		return ErrUnsupportedScheme
+	}
+
+	if via[0].URL.Path == "/robots.txt" {
+		return nil
+	}
+
+	allowed, err := f.allowed(request.Context(), request.URL)
+	if err != nil {
+		return err
+	}
+
+	if !allowed {
+		return ErrDisallowedByRobots
+	}
+
+	return nil
+}
+
+// Fetch returns the metadata of the page at rawURL. Relative favicon and image
+// URLs are resolved against the page's final URL after redirects, and the
+// favicon defaults to /favicon.ico. The favicon itself is fetched within the
+// same timeout and inlined as a data: URI; failing to fetch it leaves Favicon
+// empty without failing the page. Errors for which Temporary is true may
+// succeed on a later attempt.
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (*entities.PageMetadata, error) {
+	target, err := url.Parse(rawURL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, ErrUnsupportedScheme
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, f.client.Timeout)
+	defer cancel()
+
+	allowed, err := f.allowed(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+
+	if !allowed {
+		return nil, ErrDisallowedByRobots
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	request.Header.Set("User-Agent", f.userAgent)
+	request.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9")
+
+	response, err := f.client.Do(request)
+	if err != nil {
+		return nil, fetchError(fmt.Errorf("failed to fetch page: %w", err))
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return nil, &StatusError{URL: response.Request.URL.String(), Code: response.StatusCode}
+	}
+
+	contentType := response.Header.Get("Content-Type")
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, fmt.Errorf("%w: %q", ErrNotHTML, contentType)
+	}
+
+	metadata, err := parsePage(response.Body, f.maxBytes, contentType, response.Request.URL)
+	if err != nil {
+		return nil, fetchError(err)
+	}
+
+	metadata.Favicon = f.fetchFavicon(ctx, metadata.FaviconURL)
+
+	return metadata, nil
+}
+
+// fetchFavicon returns the image at rawURL as a data: URI, or an empty string
+// if it is disallowed, unreachable, larger than maxFaviconBytes or not a
+// raster image. The type is sniffed from the bytes, since favicons are often
+// served with generic content types.
+func (f *Fetcher) fetchFavicon(ctx context.Context, rawURL string) string {
+	target, err := url.Parse(rawURL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return ""
+	}
+
+	if allowed, err := f.allowed(ctx, target); err != nil || !allowed {
+		return ""
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return ""
+	}
+
+	request.Header.Set("User-Agent", f.userAgent)
+	request.Header.Set("Accept", "image/*")
+
+	response, err := f.client.Do(request)
+	if err != nil {
+		return ""
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return ""
+	}
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, maxFaviconBytes+1))
+	if err != nil || len(body) == 0 || len(body) > maxFaviconBytes {
+		return ""
+	}
+
+	mediaType := http.DetectContentType(body)
+	if !faviconTypes[mediaType] {
+		return ""
+	}
+
+	return "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(body)
+}
@@ -0,0 +1,236 @@
+package pagemeta_test
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"lnk/extensions/pagemeta"
+
+	"github.com/stretchr/testify/require"
+)
+
+const page = `<!doctype html>
+<html>
+<head>
+  <title>
+    Spring  sale
+  </title>
+  <meta name="description" content="Everything must go">
+  <meta property="og:image" content="/og.png">
+  <link rel="shortcut icon" href="https://cdn.example/icon.png">
+</head>
+<body><title>Not the title</title></body>
+</html>`
+
+// loopbackOnly lets tests reach httptest servers while every other
+// non-public address stays blocked.
+func loopbackOnly(addr netip.Addr) bool {
+	return addr.IsLoopback() || pagemeta.PublicAddress(addr)
+}
+
+func newServer(t *testing.T, robots string, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, _ *http.Request) {
+		if robots == "" {
+			http.NotFound(w, nil)
+			return
+		}
+
+		_, _ = w.Write([]byte(robots))
+	})
+	mux.HandleFunc("/", handler)
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func servePage(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write([]byte(page))
+}
+
+func Test_Fetcher_Fetch(t *testing.T) {
+	t.Parallel()
+
+	fetcher := pagemeta.NewFetcher(pagemeta.NewFetcherParams{AllowAddress: loopbackOnly})
+
+	t.Run("reads the head of the page", func(t *testing.T) {
+		t.Parallel()
+
+		server := newServer(t, "", servePage)
+
+		metadata, err := fetcher.Fetch(context.Background(), server.URL+"/sale")
+		require.NoError(t, err)
+		require.Equal(t, "Spring sale", metadata.Title)
+		require.Equal(t, "Everything must go", metadata.Description)
+		require.Equal(t, server.URL+"/og.png", metadata.ImageURL)
+		require.Equal(t, "https://cdn.example/icon.png", metadata.FaviconURL)
+	})
+
+	t.Run("honours robots.txt", func(t *testing.T) {
+		t.Parallel()
+
+		server := newServer(t, "User-agent: *\nDisallow: /\n\nUser-agent: lnk-preview\nDisallow: /private\nAllow: /private/open$\n", servePage)
+
+		_, err := fetcher.Fetch(context.Background(), server.URL+"/private/page")
+		require.ErrorIs(t, err, pagemeta.ErrDisallowedByRobots)
+		require.False(t, pagemeta.Temporary(err))
+
+		_, err = fetcher.Fetch(context.Background(), server.URL+"/private/open")
+		require.NoError(t, err)
+	})
+
+	t.Run("blocks internal addresses by default", func(t *testing.T) {
+		t.Parallel()
+
+		server := newServer(t, "", servePage)
+
+		_, err := pagemeta.NewFetcher(pagemeta.NewFetcherParams{}).Fetch(context.Background(), server.URL)
+		require.ErrorIs(t, err, pagemeta.ErrBlockedAddress)
+		require.False(t, pagemeta.Temporary(err))
+	})
+
+	t.Run("blocks redirects to internal addresses", func(t *testing.T) {
+		t.Parallel()
+
+		server := newServer(t, "", func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, "http://169.254.169.254/latest/meta-data/", http.StatusFound)
+		})
+
+		_, err := fetcher.Fetch(context.Background(), server.URL)
+		require.ErrorIs(t, err, pagemeta.ErrBlockedAddress)
+	})
+
+	t.Run("honours robots.txt of redirect targets", func(t *testing.T) {
+		t.Parallel()
+
+		target := newServer(t, "User-agent: *\nDisallow: /private\n", servePage)
+		server := newServer(t, "", func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, target.URL+r.URL.Path, http.StatusFound)
+		})
+
+		_, err := fetcher.Fetch(context.Background(), server.URL+"/private/page")
+		require.ErrorIs(t, err, pagemeta.ErrDisallowedByRobots)
+		require.False(t, pagemeta.Temporary(err))
+
+		metadata, err := fetcher.Fetch(context.Background(), server.URL+"/sale")
+		require.NoError(t, err)
+		require.Equal(t, "Spring sale", metadata.Title)
+	})
+
+	t.Run("reads at most the size limit", func(t *testing.T) {
+		t.Parallel()
+
+		server := newServer(t, "", func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte("<html><head>" + strings.Repeat("<!-- padding -->", 1000) + "<title>Too late</title>"))
+		})
+
+		limited := pagemeta.NewFetcher(pagemeta.NewFetcherParams{AllowAddress: loopbackOnly, MaxBodyBytes: 1024})
+
+		metadata, err := limited.Fetch(context.Background(), server.URL)
+		require.NoError(t, err)
+		require.Empty(t, metadata.Title)
+	})
+
+	t.Run("reports server errors as temporary", func(t *testing.T) {
+		t.Parallel()
+
+		server := newServer(t, "", func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		})
+
+		_, err := fetcher.Fetch(context.Background(), server.URL)
+		require.True(t, pagemeta.Temporary(err))
+
+		server = newServer(t, "", func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/pdf")
+		})
+
+		_, err = fetcher.Fetch(context.Background(), server.URL)
+		require.ErrorIs(t, err, pagemeta.ErrNotHTML)
+		require.False(t, pagemeta.Temporary(err))
+	})
+}
+
+func Test_Fetcher_Favicon(t *testing.T) {
+	t.Parallel()
+
+	fetcher := pagemeta.NewFetcher(pagemeta.NewFetcherParams{AllowAddress: loopbackOnly})
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+	for name, tc := range map[string]struct {
+		robots  string
+		favicon string
+		icon    []byte
+	}{
+		"inlines a raster image": {
+			icon:    png,
+			favicon: "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+		},
+		"skips SVG": {
+			icon: []byte(`<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`),
+		},
+		"skips oversized images": {
+			icon: append(png, make([]byte, 32<<10)...),
+		},
+		"honours robots.txt": {
+			robots: "User-agent: *\nDisallow: /favicon.ico\n",
+			icon:   png,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			server := newServer(t, tc.robots, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/favicon.ico" {
+					// Favicons are often served with generic types; the
+					// fetcher sniffs the bytes instead.
+					w.Header().Set("Content-Type", "application/octet-stream")
+					_, _ = w.Write(tc.icon)
+
+					return
+				}
+
+				w.Header().Set("Content-Type", "text/html")
+				_, _ = w.Write([]byte("<title>Sale</title>"))
+			})
+
+			metadata, err := fetcher.Fetch(context.Background(), server.URL)
+			require.NoError(t, err)
+			require.Equal(t, server.URL+"/favicon.ico", metadata.FaviconURL)
+			require.Equal(t, tc.favicon, metadata.Favicon)
+		})
+	}
+}
+
+func Test_PublicAddress(t *testing.T) {
+	t.Parallel()
+
+	for addr, public := range map[string]bool{
+		"93.184.215.14":        true,
+		"2606:2800:21f:cb07::": true,
+		"127.0.0.1":            false,
+		"10.1.2.3":             false,
+		"172.16.0.1":           false,
+		"192.168.1.1":          false,
+		"169.254.169.254":      false,
+		"100.64.0.1":           false,
+		"0.0.0.0":              false,
+		"::1":                  false,
+		"fd00::1":              false,
+		"fe80::1":              false,
+		"::ffff:10.0.0.1":      false,
+	} {
+		require.Equal(t, public, pagemeta.PublicAddress(netip.MustParseAddr(addr)), addr)
+	}
+}
@@ -0,0 +1,165 @@
+package pagemeta
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"lnk/domain/entities"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+const (
+	maxTitleLength       = 300
+	maxDescriptionLength = 1000
+	maxURLLength         = 2048
+)
+
+// parsePage reads the head of an HTML document of at most maxBytes. Reading
+// stops at the body, so large pages cost no more than their head.
+func parsePage(body io.Reader, maxBytes int64, contentType string, base *url.URL) (*entities.PageMetadata, error) {
+	reader, err := charset.NewReader(io.LimitReader(body, maxBytes), contentType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode page: %w", err)
+	}
+
+	var (
+		found   page
+		inTitle bool
+	)
+
+	tokenizer := html.NewTokenizer(reader)
+
+	for {
+		tokenType := tokenizer.Next()
+
+		if tokenType == html.ErrorToken {
+			if err := tokenizer.Err(); err != io.EOF {
+				return nil, fmt.Errorf("failed to parse page: %w", err)
+			}
+
+			return found.metadata(base), nil
+		}
+
+		token := tokenizer.Token()
+
+		switch {
+		case tokenType == html.TextToken && inTitle:
+			found.title += token.Data
+		case tokenType == html.EndTagToken && token.Data == "title":
+			inTitle = false
+		case tokenType == html.StartTagToken && token.Data == "title":
+			inTitle = found.title == ""
+		case tokenType == html.StartTagToken && token.Data == "body",
+			tokenType == html.EndTagToken && token.Data == "head":
+			return found.metadata(base), nil
+		case token.Data == "meta":
+			found.meta(token)
+		case token.Data == "link":
+			for _, rel := range strings.Fields(strings.ToLower(attribute(token, "rel"))) {
+				if rel == "icon" && found.icon == "" {
+					found.icon = attribute(token, "href")
+				}
+			}
+		}
+	}
+}
+
+// page collects the metadata candidates of a document head.
+type page struct {
+	title         string
+	ogTitle       string
+	description   string
+	ogDescription string
+	image         string
+	icon          string
+}
+
+func (p *page) meta(token html.Token) {
+	content := attribute(token, "content")
+
+	if strings.EqualFold(attribute(token, "name"), "description") {
+		p.description = content
+		return
+	}
+
+	switch strings.ToLower(attribute(token, "property")) {
+	case "og:title":
+		p.ogTitle = content
+	case "og:description":
+		p.ogDescription = content
+	case "og:image", "og:image:url":
+		if p.image == "" {
+			p.image = content
+		}
+	}
+}
+
+// metadata prefers the document title and description over their Open Graph
+// counterparts, and defaults the favicon to /favicon.ico.
+func (p *page) metadata(base *url.URL) *entities.PageMetadata {
+	title := strings.Join(strings.Fields(p.title), " ")
+	if title == "" {
+		title = strings.TrimSpace(p.ogTitle)
+	}
+
+	description := strings.TrimSpace(p.description)
+	if description == "" {
+		description = strings.TrimSpace(p.ogDescription)
+	}
+
+	icon := p.icon
+	if icon == "" {
+		icon = "/favicon.ico"
+	}
+
+	return &entities.PageMetadata{
+		Title:       truncate(title, maxTitleLength),
+		Description: truncate(description, maxDescriptionLength),
+		FaviconURL:  resolve(base, icon),
+		ImageURL:    resolve(base, p.image),
+	}
+}
+
+func attribute(token html.Token, key string) string {
+	for _, attr := range token.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+
+	return ""
+}
+
+// resolve returns ref as an absolute http or https URL, or "" when it is not
+// one.
+func resolve(base *url.URL, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return ""
+	}
+
+	parsed, err := base.Parse(ref)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return ""
+	}
+
+	resolved := parsed.String()
+	if len(resolved) > maxURLLength {
+		return ""
+	}
+
+	return resolved
+}
+
+func truncate(value string, limit int) string {
+	if utf8.RuneCountInString(value) <= limit {
+		return value
+	}
+
+	return string([]rune(value)[:limit])
+}
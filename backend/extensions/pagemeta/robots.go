@@ -0,0 +1,215 @@
+package pagemeta
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	maxRobotsBytes = 64 << 10
+	// maxRobotsEntries bounds the robots cache; it is cleared when full.
+	maxRobotsEntries = 1024
+)
+
+// robotsRule is an Allow or Disallow line of the group that applies.
+type robotsRule struct {
+	pattern string
+	allow   bool
+}
+
+type robotsEntry struct {
+	expires time.Time
+	rules   []robotsRule
+}
+
+// robotsCache keeps the rules of each origin for a TTL.
+type robotsCache struct {
+	entries map[string]robotsEntry
+	ttl     time.Duration
+	mu      sync.Mutex
+}
+
+func newRobotsCache(ttl time.Duration) *robotsCache {
+	return &robotsCache{entries: map[string]robotsEntry{}, ttl: ttl}
+}
+
+// allowed reports whether the robots.txt of target's origin lets agent fetch
+// it. A missing robots.txt allows everything; a server error fails, so the
+// fetch is retried later rather than ignoring the rules.
+func (f *Fetcher) allowed(ctx context.Context, target *url.URL) (bool, error) {
+	origin := target.Scheme + "://" + target.Host
+
+	f.robots.mu.Lock()
+	entry, ok := f.robots.entries[origin]
+	f.robots.mu.Unlock()
+
+	if !ok || time.Now().After(entry.expires) {
+		rules, err := f.fetchRobots(ctx, origin)
+		if err != nil {
+			return false, err
+		}
+
+		entry = robotsEntry{expires: time.Now().Add(f.robots.ttl), rules: rules}
+
+		f.robots.mu.Lock()
+		if len(f.robots.entries) >= maxRobotsEntries {
+			clear(f.robots.entries)
+		}
+
+		f.robots.entries[origin] = entry
+		f.robots.mu.Unlock()
+	}
+
+	path := target.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	if target.RawQuery != "" {
+		path += "?" + target.RawQuery
+	}
+
+	return robotsAllow(entry.rules, path), nil
+}
+
+func (f *Fetcher) fetchRobots(ctx context.Context, origin string) ([]robotsRule, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, origin+"/robots.txt", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build robots.txt request: %w", err)
+	}
+
+	request.Header.Set("User-Agent", f.userAgent)
+
+	response, err := f.client.Do(request)
+	if err != nil {
+		return nil, fetchError(fmt.Errorf("failed to fetch robots.txt: %w", err))
+	}
+	defer response.Body.Close()
+
+	switch {
+	case response.StatusCode >= http.StatusInternalServerError || response.StatusCode == http.StatusTooManyRequests:
+		return nil, &StatusError{Code: response.StatusCode, URL: origin + "/robots.txt"}
+	case response.StatusCode >= http.StatusBadRequest:
+		return nil, nil
+	}
+
+	return parseRobots(io.LimitReader(response.Body, maxRobotsBytes), f.agent), nil
+}
+
+// parseRobots returns the rules of the group for agent, or of the "*" group
+// when no group names agent.
+func parseRobots(r io.Reader, agent string) []robotsRule {
+	var (
+		specific, wildcard    []robotsRule
+		matchesAgent, matches bool
+		inAgents, isWildcard  bool
+	)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if !inAgents {
+				matches, isWildcard = false, false
+			}
+
+			inAgents = true
+			name := strings.ToLower(value)
+
+			switch {
+			case name == "*":
+				isWildcard = true
+			case name == agent:
+				matches = true
+				matchesAgent = true
+			}
+		case "allow", "disallow":
+			inAgents = false
+
+			if value == "" {
+				continue
+			}
+
+			rule := robotsRule{pattern: value, allow: key == "allow"}
+
+			if matches {
+				specific = append(specific, rule)
+			} else if isWildcard {
+				wildcard = append(wildcard, rule)
+			}
+		default:
+			inAgents = false
+		}
+	}
+
+	if matchesAgent {
+		return specific
+	}
+
+	return wildcard
+}
+
+// robotsAllow applies the longest matching rule; Allow wins ties.
+func robotsAllow(rules []robotsRule, path string) bool {
+	allowed, longest := true, -1
+
+	for _, rule := range rules {
+		if !robotsMatch(rule.pattern, path) {
+			continue
+		}
+
+		if len(rule.pattern) > longest || (len(rule.pattern) == longest && rule.allow) {
+			allowed, longest = rule.allow, len(rule.pattern)
+		}
+	}
+
+	return allowed
+}
+
+// robotsMatch matches a path prefix pattern in which "*" matches any run of
+// characters and a trailing "$" anchors the end.
+func robotsMatch(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+
+	parts := strings.Split(pattern, "*")
+
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+
+	rest := path[len(parts[0]):]
+
+	for i, part := range parts[1:] {
+		last := i == len(parts)-2
+		if last && anchored {
+			return strings.HasSuffix(rest, part)
+		}
+
+		index := strings.Index(rest, part)
+		if index < 0 {
+			return false
+		}
+
+		rest = rest[index+len(part):]
+	}
+
+	return !anchored || rest == ""
+}
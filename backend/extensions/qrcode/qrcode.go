@@ -0,0 +1,373 @@
+// Package qrcode renders QR codes as PNG or SVG in pure Go.
+package qrcode
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+	xdraw "golang.org/x/image/draw"
+)
+
+const (
+	FormatPNG = "png"
+	FormatSVG = "svg"
+
+	MinSize   = 64
+	MaxSize   = 2048
+	MaxMargin = 16
+
+	// logoFraction is the share of the symbol width covered by the logo. It
+	// stays well below what error-correction level H can recover.
+	logoFraction = 0.2
+)
+
+var (
+	ErrInvalidFormat = errors.New("format must be png or svg")
+	ErrInvalidSize   = fmt.Errorf("size must be between %d and %d", MinSize, MaxSize)
+	ErrInvalidMargin = fmt.Errorf("margin must be between 0 and %d", MaxMargin)
+	ErrInvalidLevel  = errors.New("error correction level must be L, M, Q or H")
+	ErrInvalidColor  = errors.New("colors must be RRGGBB or RRGGBBAA hex values")
+	ErrNoLogo        = errors.New("no logo is configured")
+)
+
+var levels = map[string]qrcode.RecoveryLevel{
+	"L": qrcode.Low,
+	"M": qrcode.Medium,
+	"Q": qrcode.High,
+	"H": qrcode.Highest,
+}
+
+// Options describe how a QR code is rendered. Colors are RRGGBB or RRGGBBAA
+// hex values with an optional leading '#'.
+type Options struct {
+	Format     string
+	Level      string
+	Foreground string
+	Background string
+	Size       int
+	// Margin is the quiet zone around the symbol, in modules.
+	Margin int
+	Logo   bool
+}
+
+// DefaultOptions renders a 256 pixel black-on-white PNG with error-correction
+// level M and the standard four-module quiet zone.
+func DefaultOptions() Options {
+	return Options{
+		Format:     FormatPNG,
+		Level:      "M",
+		Foreground: "000000",
+		Background: "ffffff",
+		Size:       256,
+		Margin:     4,
+	}
+}
+
+// Image is a rendered QR code.
+type Image struct {
+	ContentType string
+	Data        []byte
+}
+
+// Cache stores rendered images by key. Get returns a nil slice on a miss.
+// Keys start with the tag of the render and a colon, so Evict can remove
+// every render of a tag.
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, data []byte) error
+	Evict(ctx context.Context, tag string) error
+	EvictAll(ctx context.Context) error
+}
+
+// Renderer renders QR codes, optionally with a centered logo, and caches the
+// results. A Renderer without a cache renders every request.
+type Renderer struct {
+	cache   Cache
+	logo    image.Image
+	onError func(error)
+	logoPNG []byte
+}
+
+type NewRendererParams struct {
+	Cache Cache
+	// Logo is a PNG image drawn in the center of codes rendered with
+	// Options.Logo. It is optional.
+	Logo io.Reader
+	// OnError is called with cache failures, which never fail a render.
+	OnError func(error)
+}
+
+func NewRenderer(params NewRendererParams) (*Renderer, error) {
+	renderer := &Renderer{cache: params.Cache, onError: params.OnError}
+
+	if params.Logo != nil {
+		data, err := io.ReadAll(params.Logo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read QR logo: %w", err)
+		}
+
+		logo, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode QR logo: %w", err)
+		}
+
+		renderer.logo = logo
+		renderer.logoPNG = data
+	}
+
+	return renderer, nil
+}
+
+// Validate reports the first invalid option.
+func (r *Renderer) Validate(opts Options) error {
+	switch {
+	case opts.Format != FormatPNG && opts.Format != FormatSVG:
+		return ErrInvalidFormat
+	case opts.Size < MinSize || opts.Size > MaxSize:
+		return ErrInvalidSize
+	case opts.Margin < 0 || opts.Margin > MaxMargin:
+		return ErrInvalidMargin
+	case opts.Logo && r.logo == nil:
+		return ErrNoLogo
+	}
+
+	if _, ok := levels[strings.ToUpper(opts.Level)]; !ok {
+		return ErrInvalidLevel
+	}
+
+	if _, err := parseColor(opts.Foreground); err != nil {
+		return err
+	}
+
+	if _, err := parseColor(opts.Background); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Render encodes content with opts. Codes with a logo always use
+// error-correction level H, so the covered modules can be recovered. The
+// result is cached under tag, such as the short code the content links to,
+// so Purge can evict every variant of it.
+func (r *Renderer) Render(ctx context.Context, tag, content string, opts Options) (*Image, error) {
+	if err := r.Validate(opts); err != nil {
+		return nil, err
+	}
+
+	opts.Level = strings.ToUpper(opts.Level)
+	if opts.Logo {
+		opts.Level = "H"
+	}
+
+	contentType := "image/png"
+	if opts.Format == FormatSVG {
+		contentType = "image/svg+xml"
+	}
+
+	key := cacheKey(tag, content, opts)
+
+	if r.cache != nil {
+		data, err := r.cache.Get(ctx, key)
+		if err != nil {
+			r.reportError(err)
+		}
+
+		if data != nil {
+			return &Image{ContentType: contentType, Data: data}, nil
+		}
+	}
+
+	code, err := qrcode.New(content, levels[opts.Level])
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode QR code: %w", err)
+	}
+
+	code.DisableBorder = true
+
+	var data []byte
+	if opts.Format == FormatSVG {
+		data = r.svg(code.Bitmap(), opts)
+	} else {
+		data, err = r.png(code.Bitmap(), opts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if r.cache != nil {
+		if err := r.cache.Set(ctx, key, data); err != nil {
+			r.reportError(err)
+		}
+	}
+
+	return &Image{ContentType: contentType, Data: data}, nil
+}
+
+// Purge evicts the cached renders of tag.
+func (r *Renderer) Purge(ctx context.Context, tag string) error {
+	if r.cache == nil {
+		return nil
+	}
+
+	return r.cache.Evict(ctx, tag)
+}
+
+// PurgeAll evicts every cached render.
+func (r *Renderer) PurgeAll(ctx context.Context) error {
+	if r.cache == nil {
+		return nil
+	}
+
+	return r.cache.EvictAll(ctx)
+}
+
+func (r *Renderer) png(modules [][]bool, opts Options) ([]byte, error) {
+	fg, _ := parseColor(opts.Foreground)
+	bg, _ := parseColor(opts.Background)
+
+	count := len(modules) + 2*opts.Margin
+	img := image.NewNRGBA(image.Rect(0, 0, opts.Size, opts.Size))
+	draw.Draw(img, img.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+
+	// Pixels are mapped to modules proportionally, so any size is exact
+	// rather than rounded down to a multiple of the module count.
+	for y := range opts.Size {
+		row := y*count/opts.Size - opts.Margin
+		if row < 0 || row >= len(modules) {
+			continue
+		}
+
+		for x := range opts.Size {
+			col := x*count/opts.Size - opts.Margin
+			if col >= 0 && col < len(modules) && modules[row][col] {
+				img.Set(x, y, fg)
+			}
+		}
+	}
+
+	if opts.Logo {
+		side := int(float64(opts.Size*len(modules)/count) * logoFraction)
+		offset := (opts.Size - side) / 2
+		area := image.Rect(offset, offset, offset+side, offset+side)
+
+		draw.Draw(img, area.Inset(-side/10), image.NewUniform(bg), image.Point{}, draw.Src)
+		xdraw.CatmullRom.Scale(img, area, r.logo, r.logo.Bounds(), draw.Over, nil)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode PNG: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (r *Renderer) svg(modules [][]bool, opts Options) []byte {
+	fg, _ := parseColor(opts.Foreground)
+	bg, _ := parseColor(opts.Background)
+
+	count := len(modules) + 2*opts.Margin
+
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		opts.Size, opts.Size, count, count)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" %s/>`, count, count, svgFill(bg))
+	fmt.Fprintf(&buf, `<path %s d="`, svgFill(fg))
+
+	// Runs of dark modules in a row become one rectangle each.
+	for y, row := range modules {
+		for x := 0; x < len(row); x++ {
+			if !row[x] {
+				continue
+			}
+
+			start := x
+			for x < len(row) && row[x] {
+				x++
+			}
+
+			fmt.Fprintf(&buf, "M%d %dh%dv1h-%dz", start+opts.Margin, y+opts.Margin, x-start, x-start)
+		}
+	}
+
+	buf.WriteString(`"/>`)
+
+	if opts.Logo {
+		side := float64(len(modules)) * logoFraction
+		offset := (float64(count) - side) / 2
+		pad := side / 10
+
+		fmt.Fprintf(&buf, `<rect x="%s" y="%s" width="%s" height="%s" %s/>`,
+			svgNumber(offset-pad), svgNumber(offset-pad), svgNumber(side+2*pad), svgNumber(side+2*pad), svgFill(bg))
+		fmt.Fprintf(&buf, `<image x="%s" y="%s" width="%s" height="%s" href="data:image/png;base64,%s"/>`,
+			svgNumber(offset), svgNumber(offset), svgNumber(side), svgNumber(side), base64.StdEncoding.EncodeToString(r.logoPNG))
+	}
+
+	buf.WriteString(`</svg>`)
+
+	return buf.Bytes()
+}
+
+func (r *Renderer) reportError(err error) {
+	if r.onError != nil {
+		r.onError(err)
+	}
+}
+
+func parseColor(value string) (color.NRGBA, error) {
+	value = strings.TrimPrefix(value, "#")
+	if len(value) != 6 && len(value) != 8 {
+		return color.NRGBA{}, ErrInvalidColor
+	}
+
+	raw, err := hex.DecodeString(value)
+	if err != nil {
+		return color.NRGBA{}, ErrInvalidColor
+	}
+
+	if len(raw) == 3 {
+		raw = append(raw, 0xff)
+	}
+
+	return color.NRGBA{R: raw[0], G: raw[1], B: raw[2], A: raw[3]}, nil
+}
+
+func svgFill(c color.NRGBA) string {
+	fill := fmt.Sprintf(`fill="#%02x%02x%02x"`, c.R, c.G, c.B)
+	if c.A != 0xff {
+		fill += ` fill-opacity="` + svgNumber(float64(c.A)/0xff) + `"`
+	}
+
+	return fill
+}
+
+func svgNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// cacheKey covers everything that changes the rendered bytes, after the tag.
+func cacheKey(tag, content string, opts Options) string {
+	sum := sha256.Sum256(fmt.Appendf(nil, "%s\x00%s\x00%s\x00%s\x00%s\x00%d\x00%d\x00%t",
+		content, opts.Format, opts.Level,
+		strings.ToLower(strings.TrimPrefix(opts.Foreground, "#")),
+		strings.ToLower(strings.TrimPrefix(opts.Background, "#")),
+		opts.Size, opts.Margin, opts.Logo,
+	))
+
+	return tag + ":" + hex.EncodeToString(sum[:])
+}
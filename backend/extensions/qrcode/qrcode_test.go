@@ -0,0 +1,159 @@
+package qrcode_test
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"lnk/extensions/qrcode"
+
+	"github.com/stretchr/testify/require"
+)
+
+type mapCache map[string][]byte
+
+func (c mapCache) Get(_ context.Context, key string) ([]byte, error) {
+	return c[key], nil
+}
+
+func (c mapCache) Set(_ context.Context, key string, data []byte) error {
+	c[key] = data
+	return nil
+}
+
+func (c mapCache) Evict(_ context.Context, tag string) error {
+	for key := range c {
+		if strings.HasPrefix(key, tag+":") {
+			delete(c, key)
+		}
+	}
+
+	return nil
+}
+
+func (c mapCache) EvictAll(_ context.Context) error {
+	clear(c)
+	return nil
+}
+
+func logoPNG(t *testing.T) []byte {
+	t.Helper()
+
+	logo := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	for i := range logo.Pix {
+		logo.Pix[i] = 0xff
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, logo))
+
+	return buf.Bytes()
+}
+
+func Test_Renderer_PNG(t *testing.T) {
+	t.Parallel()
+
+	renderer, err := qrcode.NewRenderer(qrcode.NewRendererParams{})
+	require.NoError(t, err)
+
+	opts := qrcode.DefaultOptions()
+	opts.Size = 290
+	opts.Foreground = "#ff0000"
+
+	img, err := renderer.Render(context.Background(), "abc123", "https://lnk.example/abc123", opts)
+	require.NoError(t, err)
+	require.Equal(t, "image/png", img.ContentType)
+
+	decoded, err := png.Decode(bytes.NewReader(img.Data))
+	require.NoError(t, err)
+	require.Equal(t, 290, decoded.Bounds().Dx())
+
+	// The quiet zone is background; the finder pattern starts right after it.
+	require.Equal(t, color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, color.NRGBAModel.Convert(decoded.At(1, 1)))
+	require.Equal(t, color.NRGBA{R: 0xff, A: 0xff}, color.NRGBAModel.Convert(decoded.At(40, 40)))
+}
+
+func Test_Renderer_SVGWithLogo(t *testing.T) {
+	t.Parallel()
+
+	renderer, err := qrcode.NewRenderer(qrcode.NewRendererParams{Logo: bytes.NewReader(logoPNG(t))})
+	require.NoError(t, err)
+
+	opts := qrcode.DefaultOptions()
+	opts.Format = qrcode.FormatSVG
+	opts.Background = "ffffff00"
+	opts.Logo = true
+
+	img, err := renderer.Render(context.Background(), "abc123", "https://lnk.example/abc123", opts)
+	require.NoError(t, err)
+	require.Equal(t, "image/svg+xml", img.ContentType)
+
+	svg := string(img.Data)
+	require.True(t, strings.HasPrefix(svg, "<svg "))
+	require.Contains(t, svg, `fill-opacity="0"`)
+	require.Contains(t, svg, "data:image/png;base64,")
+}
+
+func Test_Renderer_Cache(t *testing.T) {
+	t.Parallel()
+
+	cache := mapCache{}
+	renderer, err := qrcode.NewRenderer(qrcode.NewRendererParams{Cache: cache})
+	require.NoError(t, err)
+
+	first, err := renderer.Render(context.Background(), "abc123", "https://lnk.example/abc123", qrcode.DefaultOptions())
+	require.NoError(t, err)
+	require.Len(t, cache, 1)
+
+	for key := range cache {
+		cache[key] = []byte("cached")
+	}
+
+	second, err := renderer.Render(context.Background(), "abc123", "https://lnk.example/abc123", qrcode.DefaultOptions())
+	require.NoError(t, err)
+	require.NotEqual(t, first.Data, second.Data)
+	require.Equal(t, []byte("cached"), second.Data)
+
+	_, err = renderer.Render(context.Background(), "xyz789", "https://lnk.example/xyz789", qrcode.DefaultOptions())
+	require.NoError(t, err)
+	require.Len(t, cache, 2)
+
+	require.NoError(t, renderer.Purge(context.Background(), "abc123"))
+	require.Len(t, cache, 1)
+
+	third, err := renderer.Render(context.Background(), "abc123", "https://lnk.example/abc123", qrcode.DefaultOptions())
+	require.NoError(t, err)
+	require.Equal(t, first.Data, third.Data)
+
+	require.NoError(t, renderer.PurgeAll(context.Background()))
+	require.Empty(t, cache)
+}
+
+func Test_Renderer_Validate(t *testing.T) {
+	t.Parallel()
+
+	renderer, err := qrcode.NewRenderer(qrcode.NewRendererParams{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		want   error
+		modify func(*qrcode.Options)
+	}{
+		{qrcode.ErrInvalidFormat, func(o *qrcode.Options) { o.Format = "gif" }},
+		{qrcode.ErrInvalidSize, func(o *qrcode.Options) { o.Size = 10 }},
+		{qrcode.ErrInvalidMargin, func(o *qrcode.Options) { o.Margin = -1 }},
+		{qrcode.ErrInvalidLevel, func(o *qrcode.Options) { o.Level = "X" }},
+		{qrcode.ErrInvalidColor, func(o *qrcode.Options) { o.Foreground = "red" }},
+		{qrcode.ErrNoLogo, func(o *qrcode.Options) { o.Logo = true }},
+	}
+
+	for _, test := range tests {
+		opts := qrcode.DefaultOptions()
+		test.modify(&opts)
+		require.ErrorIs(t, renderer.Validate(opts), test.want)
+	}
+}
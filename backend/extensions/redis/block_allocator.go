@@ -0,0 +1,242 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// prefetchDivisor triggers the next lease once a fifth of the current
+	// block is left.
+	prefetchDivisor    = 5
+	prefetchRetryDelay = time.Second
+	leaseTimeout       = 5 * time.Second
+)
+
+var ErrAllocatorClosed = errors.New("id allocator is closed")
+
+// lease is an inclusive range of counter values owned by this replica.
+type lease struct {
+	next int64
+	end  int64
+}
+
+func (l lease) remaining() int64 {
+	return l.end - l.next + 1
+}
+
+// BlockAllocator hands out counter values from ranges leased from Redis with
+// a single INCRBY, so creating a link does not need a Redis round trip. The
+// next range is fetched in the background before the current one runs out,
+// which also lets creation continue through short Redis outages.
+//
+// The counter only ever moves forward, because counter reconciliation treats
+// a decrease as data loss. Values left in a range at shutdown are therefore
+// abandoned rather than handed back, and logged by Close.
+type BlockAllocator struct {
+	retryAfter time.Time
+	redis      Redis
+	// leasing is closed when the lease in flight, if any, completes.
+	leasing   chan struct{}
+	logger    *zap.Logger
+	pending   *lease
+	cancel    context.CancelFunc
+	bgCtx     context.Context //nolint:containedctx // scopes background prefetches to the allocator lifetime
+	key       string
+	current   lease
+	wg        sync.WaitGroup
+	blockSize int64
+	refillAt  int64
+	mu        sync.Mutex
+	closed    bool
+}
+
+type NewBlockAllocatorParams struct {
+	Redis     Redis
+	Logger    *zap.Logger
+	Key       string
+	BlockSize int64
+}
+
+func NewBlockAllocator(params NewBlockAllocatorParams) *BlockAllocator {
+	blockSize := max(params.BlockSize, 1)
+
+	bgCtx, cancel := context.WithCancel(context.Background())
+
+	return &BlockAllocator{
+		redis:     params.Redis,
+		logger:    params.Logger,
+		key:       params.Key,
+		blockSize: blockSize,
+		refillAt:  blockSize / prefetchDivisor,
+		current:   lease{next: 1, end: 0},
+		bgCtx:     bgCtx,
+		cancel:    cancel,
+	}
+}
+
+// Next returns the next counter value, leasing a new range when needed. The
+// lock is not held while Redis is called, and waiting for another caller's
+// lease ends when ctx is done.
+func (a *BlockAllocator) Next(ctx context.Context) (int64, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for {
+		if a.closed {
+			return 0, ErrAllocatorClosed
+		}
+
+		if a.current.remaining() > 0 {
+			id := a.current.next
+			a.current.next++
+
+			a.maybePrefetch()
+
+			return id, nil
+		}
+
+		if a.pending != nil {
+			a.current = *a.pending
+			a.pending = nil
+
+			continue
+		}
+
+		// Wait for an in-flight lease instead of leasing a second block.
+		if done := a.leasing; done != nil {
+			a.mu.Unlock()
+
+			select {
+			case <-done:
+			case <-ctx.Done():
+				a.mu.Lock()
+				return 0, ctx.Err()
+			}
+
+			a.mu.Lock()
+
+			continue
+		}
+
+		next, err := a.leaseUnlocked(ctx)
+		if err != nil {
+			return 0, err
+		}
+
+		if a.closed {
+			a.abandon(next)
+			return 0, ErrAllocatorClosed
+		}
+
+		a.current = next
+	}
+}
+
+// leaseUnlocked leases a block with mu released, marking the lease as in
+// flight so other callers wait for it. It must be called with mu held.
+func (a *BlockAllocator) leaseUnlocked(ctx context.Context) (lease, error) {
+	done := make(chan struct{})
+	a.leasing = done
+
+	a.mu.Unlock()
+	next, err := a.lease(ctx)
+	a.mu.Lock()
+
+	a.leasing = nil
+	close(done)
+
+	return next, err
+}
+
+// Close stops background prefetching and logs the values that were leased
+// but never handed out.
+func (a *BlockAllocator) Close() {
+	a.mu.Lock()
+	a.closed = true
+	a.cancel()
+	a.mu.Unlock()
+
+	a.wg.Wait()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.abandon(a.current)
+
+	if a.pending != nil {
+		a.abandon(*a.pending)
+		a.pending = nil
+	}
+}
+
+// maybePrefetch must be called with mu held.
+func (a *BlockAllocator) maybePrefetch() {
+	if a.refillAt == 0 || a.leasing != nil || a.pending != nil {
+		return
+	}
+
+	if a.current.remaining() > a.refillAt || time.Now().Before(a.retryAfter) {
+		return
+	}
+
+	a.leasing = make(chan struct{})
+	a.wg.Add(1)
+
+	go a.prefetch(a.leasing)
+}
+
+func (a *BlockAllocator) prefetch(done chan struct{}) {
+	defer a.wg.Done()
+
+	ctx, cancel := context.WithTimeout(a.bgCtx, leaseTimeout)
+	defer cancel()
+
+	next, err := a.lease(ctx)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.leasing = nil
+	close(done)
+
+	if err != nil {
+		a.retryAfter = time.Now().Add(prefetchRetryDelay)
+		a.logger.Warn("Failed to prefetch counter block", zap.String("key", a.key), zap.Error(err))
+
+		return
+	}
+
+	if a.closed {
+		a.abandon(next)
+		return
+	}
+
+	a.pending = &next
+}
+
+func (a *BlockAllocator) lease(ctx context.Context) (lease, error) {
+	end, err := a.redis.IncrBy(ctx, a.key, a.blockSize)
+	if err != nil {
+		return lease{}, fmt.Errorf("failed to lease counter block: %w", err)
+	}
+
+	return lease{next: end - a.blockSize + 1, end: end}, nil
+}
+
+func (a *BlockAllocator) abandon(l lease) {
+	if l.remaining() <= 0 {
+		return
+	}
+
+	a.logger.Info("Abandoning unused counter block",
+		zap.String("key", a.key),
+		zap.Int64("from", l.next),
+		zap.Int64("to", l.end),
+	)
+}
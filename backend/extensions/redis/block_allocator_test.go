@@ -0,0 +1,131 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	redisPackage "lnk/extensions/redis"
+	"lnk/extensions/redis/mocks"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func Test_BlockAllocator_Next(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	mockRedis := mocks.NewMockRedis(t)
+	mockRedis.On("IncrBy", mock.Anything, "counter", int64(10)).Return(int64(10), nil).Once()
+	mockRedis.On("IncrBy", mock.Anything, "counter", int64(10)).Return(int64(20), nil).Once()
+	mockRedis.On("IncrBy", mock.Anything, "counter", int64(10)).Return(int64(30), nil).Maybe()
+
+	allocator := redisPackage.NewBlockAllocator(redisPackage.NewBlockAllocatorParams{
+		Redis:     mockRedis,
+		Logger:    zap.NewNop(),
+		Key:       "counter",
+		BlockSize: 10,
+	})
+	defer allocator.Close()
+
+	for want := int64(1); want <= 20; want++ {
+		got, err := allocator.Next(ctx)
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+	}
+}
+
+func Test_BlockAllocator_SurvivesOutageWithPrefetchedBlock(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	mockRedis := mocks.NewMockRedis(t)
+	mockRedis.On("IncrBy", mock.Anything, "counter", int64(5)).Return(int64(5), nil).Once()
+	mockRedis.On("IncrBy", mock.Anything, "counter", int64(5)).Return(int64(10), nil).Once()
+	mockRedis.On("IncrBy", mock.Anything, "counter", int64(5)).Return(int64(0), errors.New("connection refused"))
+
+	allocator := redisPackage.NewBlockAllocator(redisPackage.NewBlockAllocatorParams{
+		Redis:     mockRedis,
+		Logger:    zap.NewNop(),
+		Key:       "counter",
+		BlockSize: 5,
+	})
+	defer allocator.Close()
+
+	for want := int64(1); want <= 5; want++ {
+		got, err := allocator.Next(ctx)
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+
+		// Give the background prefetch time to land before the block runs out.
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	for want := int64(6); want <= 10; want++ {
+		got, err := allocator.Next(ctx)
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+	}
+
+	_, err := allocator.Next(ctx)
+	require.Error(t, err)
+}
+
+func Test_BlockAllocator_Closed(t *testing.T) {
+	t.Parallel()
+
+	allocator := redisPackage.NewBlockAllocator(redisPackage.NewBlockAllocatorParams{
+		Redis:     mocks.NewMockRedis(t),
+		Logger:    zap.NewNop(),
+		Key:       "counter",
+		BlockSize: 5,
+	})
+	allocator.Close()
+
+	_, err := allocator.Next(context.Background())
+	require.ErrorIs(t, err, redisPackage.ErrAllocatorClosed)
+}
+
+func Test_BlockAllocator_WaitEndsWithContext(t *testing.T) {
+	t.Parallel()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	mockRedis := mocks.NewMockRedis(t)
+	mockRedis.On("IncrBy", mock.Anything, "counter", int64(1)).Return(func(context.Context, string, int64) (int64, error) {
+		close(started)
+		<-release
+
+		return 1, nil
+	}, nil).Once()
+
+	allocator := redisPackage.NewBlockAllocator(redisPackage.NewBlockAllocatorParams{
+		Redis:     mockRedis,
+		Logger:    zap.NewNop(),
+		Key:       "counter",
+		BlockSize: 1,
+	})
+	defer allocator.Close()
+
+	leased := make(chan int64)
+
+	go func() {
+		id, _ := allocator.Next(context.Background())
+		leased <- id
+	}()
+
+	<-started
+
+	// A caller waiting behind the slow lease gives up with its context.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := allocator.Next(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(release)
+	require.Equal(t, int64(1), <-leased)
+}
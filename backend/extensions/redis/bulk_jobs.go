@@ -0,0 +1,134 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"lnk/domain/entities"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// bulkJobRecord is the stored form of entities.BulkJob. Item errors keep
+// only their client-safe code and message.
+type bulkJobRecord struct {
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+	ID        string             `json:"id"`
+	OwnerID   string             `json:"owner_id,omitempty"`
+	Domain    string             `json:"domain"`
+	Status    string             `json:"status"`
+	Results   []bulkResultRecord `json:"results,omitempty"`
+	Total     int                `json:"total"`
+	Completed int                `json:"completed"`
+	Failed    int                `json:"failed"`
+}
+
+type bulkResultRecord struct {
+	ShortCode    string `json:"s,omitempty"`
+	LongURL      string `json:"u"`
+	ErrorCode    string `json:"c,omitempty"`
+	ErrorMessage string `json:"m,omitempty"`
+	Index        int    `json:"i"`
+}
+
+// BulkJobStore keeps bulk jobs as JSON documents that expire after a TTL.
+type BulkJobStore struct {
+	client redis.UniversalClient
+	ttl    time.Duration
+}
+
+type NewBulkJobStoreParams struct {
+	Client redis.UniversalClient
+	TTL    time.Duration
+}
+
+func NewBulkJobStore(params NewBulkJobStoreParams) *BulkJobStore {
+	return &BulkJobStore{client: params.Client, ttl: params.TTL}
+}
+
+func (s *BulkJobStore) Save(ctx context.Context, job *entities.BulkJob) error {
+	record := bulkJobRecord{
+		ID:        job.ID,
+		OwnerID:   job.OwnerID,
+		Domain:    job.Domain,
+		Status:    string(job.Status),
+		Total:     job.Total,
+		Completed: job.Completed,
+		Failed:    job.Failed,
+		CreatedAt: job.CreatedAt,
+		UpdatedAt: job.UpdatedAt,
+	}
+
+	if len(job.Results) > 0 {
+		record.Results = make([]bulkResultRecord, len(job.Results))
+	}
+
+	for i, result := range job.Results {
+		record.Results[i] = bulkResultRecord{Index: result.Index, ShortCode: result.ShortCode, LongURL: result.LongURL}
+		if result.Error != nil {
+			record.Results[i].ErrorCode = string(result.Error.Code)
+			record.Results[i].ErrorMessage = result.Error.Message
+		}
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode bulk job: %w", err)
+	}
+
+	if err := s.client.Set(ctx, bulkJobKey(job.ID), data, s.ttl).Err(); err != nil {
+		return fmt.Errorf("failed to save bulk job %s: %w", job.ID, err)
+	}
+
+	return nil
+}
+
+// Get returns ErrKeyNotFound for unknown or expired jobs.
+func (s *BulkJobStore) Get(ctx context.Context, id string) (*entities.BulkJob, error) {
+	data, err := s.client.Get(ctx, bulkJobKey(id)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrKeyNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bulk job %s: %w", id, err)
+	}
+
+	var record bulkJobRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("failed to decode bulk job %s: %w", id, err)
+	}
+
+	job := &entities.BulkJob{
+		ID:        record.ID,
+		OwnerID:   record.OwnerID,
+		Domain:    record.Domain,
+		Status:    entities.BulkJobStatus(record.Status),
+		Total:     record.Total,
+		Completed: record.Completed,
+		Failed:    record.Failed,
+		CreatedAt: record.CreatedAt,
+		UpdatedAt: record.UpdatedAt,
+	}
+
+	if len(record.Results) > 0 {
+		job.Results = make([]entities.BulkItemResult, len(record.Results))
+	}
+
+	for i, result := range record.Results {
+		job.Results[i] = entities.BulkItemResult{Index: result.Index, ShortCode: result.ShortCode, LongURL: result.LongURL}
+		if result.ErrorCode != "" {
+			job.Results[i].Error = entities.NewError(entities.ErrorCode(result.ErrorCode), result.ErrorMessage)
+		}
+	}
+
+	return job, nil
+}
+
+func bulkJobKey(id string) string {
+	return "bulk_job:" + id
+}
@@ -0,0 +1,179 @@
+package redis
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/redis/go-redis/v9/maintnotifications"
+)
+
+const (
+	ModeStandalone = "standalone"
+	ModeSentinel   = "sentinel"
+	ModeCluster    = "cluster"
+)
+
+// newClient builds a standalone, Sentinel or Cluster client from config.
+// REDIS_URL, when set, provides the addresses, credentials, DB and TLS
+// settings; pool tuning always comes from the individual variables.
+func newClient(config *Config) (redis.UniversalClient, error) {
+	options, err := universalOptions(config)
+	if err != nil {
+		return nil, err
+	}
+
+	switch config.Mode {
+	case "", ModeStandalone:
+		return redis.NewClient(options.Simple()), nil
+	case ModeSentinel:
+		if options.MasterName == "" {
+			return nil, errors.New("sentinel mode requires REDIS_MASTER_NAME")
+		}
+
+		return redis.NewFailoverClient(options.Failover()), nil
+	case ModeCluster:
+		return redis.NewClusterClient(options.Cluster()), nil
+	default:
+		return nil, fmt.Errorf("unknown Redis mode %q", config.Mode)
+	}
+}
+
+func universalOptions(config *Config) (*redis.UniversalOptions, error) {
+	options := &redis.UniversalOptions{
+		Addrs:            addrs(config),
+		Username:         config.Username,
+		Password:         config.Password,
+		DB:               config.DB,
+		MasterName:       config.MasterName,
+		SentinelUsername: config.SentinelUsername,
+		SentinelPassword: config.SentinelPassword,
+		MaxRetries:       config.MaxRetries,
+		DialTimeout:      config.DialTimeout,
+		ReadTimeout:      config.ReadTimeout,
+		WriteTimeout:     config.WriteTimeout,
+		PoolSize:         config.PoolSize,
+		PoolTimeout:      config.PoolTimeout,
+		MinIdleConns:     config.MinIdleConns,
+		MaxIdleConns:     config.MaxIdleConns,
+		MaxActiveConns:   config.MaxActiveConns,
+		ConnMaxIdleTime:  config.ConnMaxIdleTime,
+		ConnMaxLifetime:  config.ConnMaxLifetime,
+		MaintNotificationsConfig: &maintnotifications.Config{
+			Mode: maintnotifications.ModeDisabled,
+		},
+	}
+
+	if config.URL != "" {
+		if err := applyURL(options, config); err != nil {
+			return nil, fmt.Errorf("invalid REDIS_URL: %w", err)
+		}
+	}
+
+	if len(options.Addrs) == 0 {
+		return nil, errors.New("one of REDIS_URL, REDIS_ADDRS or REDIS_HOST is required")
+	}
+
+	if config.TLSEnabled {
+		tlsConfig, err := newTLSConfig(config)
+		if err != nil {
+			return nil, err
+		}
+
+		options.TLSConfig = tlsConfig
+	}
+
+	return options, nil
+}
+
+func addrs(config *Config) []string {
+	if len(config.Addrs) > 0 {
+		return config.Addrs
+	}
+
+	if config.Host == "" {
+		return nil
+	}
+
+	return []string{net.JoinHostPort(config.Host, strconv.Itoa(config.Port))}
+}
+
+func applyURL(options *redis.UniversalOptions, config *Config) error {
+	switch config.Mode {
+	case ModeCluster:
+		parsed, err := redis.ParseClusterURL(config.URL)
+		if err != nil {
+			return err
+		}
+
+		options.Addrs = parsed.Addrs
+		options.Username = parsed.Username
+		options.Password = parsed.Password
+		options.TLSConfig = parsed.TLSConfig
+	case ModeSentinel:
+		parsed, err := redis.ParseFailoverURL(config.URL)
+		if err != nil {
+			return err
+		}
+
+		options.Addrs = parsed.SentinelAddrs
+		if parsed.MasterName != "" {
+			options.MasterName = parsed.MasterName
+		}
+		options.Username = parsed.Username
+		options.Password = parsed.Password
+		options.DB = parsed.DB
+		options.TLSConfig = parsed.TLSConfig
+	default:
+		parsed, err := redis.ParseURL(config.URL)
+		if err != nil {
+			return err
+		}
+
+		options.Addrs = []string{parsed.Addr}
+		options.Username = parsed.Username
+		options.Password = parsed.Password
+		options.DB = parsed.DB
+		options.TLSConfig = parsed.TLSConfig
+	}
+
+	return nil
+}
+
+func newTLSConfig(config *Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         config.TLSServerName,
+		InsecureSkipVerify: config.TLSInsecureSkipVerify, //nolint:gosec // opt-in for self-signed test setups
+	}
+
+	if config.TLSCAPath != "" {
+		caPEM, err := os.ReadFile(config.TLSCAPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Redis CA: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in %s", config.TLSCAPath)
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	if config.TLSCertPath != "" || config.TLSKeyPath != "" {
+		cert, err := tls.LoadX509KeyPair(config.TLSCertPath, config.TLSKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load Redis client certificate: %w", err)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
@@ -1,10 +1,54 @@
 package redis
 
+import "time"
+
 type Config struct {
-	Host            string `envconfig:"REDIS_HOST" required:"true"`
-	Password        string `envconfig:"REDIS_PASSWORD" required:"true"`
-	CounterKey      string `envconfig:"COUNTER_KEY" required:"true"`
-	Port            int    `envconfig:"REDIS_PORT" required:"true"`
-	DB              int    `envconfig:"REDIS_DB" required:"true"`
-	CounterStartVal int    `envconfig:"COUNTER_START_VAL" required:"true"`
+	Mode                      string        `envconfig:"REDIS_MODE" default:"standalone"`
+	URL                       string        `envconfig:"REDIS_URL"`
+	Host                      string        `envconfig:"REDIS_HOST"`
+	Username                  string        `envconfig:"REDIS_USERNAME"`
+	Password                  string        `envconfig:"REDIS_PASSWORD"`
+	MasterName                string        `envconfig:"REDIS_MASTER_NAME"`
+	SentinelUsername          string        `envconfig:"REDIS_SENTINEL_USERNAME"`
+	SentinelPassword          string        `envconfig:"REDIS_SENTINEL_PASSWORD"`
+	TLSCAPath                 string        `envconfig:"REDIS_TLS_CA_PATH"`
+	TLSCertPath               string        `envconfig:"REDIS_TLS_CERT_PATH"`
+	TLSKeyPath                string        `envconfig:"REDIS_TLS_KEY_PATH"`
+	TLSServerName             string        `envconfig:"REDIS_TLS_SERVER_NAME"`
+	CounterKey                string        `envconfig:"COUNTER_KEY" required:"true"`
+	CounterHashTag            string        `envconfig:"COUNTER_HASH_TAG"`
+	Addrs                     []string      `envconfig:"REDIS_ADDRS"`
+	Port                      int           `envconfig:"REDIS_PORT" default:"6379"`
+	DB                        int           `envconfig:"REDIS_DB" default:"0"`
+	PoolSize                  int           `envconfig:"REDIS_POOL_SIZE"`
+	MinIdleConns              int           `envconfig:"REDIS_MIN_IDLE_CONNS"`
+	MaxIdleConns              int           `envconfig:"REDIS_MAX_IDLE_CONNS"`
+	MaxActiveConns            int           `envconfig:"REDIS_MAX_ACTIVE_CONNS"`
+	MaxRetries                int           `envconfig:"REDIS_MAX_RETRIES" default:"3"`
+	PoolTimeout               time.Duration `envconfig:"REDIS_POOL_TIMEOUT"`
+	ConnMaxIdleTime           time.Duration `envconfig:"REDIS_CONN_MAX_IDLE_TIME"`
+	ConnMaxLifetime           time.Duration `envconfig:"REDIS_CONN_MAX_LIFETIME"`
+	DialTimeout               time.Duration `envconfig:"REDIS_DIAL_TIMEOUT" default:"5s"`
+	ReadTimeout               time.Duration `envconfig:"REDIS_READ_TIMEOUT" default:"3s"`
+	WriteTimeout              time.Duration `envconfig:"REDIS_WRITE_TIMEOUT" default:"3s"`
+	CounterStartVal           int           `envconfig:"COUNTER_START_VAL" required:"true"`
+	CounterHeadroom           int64         `envconfig:"COUNTER_HEADROOM" default:"100000"`
+	CounterBlockSize          int64         `envconfig:"COUNTER_BLOCK_SIZE" default:"1000"`
+	CounterCheckpointInterval time.Duration `envconfig:"COUNTER_CHECKPOINT_INTERVAL" default:"30s"`
+	// IdempotencyTTL is how long responses are replayed for a repeated
+	// Idempotency-Key; IdempotencyLockTTL bounds how long a crashed request
+	// can block retries with the same key.
+	IdempotencyTTL     time.Duration `envconfig:"IDEMPOTENCY_TTL" default:"24h"`
+	IdempotencyLockTTL time.Duration `envconfig:"IDEMPOTENCY_LOCK_TTL" default:"30s"`
+	// BulkJobTTL is how long finished bulk jobs and their results can be
+	// polled.
+	BulkJobTTL            time.Duration `envconfig:"BULK_JOB_TTL" default:"24h"`
+	QRCacheTTL            time.Duration `envconfig:"QR_CACHE_TTL" default:"168h"`
+	TLSEnabled            bool          `envconfig:"REDIS_TLS_ENABLED" default:"false"`
+	TLSInsecureSkipVerify bool          `envconfig:"REDIS_TLS_INSECURE_SKIP_VERIFY" default:"false"`
+}
+
+// CounterRedisKey is the counter key wrapped in its hash tag.
+func (c *Config) CounterRedisKey() string {
+	return Key(c.CounterHashTag, c.CounterKey)
 }
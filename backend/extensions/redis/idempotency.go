@@ -0,0 +1,197 @@
+package redis
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// ErrIdempotencyInProgress is returned while another request holding the
+	// same key has not completed yet.
+	ErrIdempotencyInProgress = errors.New("a request with this idempotency key is in progress")
+	// ErrIdempotencyMismatch is returned when a key is reused for a request
+	// with a different fingerprint.
+	ErrIdempotencyMismatch = errors.New("idempotency key was used for a different request")
+)
+
+// beginIdempotencyScript claims KEYS[1] for the request fingerprint ARGV[1]
+// with the lock token ARGV[2] for ARGV[3] milliseconds. When the key already
+// exists it returns the stored fields instead, so the caller can replay or
+// reject the request.
+var beginIdempotencyScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 1 then
+	return redis.call("HGETALL", KEYS[1])
+end
+redis.call("HSET", KEYS[1], "fingerprint", ARGV[1], "token", ARGV[2])
+redis.call("PEXPIRE", KEYS[1], ARGV[3])
+return false
+`)
+
+// completeIdempotencyScript stores the response if the lock token ARGV[1]
+// still owns KEYS[1], keeping it for ARGV[5] milliseconds.
+var completeIdempotencyScript = redis.NewScript(`
+if redis.call("HGET", KEYS[1], "token") ~= ARGV[1] then
+	return 0
+end
+redis.call("HSET", KEYS[1], "status", ARGV[2], "content_type", ARGV[3], "body", ARGV[4])
+redis.call("HDEL", KEYS[1], "token")
+redis.call("PEXPIRE", KEYS[1], ARGV[5])
+return 1
+`)
+
+// releaseIdempotencyScript deletes KEYS[1] if the lock token ARGV[1] still
+// owns it, so a failed request can be retried with the same key.
+var releaseIdempotencyScript = redis.NewScript(`
+if redis.call("HGET", KEYS[1], "token") == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// extendIdempotencyScript renews the lock on KEYS[1] for ARGV[2] milliseconds
+// if the lock token ARGV[1] still owns it.
+var extendIdempotencyScript = redis.NewScript(`
+if redis.call("HGET", KEYS[1], "token") == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// IdempotentResponse is a stored response replayed for retried requests.
+type IdempotentResponse struct {
+	ContentType string
+	Body        []byte
+	Status      int
+}
+
+// IdempotencyStore remembers the first response for each caller and
+// Idempotency-Key. A request first claims the key with a short-lived lock,
+// then either completes it with its response or releases it on failure.
+type IdempotencyStore struct {
+	client  redis.UniversalClient
+	ttl     time.Duration
+	lockTTL time.Duration
+}
+
+type NewIdempotencyStoreParams struct {
+	Client  redis.UniversalClient
+	TTL     time.Duration
+	LockTTL time.Duration
+}
+
+func NewIdempotencyStore(params NewIdempotencyStoreParams) *IdempotencyStore {
+	return &IdempotencyStore{
+		client:  params.Client,
+		ttl:     params.TTL,
+		lockTTL: params.LockTTL,
+	}
+}
+
+// Begin claims key for a request with the given fingerprint. It returns a
+// lock token when the request should run, or the stored response when it is
+// a replay. ErrIdempotencyInProgress and ErrIdempotencyMismatch report
+// concurrent and conflicting reuse of the key.
+func (s *IdempotencyStore) Begin(ctx context.Context, caller, key, fingerprint string) (string, *IdempotentResponse, error) {
+	token, err := newLockToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	result, err := beginIdempotencyScript.Run(ctx, s.client,
+		[]string{idempotencyKey(caller, key)},
+		fingerprint, token, s.lockTTL.Milliseconds(),
+	).StringSlice()
+	if errors.Is(err, redis.Nil) {
+		return token, nil, nil
+	}
+
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to claim idempotency key: %w", err)
+	}
+
+	fields := make(map[string]string, len(result)/2)
+	for i := 0; i+1 < len(result); i += 2 {
+		fields[result[i]] = result[i+1]
+	}
+
+	if fields["fingerprint"] != fingerprint {
+		return "", nil, ErrIdempotencyMismatch
+	}
+
+	if _, done := fields["status"]; !done {
+		return "", nil, ErrIdempotencyInProgress
+	}
+
+	status, err := strconv.Atoi(fields["status"])
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid stored idempotent status %q: %w", fields["status"], err)
+	}
+
+	return "", &IdempotentResponse{
+		Status:      status,
+		ContentType: fields["content_type"],
+		Body:        []byte(fields["body"]),
+	}, nil
+}
+
+// Complete stores the response for key if token still holds its lock.
+func (s *IdempotencyStore) Complete(ctx context.Context, caller, key, token string, response *IdempotentResponse) error {
+	err := completeIdempotencyScript.Run(ctx, s.client,
+		[]string{idempotencyKey(caller, key)},
+		token, response.Status, response.ContentType, response.Body, s.ttl.Milliseconds(),
+	).Err()
+	if err != nil {
+		return fmt.Errorf("failed to store idempotent response: %w", err)
+	}
+
+	return nil
+}
+
+// Extend renews the lock on key while the request holding token still runs,
+// so a slow request is not retried alongside itself.
+func (s *IdempotencyStore) Extend(ctx context.Context, caller, key, token string) error {
+	err := extendIdempotencyScript.Run(ctx, s.client,
+		[]string{idempotencyKey(caller, key)},
+		token, s.lockTTL.Milliseconds(),
+	).Err()
+	if err != nil {
+		return fmt.Errorf("failed to extend idempotency lock: %w", err)
+	}
+
+	return nil
+}
+
+// Release drops the lock on key so the request can be retried.
+func (s *IdempotencyStore) Release(ctx context.Context, caller, key, token string) error {
+	err := releaseIdempotencyScript.Run(ctx, s.client, []string{idempotencyKey(caller, key)}, token).Err()
+	if err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// idempotencyKey hashes caller and key so arbitrary client input stays out of
+// the Redis key space.
+func idempotencyKey(caller, key string) string {
+	sum := sha256.Sum256([]byte(caller + "\x00" + key))
+
+	return "idempotency:" + hex.EncodeToString(sum[:])
+}
+
+func newLockToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate idempotency lock token: %w", err)
+	}
+
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,101 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "lnk/extensions/redis"
+
+// otelHook wraps every Redis command and pipeline in a client span and
+// records command latency and errors.
+type otelHook struct {
+	tracer   trace.Tracer
+	duration metric.Float64Histogram
+	errors   metric.Int64Counter
+	attrs    []attribute.KeyValue
+}
+
+func newOTelHook(mode string) *otelHook {
+	meter := otel.Meter(instrumentationName)
+
+	// Instrument creation only fails on invalid names, which are constant here.
+	duration, _ := meter.Float64Histogram(
+		"db.client.operation.duration",
+		metric.WithDescription("Duration of Redis commands"),
+		metric.WithUnit("s"),
+	)
+
+	errorCounter, _ := meter.Int64Counter(
+		"db.client.operation.errors",
+		metric.WithDescription("Number of failed Redis commands"),
+		metric.WithUnit("1"),
+	)
+
+	return &otelHook{
+		tracer:   otel.Tracer(instrumentationName),
+		duration: duration,
+		errors:   errorCounter,
+		attrs: []attribute.KeyValue{
+			semconv.DBSystemNameRedis,
+			attribute.String("db.redis.mode", mode),
+		},
+	}
+}
+
+func (h *otelHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (h *otelHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		return h.observe(ctx, cmd.FullName(), func(ctx context.Context) error {
+			return next(ctx, cmd)
+		})
+	}
+}
+
+func (h *otelHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		return h.observe(ctx, "pipeline", func(ctx context.Context) error {
+			return next(ctx, cmds)
+		})
+	}
+}
+
+func (h *otelHook) observe(ctx context.Context, operation string, run func(context.Context) error) error {
+	attrs := append([]attribute.KeyValue{semconv.DBOperationName(operation)}, h.attrs...)
+
+	ctx, span := h.tracer.Start(ctx, operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	defer span.End()
+
+	start := time.Now()
+	err := run(ctx)
+
+	metricAttrs := metric.WithAttributes(attrs...)
+	h.duration.Record(ctx, time.Since(start).Seconds(), metricAttrs)
+
+	// A missing key is a normal result, not a failure.
+	if err != nil && !errors.Is(err, redis.Nil) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		h.errors.Add(ctx, 1, metricAttrs)
+	}
+
+	return err
+}
@@ -0,0 +1,12 @@
+package redis
+
+// Key prefixes name with a Redis Cluster hash tag. Only the tag is hashed, so
+// keys sharing a tag land on the same slot and can be used together in
+// scripts and transactions. An empty tag leaves name untouched.
+func Key(tag, name string) string {
+	if tag == "" {
+		return name
+	}
+
+	return "{" + tag + "}:" + name
+}
@@ -0,0 +1,31 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Lease lets one process at a time run a periodic job across replicas. It is
+// taken with SET NX PX and never released early, so it also spaces the runs.
+type Lease struct {
+	client redis.UniversalClient
+	key    string
+}
+
+func NewLease(client redis.UniversalClient, name string) *Lease {
+	return &Lease{client: client, key: "lease:" + name}
+}
+
+// Acquire takes the lease for ttl unless it is held, and reports whether it
+// was taken.
+func (l *Lease) Acquire(ctx context.Context, ttl time.Duration) (bool, error) {
+	acquired, err := l.client.SetNX(ctx, l.key, time.Now().UTC().Format(time.RFC3339), ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire lease %s: %w", l.key, err)
+	}
+
+	return acquired, nil
+}
@@ -13,6 +13,34 @@ type MockRedis struct {
 	mock.Mock
 }
 
+// GetInt provides a mock function with given fields: ctx, key
+func (_m *MockRedis) GetInt(ctx context.Context, key string) (int64, error) {
+	_ret := _m.Called(ctx, key)
+
+	if len(_ret) == 0 {
+		panic("no return value specified for GetInt")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := _ret.Get(0).(func(context.Context, string) (int64, error)); ok {
+		return rf(ctx, key)
+	}
+	if rf, ok := _ret.Get(0).(func(context.Context, string) int64); ok {
+		r0 = rf(ctx, key)
+	} else {
+		r0 = _ret.Get(0).(int64)
+	}
+
+	if rf, ok := _ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, key)
+	} else {
+		r1 = _ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Incr provides a mock function with given fields: ctx, key
 func (_m *MockRedis) Incr(ctx context.Context, key string) (int64, error) {
 	_ret := _m.Called(ctx, key)
@@ -41,6 +69,62 @@ func (_m *MockRedis) Incr(ctx context.Context, key string) (int64, error) {
 	return r0, r1
 }
 
+// IncrBy provides a mock function with given fields: ctx, key, value
+func (_m *MockRedis) IncrBy(ctx context.Context, key string, value int64) (int64, error) {
+	_ret := _m.Called(ctx, key, value)
+
+	if len(_ret) == 0 {
+		panic("no return value specified for IncrBy")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := _ret.Get(0).(func(context.Context, string, int64) (int64, error)); ok {
+		return rf(ctx, key, value)
+	}
+	if rf, ok := _ret.Get(0).(func(context.Context, string, int64) int64); ok {
+		r0 = rf(ctx, key, value)
+	} else {
+		r0 = _ret.Get(0).(int64)
+	}
+
+	if rf, ok := _ret.Get(1).(func(context.Context, string, int64) error); ok {
+		r1 = rf(ctx, key, value)
+	} else {
+		r1 = _ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetIfGreater provides a mock function with given fields: ctx, key, value
+func (_m *MockRedis) SetIfGreater(ctx context.Context, key string, value int64) (bool, error) {
+	_ret := _m.Called(ctx, key, value)
+
+	if len(_ret) == 0 {
+		panic("no return value specified for SetIfGreater")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := _ret.Get(0).(func(context.Context, string, int64) (bool, error)); ok {
+		return rf(ctx, key, value)
+	}
+	if rf, ok := _ret.Get(0).(func(context.Context, string, int64) bool); ok {
+		r0 = rf(ctx, key, value)
+	} else {
+		r0 = _ret.Get(0).(bool)
+	}
+
+	if rf, ok := _ret.Get(1).(func(context.Context, string, int64) error); ok {
+		r1 = rf(ctx, key, value)
+	} else {
+		r1 = _ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewMockRedis creates a new instance of MockRedis. It also registers a testing interface on the mock and a cleanup function to assert the mock's expectations.
 func NewMockRedis(t interface {
 	mock.TestingT
@@ -0,0 +1,131 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// QRCache keeps rendered QR codes so repeated requests skip rendering.
+type QRCache struct {
+	client redis.UniversalClient
+	ttl    time.Duration
+}
+
+func NewQRCache(client redis.UniversalClient, ttl time.Duration) *QRCache {
+	return &QRCache{client: client, ttl: ttl}
+}
+
+// Get returns nil without an error on a cache miss.
+func (c *QRCache) Get(ctx context.Context, key string) ([]byte, error) {
+	data, err := c.client.Get(ctx, qrCacheKey(key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cached QR code: %w", err)
+	}
+
+	return data, nil
+}
+
+func (c *QRCache) Set(ctx context.Context, key string, data []byte) error {
+	if err := c.client.Set(ctx, qrCacheKey(key), data, c.ttl).Err(); err != nil {
+		return fmt.Errorf("failed to cache QR code: %w", err)
+	}
+
+	return nil
+}
+
+// Evict deletes the cached codes of tag.
+func (c *QRCache) Evict(ctx context.Context, tag string) error {
+	if err := deleteMatching(ctx, c.client, qrCacheKey(escapePattern(tag)+":*")); err != nil {
+		return fmt.Errorf("failed to evict cached QR codes: %w", err)
+	}
+
+	return nil
+}
+
+// EvictAll deletes every cached code.
+func (c *QRCache) EvictAll(ctx context.Context) error {
+	if err := deleteMatching(ctx, c.client, qrCacheKey("*")); err != nil {
+		return fmt.Errorf("failed to evict cached QR codes: %w", err)
+	}
+
+	return nil
+}
+
+// deleteMatching deletes the keys matching pattern with SCAN, on every master
+// of a cluster. Keys are unlinked one at a time, so keys on different slots
+// never share a command.
+func deleteMatching(ctx context.Context, client redis.UniversalClient, pattern string) error {
+	if cluster, ok := client.(*redis.ClusterClient); ok {
+		return cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			return deleteMatchingOn(ctx, node, pattern)
+		})
+	}
+
+	return deleteMatchingOn(ctx, client, pattern)
+}
+
+func deleteMatchingOn(ctx context.Context, client redis.Cmdable, pattern string) error {
+	const batchSize = 500
+
+	keys := make([]string, 0, batchSize)
+
+	unlink := func() error {
+		if len(keys) == 0 {
+			return nil
+		}
+
+		_, err := client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, key := range keys {
+				pipe.Unlink(ctx, key)
+			}
+
+			return nil
+		})
+		keys = keys[:0]
+
+		return err
+	}
+
+	iter := client.Scan(ctx, 0, pattern, batchSize).Iterator()
+	for iter.Next(ctx) {
+		if keys = append(keys, iter.Val()); len(keys) == batchSize {
+			if err := unlink(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := iter.Err(); err != nil {
+		return err
+	}
+
+	return unlink()
+}
+
+// escapePattern escapes the glob characters of a SCAN MATCH pattern.
+func escapePattern(value string) string {
+	var escaped strings.Builder
+
+	for _, r := range value {
+		if strings.ContainsRune(`*?[]^\`, r) {
+			escaped.WriteByte('\\')
+		}
+
+		escaped.WriteRune(r)
+	}
+
+	return escaped.String()
+}
+
+func qrCacheKey(key string) string {
+	return "qr:" + key
+}
@@ -0,0 +1,15 @@
+package redis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_EscapePattern(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "abc123", escapePattern("abc123"))
+	require.Equal(t, `a\*b\?c\[d\]\^e\\f`, escapePattern(`a*b?c[d]^e\f`))
+	require.Equal(t, `qr:a\*:*`, qrCacheKey(escapePattern("a*")+":*"))
+}
@@ -2,24 +2,41 @@ package redis
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/redis/go-redis/v9"
-	"github.com/redis/go-redis/v9/maintnotifications"
 	"go.uber.org/zap"
 )
 
+// ErrKeyNotFound is returned when a key does not exist.
+var ErrKeyNotFound = errors.New("redis key not found")
+
+// setIfGreaterScript raises an integer key to ARGV[1] only when the key is
+// missing or currently holds a smaller value, so it never moves a counter back.
+var setIfGreaterScript = redis.NewScript(`
+local current = redis.call("GET", KEYS[1])
+if current == false or tonumber(current) < tonumber(ARGV[1]) then
+	redis.call("SET", KEYS[1], ARGV[1])
+	return 1
+end
+return 0
+`)
+
 // Redis is an interface for Redis operations.
 // This interface allows for easy mocking in tests.
 type Redis interface {
 	Incr(ctx context.Context, key string) (int64, error)
+	IncrBy(ctx context.Context, key string, value int64) (int64, error)
+	GetInt(ctx context.Context, key string) (int64, error)
+	SetIfGreater(ctx context.Context, key string, value int64) (bool, error)
 }
 
 type redisAdapter struct {
-	client *redis.Client
+	client redis.UniversalClient
 }
 
-func NewRedisAdapter(client *redis.Client) Redis {
+func NewRedisAdapter(client redis.UniversalClient) Redis {
 	return &redisAdapter{client: client}
 }
 
@@ -32,22 +49,59 @@ func (r *redisAdapter) Incr(ctx context.Context, key string) (int64, error) {
 	return result, nil
 }
 
-func SetupRedis(ctx context.Context, config *Config, logger *zap.Logger) (*redis.Client, error) {
-	client := redis.NewClient(&redis.Options{
-		Addr:     fmt.Sprintf("%s:%d", config.Host, config.Port),
-		Password: config.Password,
-		DB:       config.DB,
-		MaintNotificationsConfig: &maintnotifications.Config{
-			Mode: maintnotifications.ModeDisabled,
-		},
-	})
-
-	_, err := client.Ping(ctx).Result()
+func (r *redisAdapter) IncrBy(ctx context.Context, key string, value int64) (int64, error) {
+	result, err := r.client.IncrBy(ctx, key, value).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to increment Redis key %s by %d: %w", key, value, err)
+	}
+
+	return result, nil
+}
+
+func (r *redisAdapter) GetInt(ctx context.Context, key string) (int64, error) {
+	result, err := r.client.Get(ctx, key).Int64()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return 0, ErrKeyNotFound
+		}
+
+		return 0, fmt.Errorf("failed to get Redis key %s: %w", key, err)
+	}
+
+	return result, nil
+}
+
+func (r *redisAdapter) SetIfGreater(ctx context.Context, key string, value int64) (bool, error) {
+	result, err := setIfGreaterScript.Run(ctx, r.client, []string{key}, value).Int()
 	if err != nil {
+		return false, fmt.Errorf("failed to raise Redis key %s: %w", key, err)
+	}
+
+	return result == 1, nil
+}
+
+// SetupRedis connects to Redis in the configured mode (standalone, Sentinel or
+// Cluster) and instruments the client with OpenTelemetry.
+func SetupRedis(ctx context.Context, config *Config, logger *zap.Logger) (redis.UniversalClient, error) {
+	client, err := newClient(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redis config: %w", err)
+	}
+
+	mode := config.Mode
+	if mode == "" {
+		mode = ModeStandalone
+	}
+
+	client.AddHook(newOTelHook(mode))
+
+	_, err = client.Ping(ctx).Result()
+	if err != nil {
+		_ = client.Close()
 		return nil, fmt.Errorf("failed to ping Redis: %w", err)
 	}
 
-	logger.Info("Redis connection established successfully")
+	logger.Info("Redis connection established successfully", zap.String("mode", mode))
 
 	return client, nil
 }
@@ -56,17 +110,24 @@ func SetupRedis(ctx context.Context, config *Config, logger *zap.Logger) (*redis
 // For security reasons, we will set the initial counter value to 14000000 - 1
 // and then increment it by 1 each time a new short URL is created. This is to prevent
 // the counter from being guessed by the public.
-func SetInitialCounterValue(ctx context.Context, client *redis.Client, config *Config, logger *zap.Logger) (bool, error) {
+func SetInitialCounterValue(ctx context.Context, client redis.UniversalClient, config *Config, logger *zap.Logger) (bool, error) {
 	initialValue := int64(config.CounterStartVal - 1)
 
-	set, err := client.SetNX(ctx, config.CounterKey, initialValue, 0).Result()
+	set, err := client.SetNX(ctx, config.CounterRedisKey(), initialValue, 0).Result()
 	if err != nil {
 		return false, fmt.Errorf("failed to initialize counter: %w", err)
 	}
 
 	if set {
-		logger.Info("Initialized Redis counter", zap.String("key", config.CounterKey), zap.Int64("start_value", initialValue))
+		logger.Info("Initialized Redis counter", zap.String("key", config.CounterRedisKey()), zap.Int64("start_value", initialValue))
 	}
 
 	return set, nil
 }
+
+// PingCheck verifies that Redis answers commands.
+func PingCheck(client redis.UniversalClient) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return client.Ping(ctx).Err()
+	}
+}
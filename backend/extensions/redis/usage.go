@@ -0,0 +1,108 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// usageRetention keeps a period's counter past the end of the month, so the
+// previous month can still be reported.
+const usageRetention = 62 * 24 * time.Hour
+
+// reserveUsageScript adds ARGV[1] to KEYS[1] unless that would take it over
+// the limit ARGV[2], where 0 means unlimited. It returns 1 when the amount was
+// reserved, and -1 without reserving it when KEYS[1] does not exist.
+var reserveUsageScript = redis.NewScript(`
+local current = redis.call("GET", KEYS[1])
+if not current then
+	return -1
+end
+local used = tonumber(current)
+local amount = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+if limit > 0 and used + amount > limit then
+	return 0
+end
+redis.call("INCRBY", KEYS[1], amount)
+redis.call("PEXPIRE", KEYS[1], ARGV[3])
+return 1
+`)
+
+// releaseUsageScript subtracts ARGV[1] from KEYS[1] without going below zero.
+var releaseUsageScript = redis.NewScript(`
+local used = tonumber(redis.call("GET", KEYS[1]) or "0")
+local amount = math.min(used, tonumber(ARGV[1]))
+if amount > 0 then
+	redis.call("DECRBY", KEYS[1], amount)
+end
+return amount
+`)
+
+// UsageMeter counts the links each workspace creates per period. A
+// reservation is checked against the limit and counted atomically, so
+// concurrent requests cannot overrun a quota.
+type UsageMeter struct {
+	client redis.UniversalClient
+}
+
+func NewUsageMeter(client redis.UniversalClient) *UsageMeter {
+	return &UsageMeter{client: client}
+}
+
+// Reserve counts amount links for the workspace in period unless the total
+// would exceed limit. It reports whether the links were counted, and ok false
+// without counting them while the period has no counter.
+func (m *UsageMeter) Reserve(ctx context.Context, workspaceID, period string, amount, limit int64) (reserved, ok bool, err error) {
+	result, err := reserveUsageScript.Run(ctx, m.client,
+		[]string{usageKey(workspaceID, period)},
+		amount, limit, usageRetention.Milliseconds(),
+	).Int()
+	if err != nil {
+		return false, false, fmt.Errorf("failed to reserve usage: %w", err)
+	}
+
+	return result == 1, result != -1, nil
+}
+
+// Seed creates the counter of the workspace in period with used links unless
+// it exists.
+func (m *UsageMeter) Seed(ctx context.Context, workspaceID, period string, used int64) error {
+	if err := m.client.SetNX(ctx, usageKey(workspaceID, period), used, usageRetention).Err(); err != nil {
+		return fmt.Errorf("failed to seed usage: %w", err)
+	}
+
+	return nil
+}
+
+// Release returns amount reserved links that were not created.
+func (m *UsageMeter) Release(ctx context.Context, workspaceID, period string, amount int64) error {
+	err := releaseUsageScript.Run(ctx, m.client, []string{usageKey(workspaceID, period)}, amount).Err()
+	if err != nil {
+		return fmt.Errorf("failed to release usage: %w", err)
+	}
+
+	return nil
+}
+
+// Used returns the links counted for the workspace in period, and ok false
+// while the period has no counter.
+func (m *UsageMeter) Used(ctx context.Context, workspaceID, period string) (used int64, ok bool, err error) {
+	used, err = m.client.Get(ctx, usageKey(workspaceID, period)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, false, nil
+	}
+
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to get usage: %w", err)
+	}
+
+	return used, true, nil
+}
+
+func usageKey(workspaceID, period string) string {
+	return "usage:links:" + workspaceID + ":" + period
+}
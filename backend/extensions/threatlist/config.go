@@ -0,0 +1,13 @@
+package threatlist
+
+import "time"
+
+type Config struct {
+	// Dir holds the *.domains and *.prefixes feeds. Matching is off when it
+	// is empty.
+	Dir string `envconfig:"THREAT_LIST_DIR"`
+	// Refresh is how often Dir is checked for changed files.
+	Refresh time.Duration `envconfig:"THREAT_LIST_REFRESH" default:"1m"`
+	// ScanInterval is the time between checks of every stored link.
+	ScanInterval time.Duration `envconfig:"THREAT_SCAN_INTERVAL" default:"1h"`
+}
@@ -0,0 +1,123 @@
+package threatlist
+
+import (
+	"net/netip"
+	"net/url"
+	"strings"
+)
+
+const (
+	// maxHostSuffixes and maxPathPrefixes bound the expressions of one URL as
+	// Safe Browsing does.
+	maxHostSuffixes = 4
+	maxPathPrefixes = 4
+)
+
+// hosts returns the canonical host of u and the parent domains that domain
+// feeds are checked for, down to two labels.
+func hosts(u *url.URL) []string {
+	host := strings.Trim(strings.ToLower(u.Hostname()), ".")
+	if host == "" {
+		return nil
+	}
+
+	if _, err := netip.ParseAddr(host); err == nil {
+		return []string{host}
+	}
+
+	candidates := []string{host}
+
+	labels := strings.Split(host, ".")
+	for i := 1; i < len(labels)-1; i++ {
+		candidates = append(candidates, strings.Join(labels[i:], "."))
+	}
+
+	return candidates
+}
+
+// expressions returns the host-suffix/path-prefix expressions of u whose
+// SHA-256 hash prefixes are looked up, following the Safe Browsing
+// canonicalization in simplified form: the exact host and up to four of its
+// suffixes formed from the last five labels, combined with the exact path with
+// and without its query and up to four leading path prefixes.
+func expressions(u *url.URL) []string {
+	candidates := hosts(u)
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	// Suffixes come from at most the last five labels and never the top-level
+	// domain alone, which hosts already leaves out.
+	suffixes := candidates[1:]
+	if len(suffixes) > maxHostSuffixes {
+		suffixes = suffixes[len(suffixes)-maxHostSuffixes:]
+	}
+
+	hostnames := append([]string{candidates[0]}, suffixes...)
+
+	path := canonicalPath(u.EscapedPath())
+
+	paths := []string{path}
+	if u.RawQuery != "" {
+		paths = append([]string{path + "?" + u.RawQuery}, paths...)
+	}
+
+	// The last component is either the file, which is not a prefix, or the
+	// exact path itself.
+	prefix := "/"
+	components := strings.Split(strings.Trim(path, "/"), "/")
+
+	for i := 0; i < maxPathPrefixes; i++ {
+		if prefix != path {
+			paths = append(paths, prefix)
+		}
+
+		if i >= len(components)-1 {
+			break
+		}
+
+		prefix += components[i] + "/"
+	}
+
+	seen := make(map[string]struct{}, len(hostnames)*len(paths))
+	found := make([]string, 0, len(hostnames)*len(paths))
+
+	for _, hostname := range hostnames {
+		for _, p := range paths {
+			expression := hostname + p
+			if _, ok := seen[expression]; ok {
+				continue
+			}
+
+			seen[expression] = struct{}{}
+			found = append(found, expression)
+		}
+	}
+
+	return found
+}
+
+// canonicalPath resolves "." and ".." segments and collapses repeated slashes,
+// keeping a trailing slash.
+func canonicalPath(path string) string {
+	segments := make([]string, 0, strings.Count(path, "/"))
+
+	for _, segment := range strings.Split(path, "/") {
+		switch segment {
+		case "", ".":
+		case "..":
+			if len(segments) > 0 {
+				segments = segments[:len(segments)-1]
+			}
+		default:
+			segments = append(segments, segment)
+		}
+	}
+
+	canonical := "/" + strings.Join(segments, "/")
+	if len(segments) > 0 && (strings.HasSuffix(path, "/") || strings.HasSuffix(path, "/.") || strings.HasSuffix(path, "/..")) {
+		canonical += "/"
+	}
+
+	return canonical
+}
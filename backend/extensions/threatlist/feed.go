@@ -0,0 +1,179 @@
+package threatlist
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const (
+	minPrefixBytes = 4
+	maxPrefixBytes = sha256.Size
+)
+
+// feed is one file of the list. Domains are kept as the first 8 bytes of
+// their SHA-256 and hash prefixes as one sorted run per length, so both are
+// searched in place without per-entry allocations.
+type feed struct {
+	// prefixes holds, for each prefix length, the sorted prefixes of that
+	// length back to back.
+	prefixes map[int][]byte
+	name     string
+	domains  []uint64
+}
+
+// parseDomains reads a plain domain feed: one domain per line, optionally
+// after an address as in a hosts file. Subdomains of a listed domain match
+// too. Blank lines and text after "#" are ignored.
+func parseDomains(name string, r io.Reader) (*feed, error) {
+	parsed := &feed{name: name}
+
+	err := eachLine(r, func(number int, line string) error {
+		fields := strings.Fields(line)
+		domain := strings.Trim(strings.TrimPrefix(strings.ToLower(fields[len(fields)-1]), "*."), ".")
+
+		if domain == "" || strings.ContainsAny(domain, "/:?#@") {
+			return fmt.Errorf("line %d: %q is not a domain", number, fields[len(fields)-1])
+		}
+
+		parsed.domains = append(parsed.domains, domainHash(domain))
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.Sort(parsed.domains)
+	parsed.domains = slices.Compact(parsed.domains)
+
+	return parsed, nil
+}
+
+// parsePrefixes reads a hash-prefix feed: one hex-encoded prefix of the
+// SHA-256 of a URL expression per line, between 4 and 32 bytes long. Blank
+// lines and text after "#" are ignored.
+func parsePrefixes(name string, r io.Reader) (*feed, error) {
+	parsed := &feed{name: name, prefixes: map[int][]byte{}}
+
+	err := eachLine(r, func(number int, line string) error {
+		prefix, err := hex.DecodeString(line)
+		if err != nil || len(prefix) < minPrefixBytes || len(prefix) > maxPrefixBytes {
+			return fmt.Errorf("line %d: %q is not a hex hash prefix of 4 to 32 bytes", number, line)
+		}
+
+		parsed.prefixes[len(prefix)] = append(parsed.prefixes[len(prefix)], prefix...)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for length, run := range parsed.prefixes {
+		parsed.prefixes[length] = sortRun(run, length)
+	}
+
+	return parsed, nil
+}
+
+func eachLine(r io.Reader, fn func(number int, line string) error) error {
+	scanner := bufio.NewScanner(r)
+
+	for number := 1; scanner.Scan(); number++ {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+
+		if err := fn(number, line); err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read feed: %w", err)
+	}
+
+	return nil
+}
+
+// sortRun sorts and deduplicates the entries of length bytes in run.
+func sortRun(run []byte, length int) []byte {
+	entries := make([][]byte, 0, len(run)/length)
+	for i := 0; i < len(run); i += length {
+		entries = append(entries, run[i:i+length])
+	}
+
+	slices.SortFunc(entries, bytes.Compare)
+	entries = slices.CompactFunc(entries, bytes.Equal)
+
+	sorted := make([]byte, 0, len(entries)*length)
+	for _, entry := range entries {
+		sorted = append(sorted, entry...)
+	}
+
+	return sorted
+}
+
+func (f *feed) hasDomain(hash uint64) bool {
+	_, found := slices.BinarySearch(f.domains, hash)
+
+	return found
+}
+
+// matchHash reports whether hash is on the feed. A full 32-byte entry is an
+// exact match. A shorter prefix only narrows the hash down: when the feed
+// also lists full hashes under that prefix, one of them must be hash, and the
+// prefix alone is another expression's. Otherwise the hit is unconfirmed.
+func (f *feed) matchHash(hash [sha256.Size]byte) (matched, confirmed bool) {
+	full := f.prefixes[maxPrefixBytes]
+	if hasEntry(full, maxPrefixBytes, hash[:]) {
+		return true, true
+	}
+
+	for length, run := range f.prefixes {
+		if length == maxPrefixBytes || !hasEntry(run, length, hash[:length]) {
+			continue
+		}
+
+		if !hasEntry(full, maxPrefixBytes, hash[:length]) {
+			matched = true
+		}
+	}
+
+	return matched, false
+}
+
+// hasEntry reports whether an entry of the sorted run of length-byte entries
+// starts with target.
+func hasEntry(run []byte, length int, target []byte) bool {
+	count := len(run) / length
+	index := sort.Search(count, func(i int) bool {
+		return bytes.Compare(run[i*length:i*length+len(target)], target) >= 0
+	})
+
+	return index < count && bytes.Equal(run[index*length:index*length+len(target)], target)
+}
+
+func (f *feed) entries() int {
+	count := len(f.domains)
+	for length, run := range f.prefixes {
+		count += len(run) / length
+	}
+
+	return count
+}
+
+func domainHash(domain string) uint64 {
+	sum := sha256.Sum256([]byte(domain))
+
+	return binary.BigEndian.Uint64(sum[:8])
+}
@@ -0,0 +1,243 @@
+// Package threatlist matches URLs against threat feeds stored on local disk,
+// so destinations can be checked without any network access.
+package threatlist
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	domainsExtension  = ".domains"
+	prefixesExtension = ".prefixes"
+
+	defaultRefresh = time.Minute
+)
+
+// snapshot is one load of the directory. It is never changed after loading,
+// so lookups need no locks.
+type snapshot struct {
+	fingerprint string
+	feeds       []*feed
+}
+
+// List holds the feeds of a directory: *.domains files list domains and
+// *.prefixes files list SHA-256 hash prefixes of URL expressions. Each file is
+// named after its list, such as malware.domains. Dropping in, replacing or
+// removing files reloads the list; files should be replaced by renaming, so a
+// half-written file is never loaded.
+type List struct {
+	logger  *zap.Logger
+	current atomic.Pointer[snapshot]
+	dir     string
+	refresh time.Duration
+}
+
+type NewListParams struct {
+	Logger *zap.Logger
+	Dir    string
+	// Refresh is how often Dir is checked for changed files.
+	Refresh time.Duration
+}
+
+// NewList loads the feeds in Dir. It fails when a feed cannot be read, so a
+// broken list is noticed at startup.
+func NewList(params NewListParams) (*List, error) {
+	if params.Refresh <= 0 {
+		params.Refresh = defaultRefresh
+	}
+
+	list := &List{
+		logger:  params.Logger,
+		dir:     params.Dir,
+		refresh: params.Refresh,
+	}
+
+	if _, err := list.Reload(); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// Run reloads the list whenever the files of its directory change, until ctx
+// is done. A reload that fails keeps the previous list.
+func (l *List) Run(ctx context.Context) error {
+	ticker := time.NewTicker(l.refresh)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if _, err := l.Reload(); err != nil {
+				l.logger.Error("Failed to reload threat list; keeping the previous one", zap.String("dir", l.dir), zap.Error(err))
+			}
+		}
+	}
+}
+
+// Reload loads the directory again if its files changed and reports whether
+// it did.
+func (l *List) Reload() (bool, error) {
+	files, fingerprint, err := l.scan()
+	if err != nil {
+		return false, err
+	}
+
+	if current := l.current.Load(); current != nil && current.fingerprint == fingerprint {
+		return false, nil
+	}
+
+	loaded := &snapshot{fingerprint: fingerprint}
+	entries := 0
+
+	for _, file := range files {
+		parsed, err := loadFeed(filepath.Join(l.dir, file))
+		if err != nil {
+			return false, err
+		}
+
+		loaded.feeds = append(loaded.feeds, parsed)
+		entries += parsed.entries()
+	}
+
+	l.current.Store(loaded)
+	l.logger.Info("Threat list loaded", zap.String("dir", l.dir), zap.Int("feeds", len(loaded.feeds)), zap.Int("entries", entries))
+
+	return true, nil
+}
+
+// Match reports whether rawURL is on the list, and names the first list it is
+// on. URLs that cannot be parsed never match. A hash prefix that no full hash
+// of its feed confirms still matches, since the feed may only ship prefixes,
+// but a confirmed match on a later list wins and the unconfirmed one is
+// logged, as it can be a false positive.
+func (l *List) Match(rawURL string) (string, bool) {
+	loaded := l.current.Load()
+	if loaded == nil || len(loaded.feeds) == 0 {
+		return "", false
+	}
+
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", false
+	}
+
+	domains := hosts(parsed)
+	domainHashes := make([]uint64, len(domains))
+
+	for i, domain := range domains {
+		domainHashes[i] = domainHash(domain)
+	}
+
+	var (
+		expressionHashes [][sha256.Size]byte
+		unconfirmed      string
+	)
+
+	for _, parsedFeed := range loaded.feeds {
+		for _, hash := range domainHashes {
+			if parsedFeed.hasDomain(hash) {
+				return parsedFeed.name, true
+			}
+		}
+
+		if len(parsedFeed.prefixes) == 0 {
+			continue
+		}
+
+		if expressionHashes == nil {
+			for _, expression := range expressions(parsed) {
+				expressionHashes = append(expressionHashes, sha256.Sum256([]byte(expression)))
+			}
+		}
+
+		for _, hash := range expressionHashes {
+			matched, confirmed := parsedFeed.matchHash(hash)
+
+			switch {
+			case confirmed:
+				return parsedFeed.name, true
+			case matched && unconfirmed == "":
+				unconfirmed = parsedFeed.name
+			}
+		}
+	}
+
+	if unconfirmed == "" {
+		return "", false
+	}
+
+	l.logger.Info("Threat list matched a hash prefix without a full hash to confirm it",
+		zap.String("list", unconfirmed),
+		zap.String("host", parsed.Hostname()),
+	)
+
+	return unconfirmed, true
+}
+
+// scan lists the feed files of the directory in name order and fingerprints
+// their names, sizes and modification times.
+func (l *List) scan() ([]string, string, error) {
+	dirEntries, err := os.ReadDir(l.dir)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read threat list directory: %w", err)
+	}
+
+	var (
+		files       []string
+		fingerprint strings.Builder
+	)
+
+	for _, entry := range dirEntries {
+		extension := filepath.Ext(entry.Name())
+		if entry.IsDir() || (extension != domainsExtension && extension != prefixesExtension) {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to stat threat feed %s: %w", entry.Name(), err)
+		}
+
+		files = append(files, entry.Name())
+		fmt.Fprintf(&fingerprint, "%s:%d:%d;", entry.Name(), info.Size(), info.ModTime().UnixNano())
+	}
+
+	return files, fingerprint.String(), nil
+}
+
+func loadFeed(path string) (*feed, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open threat feed: %w", err)
+	}
+	defer file.Close()
+
+	name := filepath.Base(path)
+	extension := filepath.Ext(name)
+
+	var parsed *feed
+	if extension == domainsExtension {
+		parsed, err = parseDomains(strings.TrimSuffix(name, extension), file)
+	} else {
+		parsed, err = parsePrefixes(strings.TrimSuffix(name, extension), file)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to load threat feed %s: %w", name, err)
+	}
+
+	return parsed, nil
+}
@@ -0,0 +1,132 @@
+package threatlist_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"lnk/extensions/threatlist"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func hashPrefix(expression string, length int) string {
+	sum := sha256.Sum256([]byte(expression))
+
+	return hex.EncodeToString(sum[:length])
+}
+
+func writeFeed(t *testing.T, dir, name, content string) {
+	t.Helper()
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+}
+
+func Test_List_Match(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFeed(t, dir, "phishing.domains", "# comment\nevil.example\n0.0.0.0 tracker.example # hosts file\n*.wild.example\n")
+	writeFeed(t, dir, "malware.prefixes", hashPrefix("files.example/payloads/", 32)+"\n"+hashPrefix("203.0.113.7/", 4)+"\n")
+	writeFeed(t, dir, "notes.txt", "not a feed\n")
+
+	list, err := threatlist.NewList(threatlist.NewListParams{Logger: zap.NewNop(), Dir: dir})
+	require.NoError(t, err)
+
+	for rawURL, want := range map[string]string{
+		"https://evil.example/login":                       "phishing",
+		"https://login.EVIL.example./account":              "phishing",
+		"http://tracker.example":                           "phishing",
+		"https://a.wild.example":                           "phishing",
+		"https://files.example/payloads/run.exe?x=1":       "malware",
+		"https://cdn.files.example/payloads/../payloads/a": "malware",
+		"http://203.0.113.7:8080/anything":                 "malware",
+		"https://example.com":                              "",
+		"https://notevil.example":                          "",
+		"https://files.example/other/run.exe":              "",
+	} {
+		name, matched := list.Match(rawURL)
+		require.Equal(t, want != "", matched, rawURL)
+		require.Equal(t, want, name, rawURL)
+	}
+}
+
+func Test_List_Match_ConfirmsPrefixes(t *testing.T) {
+	t.Parallel()
+
+	// Another expression whose hash shares the 4-byte prefix of
+	// shop.example/: the prefix collides, and the full hash tells them apart.
+	colliding, err := hex.DecodeString(hashPrefix("shop.example/", 4) + strings.Repeat("00", 28))
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	writeFeed(t, dir, "malware.prefixes", hashPrefix("shop.example/", 4)+"\n"+hex.EncodeToString(colliding)+"\n"+
+		hashPrefix("files.example/", 4)+"\n"+hashPrefix("files.example/", 32)+"\n")
+	writeFeed(t, dir, "phishing.prefixes", hashPrefix("login.example/", 4)+"\n")
+
+	list, err := threatlist.NewList(threatlist.NewListParams{Logger: zap.NewNop(), Dir: dir})
+	require.NoError(t, err)
+
+	for rawURL, want := range map[string]string{
+		"https://shop.example/":  "",
+		"https://files.example/": "malware",
+		// Without full hashes the prefix matches unconfirmed.
+		"https://login.example/": "phishing",
+	} {
+		name, matched := list.Match(rawURL)
+		require.Equal(t, want != "", matched, rawURL)
+		require.Equal(t, want, name, rawURL)
+	}
+}
+
+func Test_List_Reload(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	list, err := threatlist.NewList(threatlist.NewListParams{Logger: zap.NewNop(), Dir: dir})
+	require.NoError(t, err)
+
+	_, matched := list.Match("https://evil.example")
+	require.False(t, matched)
+
+	writeFeed(t, dir, "phishing.domains", "evil.example\n")
+
+	reloaded, err := list.Reload()
+	require.NoError(t, err)
+	require.True(t, reloaded)
+
+	_, matched = list.Match("https://evil.example")
+	require.True(t, matched)
+
+	reloaded, err = list.Reload()
+	require.NoError(t, err)
+	require.False(t, reloaded)
+
+	// A broken feed keeps the previous list.
+	writeFeed(t, dir, "malware.prefixes", "not-hex\n")
+
+	_, err = list.Reload()
+	require.ErrorContains(t, err, "malware.prefixes")
+
+	_, matched = list.Match("https://evil.example")
+	require.True(t, matched)
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "malware.prefixes")))
+	require.NoError(t, os.Remove(filepath.Join(dir, "phishing.domains")))
+
+	reloaded, err = list.Reload()
+	require.NoError(t, err)
+	require.True(t, reloaded)
+
+	_, matched = list.Match("https://evil.example")
+	require.False(t, matched)
+
+	_, err = threatlist.NewList(threatlist.NewListParams{Logger: zap.NewNop(), Dir: filepath.Join(dir, "missing"), Refresh: time.Second})
+	require.Error(t, err)
+}
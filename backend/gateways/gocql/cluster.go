@@ -0,0 +1,193 @@
+package gocql
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+	"github.com/apache/cassandra-gocql-driver/v2/lz4"
+	"github.com/apache/cassandra-gocql-driver/v2/snappy"
+)
+
+const (
+	simpleStrategy          = "SimpleStrategy"
+	networkTopologyStrategy = "NetworkTopologyStrategy"
+)
+
+// QueryPolicy holds the per-statement settings that the driver cannot apply
+// at cluster level: separate read/write consistency and speculative execution.
+type QueryPolicy struct {
+	SpeculativeExecution gocql.SpeculativeExecutionPolicy
+	ReadConsistency      gocql.Consistency
+	WriteConsistency     gocql.Consistency
+}
+
+// NewQueryPolicy builds the query policy from config. Read and write
+// consistency fall back to CASSANDRA_CONSISTENCY when unset.
+func NewQueryPolicy(config *Config) (QueryPolicy, error) {
+	defaultConsistency, err := parseConsistency(config.Consistency, gocql.Quorum)
+	if err != nil {
+		return QueryPolicy{}, err
+	}
+
+	readConsistency, err := parseConsistency(config.ReadConsistency, defaultConsistency)
+	if err != nil {
+		return QueryPolicy{}, err
+	}
+
+	writeConsistency, err := parseConsistency(config.WriteConsistency, defaultConsistency)
+	if err != nil {
+		return QueryPolicy{}, err
+	}
+
+	var speculative gocql.SpeculativeExecutionPolicy = gocql.NonSpeculativeExecution{}
+	if config.SpeculativeAttempts > 0 {
+		speculative = &gocql.SimpleSpeculativeExecution{
+			NumAttempts:  config.SpeculativeAttempts,
+			TimeoutDelay: config.SpeculativeDelay,
+		}
+	}
+
+	return QueryPolicy{
+		SpeculativeExecution: speculative,
+		ReadConsistency:      readConsistency,
+		WriteConsistency:     writeConsistency,
+	}, nil
+}
+
+func newClusterConfig(config *Config) (*gocql.ClusterConfig, error) {
+	if len(config.Hosts) == 0 {
+		return nil, fmt.Errorf("at least one contact point is required")
+	}
+
+	consistency, err := parseConsistency(config.Consistency, gocql.Quorum)
+	if err != nil {
+		return nil, err
+	}
+
+	cluster := gocql.NewCluster(config.Hosts...)
+	cluster.Port = config.Port
+	cluster.Authenticator = gocql.PasswordAuthenticator{
+		Username: config.Username,
+		Password: config.Password,
+	}
+	cluster.Consistency = consistency
+	cluster.ConnectTimeout = config.ConnectTimeout
+	cluster.Timeout = config.Timeout
+
+	if config.NumConns > 0 {
+		cluster.NumConns = config.NumConns
+	}
+
+	cluster.PoolConfig.HostSelectionPolicy = hostSelectionPolicy(config)
+
+	cluster.RetryPolicy, err = retryPolicy(config)
+	if err != nil {
+		return nil, err
+	}
+
+	cluster.Compressor, err = compressor(config.Compression)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.TLSEnabled {
+		cluster.SslOpts = &gocql.SslOptions{
+			CertPath:               config.TLSCertPath,
+			KeyPath:                config.TLSKeyPath,
+			CaPath:                 config.TLSCAPath,
+			EnableHostVerification: config.TLSHostVerification,
+		}
+	}
+
+	return cluster, nil
+}
+
+// hostSelectionPolicy prefers hosts in the local DC when one is configured and
+// routes queries straight to a replica when token awareness is enabled.
+func hostSelectionPolicy(config *Config) gocql.HostSelectionPolicy {
+	fallback := gocql.RoundRobinHostPolicy()
+	if config.LocalDC != "" {
+		fallback = gocql.DCAwareRoundRobinPolicy(config.LocalDC)
+	}
+
+	if !config.TokenAware {
+		return fallback
+	}
+
+	return gocql.TokenAwareHostPolicy(fallback)
+}
+
+func retryPolicy(config *Config) (gocql.RetryPolicy, error) {
+	switch strings.ToLower(config.RetryPolicy) {
+	case "", "simple":
+		return &gocql.SimpleRetryPolicy{NumRetries: config.RetryAttempts}, nil
+	case "exponential":
+		return &gocql.ExponentialBackoffRetryPolicy{
+			NumRetries: config.RetryAttempts,
+			Min:        config.RetryMinBackoff,
+			Max:        config.RetryMaxBackoff,
+		}, nil
+	case "none":
+		return &gocql.SimpleRetryPolicy{NumRetries: 0}, nil
+	default:
+		return nil, fmt.Errorf("unknown retry policy %q", config.RetryPolicy)
+	}
+}
+
+func compressor(name string) (gocql.Compressor, error) {
+	switch strings.ToLower(name) {
+	case "", "none":
+		return nil, nil //nolint:nilnil // no compression is a valid choice
+	case "snappy":
+		return snappy.SnappyCompressor{}, nil
+	case "lz4":
+		return lz4.LZ4Compressor{}, nil
+	default:
+		return nil, fmt.Errorf("unknown compression %q", name)
+	}
+}
+
+func parseConsistency(value string, fallback gocql.Consistency) (gocql.Consistency, error) {
+	if value == "" {
+		return fallback, nil
+	}
+
+	consistency, err := gocql.ParseConsistencyWrapper(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid consistency %q: %w", value, err)
+	}
+
+	return consistency, nil
+}
+
+// replicationClause renders the replication map used when creating the keyspace.
+func replicationClause(config *Config) (string, error) {
+	switch config.ReplicationStrategy {
+	case "", simpleStrategy:
+		return fmt.Sprintf("{'class': '%s', 'replication_factor': %d}", simpleStrategy, config.ReplicationFactor), nil
+	case networkTopologyStrategy:
+		if len(config.DCReplication) == 0 {
+			return "", fmt.Errorf("%s requires CASSANDRA_DC_REPLICATION", networkTopologyStrategy)
+		}
+
+		dcs := make([]string, 0, len(config.DCReplication))
+		for dc := range config.DCReplication {
+			dcs = append(dcs, dc)
+		}
+
+		sort.Strings(dcs)
+
+		parts := make([]string, 0, len(dcs)+1)
+		parts = append(parts, fmt.Sprintf("'class': '%s'", networkTopologyStrategy))
+
+		for _, dc := range dcs {
+			parts = append(parts, fmt.Sprintf("'%s': %d", dc, config.DCReplication[dc]))
+		}
+
+		return "{" + strings.Join(parts, ", ") + "}", nil
+	default:
+		return "", fmt.Errorf("unknown replication strategy %q", config.ReplicationStrategy)
+	}
+}
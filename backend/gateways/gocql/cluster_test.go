@@ -0,0 +1,189 @@
+package gocql
+
+import (
+	"testing"
+	"time"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseConsistency(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		value    string
+		expected gocql.Consistency
+		wantErr  bool
+	}{
+		{name: "empty uses the fallback", value: "", expected: gocql.Two},
+		{name: "upper case", value: "QUORUM", expected: gocql.Quorum},
+		{name: "lower case", value: "local_quorum", expected: gocql.LocalQuorum},
+		{name: "one", value: "ONE", expected: gocql.One},
+		{name: "unknown", value: "MOST", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			consistency, err := parseConsistency(tt.value, gocql.Two)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, consistency)
+		})
+	}
+}
+
+func Test_NewQueryPolicy(t *testing.T) {
+	t.Parallel()
+
+	policy, err := NewQueryPolicy(&Config{Consistency: "LOCAL_QUORUM", WriteConsistency: "EACH_QUORUM"})
+	require.NoError(t, err)
+	require.Equal(t, gocql.LocalQuorum, policy.ReadConsistency)
+	require.Equal(t, gocql.EachQuorum, policy.WriteConsistency)
+	require.IsType(t, gocql.NonSpeculativeExecution{}, policy.SpeculativeExecution)
+
+	policy, err = NewQueryPolicy(&Config{SpeculativeAttempts: 2, SpeculativeDelay: time.Millisecond})
+	require.NoError(t, err)
+	require.Equal(t, gocql.Quorum, policy.ReadConsistency)
+	require.Equal(t, &gocql.SimpleSpeculativeExecution{NumAttempts: 2, TimeoutDelay: time.Millisecond}, policy.SpeculativeExecution)
+
+	_, err = NewQueryPolicy(&Config{ReadConsistency: "SOME"})
+	require.Error(t, err)
+}
+
+func Test_RetryPolicy(t *testing.T) {
+	t.Parallel()
+
+	config := Config{RetryAttempts: 3, RetryMinBackoff: time.Millisecond, RetryMaxBackoff: time.Second}
+
+	tests := []struct {
+		expected gocql.RetryPolicy
+		name     string
+		policy   string
+		wantErr  bool
+	}{
+		{name: "default", policy: "", expected: &gocql.SimpleRetryPolicy{NumRetries: 3}},
+		{name: "simple", policy: "Simple", expected: &gocql.SimpleRetryPolicy{NumRetries: 3}},
+		{
+			name:     "exponential",
+			policy:   "exponential",
+			expected: &gocql.ExponentialBackoffRetryPolicy{NumRetries: 3, Min: time.Millisecond, Max: time.Second},
+		},
+		{name: "none", policy: "none", expected: &gocql.SimpleRetryPolicy{NumRetries: 0}},
+		{name: "unknown", policy: "forever", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			config := config
+			config.RetryPolicy = tt.policy
+
+			policy, err := retryPolicy(&config)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, policy)
+		})
+	}
+}
+
+func Test_HostSelectionPolicy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		expected gocql.HostSelectionPolicy
+		name     string
+		config   Config
+	}{
+		{name: "round robin", config: Config{}, expected: gocql.RoundRobinHostPolicy()},
+		{name: "local DC", config: Config{LocalDC: "dc1"}, expected: gocql.DCAwareRoundRobinPolicy("dc1")},
+		{
+			name:     "token aware",
+			config:   Config{LocalDC: "dc1", TokenAware: true},
+			expected: gocql.TokenAwareHostPolicy(gocql.DCAwareRoundRobinPolicy("dc1")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.IsType(t, tt.expected, hostSelectionPolicy(&tt.config))
+		})
+	}
+}
+
+func Test_ReplicationClause(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		expected string
+		config   Config
+		wantErr  bool
+	}{
+		{
+			name:     "default",
+			config:   Config{ReplicationFactor: 1},
+			expected: "{'class': 'SimpleStrategy', 'replication_factor': 1}",
+		},
+		{
+			name:     "simple",
+			config:   Config{ReplicationStrategy: simpleStrategy, ReplicationFactor: 3},
+			expected: "{'class': 'SimpleStrategy', 'replication_factor': 3}",
+		},
+		{
+			name: "network topology sorts DCs",
+			config: Config{
+				ReplicationStrategy: networkTopologyStrategy,
+				DCReplication:       map[string]int{"eu-west": 3, "us-east": 2},
+			},
+			expected: "{'class': 'NetworkTopologyStrategy', 'eu-west': 3, 'us-east': 2}",
+		},
+		{name: "network topology without DCs", config: Config{ReplicationStrategy: networkTopologyStrategy}, wantErr: true},
+		{name: "unknown", config: Config{ReplicationStrategy: "LocalStrategy"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			clause, err := replicationClause(&tt.config)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, clause)
+		})
+	}
+}
+
+func Test_NewClusterConfig(t *testing.T) {
+	t.Parallel()
+
+	_, err := newClusterConfig(&Config{})
+	require.Error(t, err)
+
+	_, err = newClusterConfig(&Config{Hosts: []string{"cassandra"}, Compression: "zstd"})
+	require.Error(t, err)
+
+	cluster, err := newClusterConfig(&Config{Hosts: []string{"cassandra"}, Consistency: "ONE", Compression: "lz4", NumConns: 4})
+	require.NoError(t, err)
+	require.Equal(t, gocql.One, cluster.Consistency)
+	require.Equal(t, 4, cluster.NumConns)
+	require.NotNil(t, cluster.Compressor)
+}
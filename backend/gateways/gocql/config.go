@@ -1,10 +1,35 @@
 package gocql
 
+import "time"
+
 type Config struct {
-	Host        string `envconfig:"CASSANDRA_HOST" required:"true"`
-	Username    string `envconfig:"CASSANDRA_USERNAME" required:"true"`
-	Password    string `envconfig:"CASSANDRA_PASSWORD" required:"true"`
-	Keyspace    string `envconfig:"CASSANDRA_KEYSPACE" required:"true"`
-	Port        int    `envconfig:"CASSANDRA_PORT" required:"true"`
-	AutoMigrate bool   `envconfig:"CASSANDRA_AUTO_MIGRATE" default:"false"`
+	DCReplication       map[string]int `envconfig:"CASSANDRA_DC_REPLICATION"`
+	Username            string         `envconfig:"CASSANDRA_USERNAME" required:"true"`
+	Password            string         `envconfig:"CASSANDRA_PASSWORD" required:"true"`
+	Keyspace            string         `envconfig:"CASSANDRA_KEYSPACE" required:"true"`
+	LocalDC             string         `envconfig:"CASSANDRA_LOCAL_DC"`
+	ReplicationStrategy string         `envconfig:"CASSANDRA_REPLICATION_STRATEGY" default:"SimpleStrategy"`
+	Consistency         string         `envconfig:"CASSANDRA_CONSISTENCY" default:"QUORUM"`
+	ReadConsistency     string         `envconfig:"CASSANDRA_READ_CONSISTENCY"`
+	WriteConsistency    string         `envconfig:"CASSANDRA_WRITE_CONSISTENCY"`
+	Compression         string         `envconfig:"CASSANDRA_COMPRESSION"`
+	RetryPolicy         string         `envconfig:"CASSANDRA_RETRY_POLICY" default:"simple"`
+	TLSCertPath         string         `envconfig:"CASSANDRA_TLS_CERT_PATH"`
+	TLSKeyPath          string         `envconfig:"CASSANDRA_TLS_KEY_PATH"`
+	TLSCAPath           string         `envconfig:"CASSANDRA_TLS_CA_PATH"`
+	Hosts               []string       `envconfig:"CASSANDRA_HOST" required:"true"`
+	Port                int            `envconfig:"CASSANDRA_PORT" required:"true"`
+	ReplicationFactor   int            `envconfig:"CASSANDRA_REPLICATION_FACTOR" default:"1"`
+	NumConns            int            `envconfig:"CASSANDRA_NUM_CONNS" default:"2"`
+	RetryAttempts       int            `envconfig:"CASSANDRA_RETRY_ATTEMPTS" default:"3"`
+	RetryMinBackoff     time.Duration  `envconfig:"CASSANDRA_RETRY_MIN_BACKOFF" default:"100ms"`
+	RetryMaxBackoff     time.Duration  `envconfig:"CASSANDRA_RETRY_MAX_BACKOFF" default:"2s"`
+	SpeculativeAttempts int            `envconfig:"CASSANDRA_SPECULATIVE_ATTEMPTS" default:"0"`
+	SpeculativeDelay    time.Duration  `envconfig:"CASSANDRA_SPECULATIVE_DELAY" default:"100ms"`
+	ConnectTimeout      time.Duration  `envconfig:"CASSANDRA_CONNECT_TIMEOUT" default:"30s"`
+	Timeout             time.Duration  `envconfig:"CASSANDRA_TIMEOUT" default:"10s"`
+	TLSEnabled          bool           `envconfig:"CASSANDRA_TLS_ENABLED" default:"false"`
+	TLSHostVerification bool           `envconfig:"CASSANDRA_TLS_HOST_VERIFICATION" default:"true"`
+	TokenAware          bool           `envconfig:"CASSANDRA_TOKEN_AWARE" default:"true"`
+	AutoMigrate         bool           `envconfig:"CASSANDRA_AUTO_MIGRATE" default:"false"`
 }
@@ -0,0 +1,237 @@
+package gocql
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "lnk/gateways/gocql"
+
+// Executor runs registered statements against a session. Every execution is
+// wrapped in a client span carrying the database semantic-convention
+// attributes and is recorded in per-statement latency and error metrics.
+type Executor struct {
+	session  *gocql.Session
+	policy   *QueryPolicy
+	tracer   trace.Tracer
+	duration metric.Float64Histogram
+	errors   metric.Int64Counter
+}
+
+func NewExecutor(session *gocql.Session) *Executor {
+	meter := otel.Meter(instrumentationName)
+
+	// Instrument creation only fails on invalid names, which are constant here.
+	duration, _ := meter.Float64Histogram(
+		"db.client.operation.duration",
+		metric.WithDescription("Duration of Cassandra statements"),
+		metric.WithUnit("s"),
+	)
+
+	errorCounter, _ := meter.Int64Counter(
+		"db.client.operation.errors",
+		metric.WithDescription("Number of failed Cassandra statements"),
+		metric.WithUnit("1"),
+	)
+
+	return &Executor{
+		session:  session,
+		tracer:   otel.Tracer(instrumentationName),
+		duration: duration,
+		errors:   errorCounter,
+	}
+}
+
+// SetQueryPolicy applies per-statement consistency and speculative execution.
+// Without a policy, statements use the session defaults.
+func (e *Executor) SetQueryPolicy(policy QueryPolicy) {
+	e.policy = &policy
+}
+
+// Exec runs a statement that returns no rows.
+func (e *Executor) Exec(ctx context.Context, stmt Statement, values ...any) error {
+	query := e.query(stmt, values)
+
+	return e.observe(ctx, stmt, query, func(ctx context.Context) error {
+		return query.ExecContext(ctx)
+	})
+}
+
+// Scan runs a statement and scans the first row into dest.
+func (e *Executor) Scan(ctx context.Context, stmt Statement, values []any, dest ...any) error {
+	query := e.query(stmt, values)
+
+	return e.observe(ctx, stmt, query, func(ctx context.Context) error {
+		return query.ScanContext(ctx, dest...)
+	})
+}
+
+// Each runs a statement and calls fn after scanning each row into dest. Rows
+// are fetched page by page, so it is suitable for full-table scans.
+func (e *Executor) Each(ctx context.Context, stmt Statement, values []any, dest []any, fn func() error) error {
+	query := e.query(stmt, values)
+
+	return e.observe(ctx, stmt, query, func(ctx context.Context) error {
+		scanner := query.IterContext(ctx).Scanner()
+
+		for scanner.Next() {
+			if err := scanner.Scan(dest...); err != nil {
+				return err
+			}
+
+			if err := fn(); err != nil {
+				return err
+			}
+		}
+
+		return scanner.Err()
+	})
+}
+
+// Page runs a statement and scans the rows of a single page into dest, calling
+// fn after each row. pageState resumes after an earlier page and is nil for
+// the first one. It returns the state of the next page, or nil after the last
+// page.
+func (e *Executor) Page(ctx context.Context, stmt Statement, values []any, pageSize int, pageState []byte, dest []any, fn func()) ([]byte, error) {
+	query := e.query(stmt, values).PageSize(pageSize).PageState(pageState)
+
+	var next []byte
+
+	err := e.observe(ctx, stmt, query, func(ctx context.Context) error {
+		iter := query.IterContext(ctx)
+		next = iter.PageState()
+
+		scanner := iter.Scanner()
+		for scanner.Next() {
+			if err := scanner.Scan(dest...); err != nil {
+				return err
+			}
+
+			fn()
+		}
+
+		return scanner.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return next, nil
+}
+
+// BatchEntry is one statement of a batch with its values.
+type BatchEntry struct {
+	Statement Statement
+	Values    []any
+}
+
+// ExecBatch runs entries as one logged batch: once it is accepted, all of the
+// entries are applied even if a replica fails midway. name identifies the
+// batch in spans and metrics.
+func (e *Executor) ExecBatch(ctx context.Context, name string, entries ...BatchEntry) error {
+	batch := e.session.Batch(gocql.LoggedBatch)
+	for _, entry := range entries {
+		batch.Entries = append(batch.Entries, gocql.BatchEntry{
+			Stmt:       entry.Statement.CQL,
+			Args:       entry.Values,
+			Idempotent: entry.Statement.Idempotent,
+		})
+	}
+
+	if e.policy != nil {
+		batch = batch.Consistency(e.policy.WriteConsistency)
+	}
+
+	stmt := Statement{Name: name, Operation: "BATCH"}
+
+	return e.observe(ctx, stmt, batch, func(ctx context.Context) error {
+		return batch.ExecContext(ctx)
+	})
+}
+
+// ExecCAS runs a lightweight transaction and reports whether it was applied.
+func (e *Executor) ExecCAS(ctx context.Context, stmt Statement, values ...any) (bool, error) {
+	query := e.query(stmt, values)
+
+	var applied bool
+
+	err := e.observe(ctx, stmt, query, func(ctx context.Context) error {
+		var err error
+
+		applied, err = query.MapScanCASContext(ctx, map[string]any{})
+
+		return err
+	})
+
+	return applied, err
+}
+
+func (e *Executor) query(stmt Statement, values []any) *gocql.Query {
+	query := e.session.Query(stmt.CQL, values...).Idempotent(stmt.Idempotent)
+	if e.policy == nil {
+		return query
+	}
+
+	if stmt.IsRead() {
+		query = query.Consistency(e.policy.ReadConsistency)
+	} else {
+		query = query.Consistency(e.policy.WriteConsistency)
+	}
+
+	if stmt.Idempotent {
+		query = query.SetSpeculativeExecutionPolicy(e.policy.SpeculativeExecution)
+	}
+
+	return query
+}
+
+// observed is a query or batch as seen by observe.
+type observed interface {
+	Keyspace() string
+	GetConsistency() gocql.Consistency
+}
+
+func (e *Executor) observe(ctx context.Context, stmt Statement, query observed, run func(context.Context) error) error {
+	attrs := []attribute.KeyValue{
+		semconv.DBSystemNameCassandra,
+		semconv.DBOperationName(stmt.Operation),
+		semconv.DBNamespace(query.Keyspace()),
+		semconv.CassandraConsistencyLevelKey.String(strings.ToLower(query.GetConsistency().String())),
+		attribute.String("db.statement.name", stmt.Name),
+	}
+
+	ctx, span := e.tracer.Start(ctx, stmt.Name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	defer span.End()
+
+	start := time.Now()
+	err := run(ctx)
+	elapsed := time.Since(start).Seconds()
+
+	metricAttrs := metric.WithAttributes(
+		attribute.String("db.statement.name", stmt.Name),
+		semconv.DBOperationName(stmt.Operation),
+	)
+
+	e.duration.Record(ctx, elapsed, metricAttrs)
+
+	if err != nil && !errors.Is(err, gocql.ErrNotFound) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		e.errors.Add(ctx, 1, metricAttrs)
+	}
+
+	return err
+}
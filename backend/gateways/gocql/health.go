@@ -0,0 +1,101 @@
+package gocql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+
+	"lnk/gateways/gocql/migrations"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+)
+
+var (
+	selectReleaseVersionStatement = MustRegister(Statement{
+		Name:       "system.local.release_version",
+		CQL:        "SELECT release_version FROM system.local",
+		Idempotent: true,
+	})
+
+	selectSchemaVersionStatement = MustRegister(Statement{
+		Name:       "schema_migrations.select",
+		CQL:        "SELECT version, dirty FROM schema_migrations LIMIT 1",
+		Idempotent: true,
+	})
+)
+
+// PingCheck verifies that the cluster answers queries.
+func PingCheck(executor *Executor) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		var releaseVersion string
+
+		return executor.Scan(ctx, selectReleaseVersionStatement, nil, &releaseVersion)
+	}
+}
+
+// MigrationCheck verifies that the schema version applied to the keyspace is
+// not dirty and includes the latest migration embedded in this binary. A newer
+// schema passes, so replicas of the previous release stay ready while the
+// migrator advances it during a rollout.
+func MigrationCheck(executor *Executor) (func(ctx context.Context) error, error) {
+	expected, err := LatestMigrationVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context) error {
+		var (
+			version int64
+			dirty   bool
+		)
+
+		err := executor.Scan(ctx, selectSchemaVersionStatement, nil, &version, &dirty)
+		if err != nil {
+			if errors.Is(err, gocql.ErrNotFound) {
+				return errors.New("no migrations applied")
+			}
+
+			return fmt.Errorf("failed to read schema version: %w", err)
+		}
+
+		if dirty {
+			return fmt.Errorf("schema version %d is dirty", version)
+		}
+
+		if uint64(version) < expected { //nolint:gosec // migration versions are positive
+			return fmt.Errorf("schema version %d is behind expected %d", version, expected)
+		}
+
+		return nil
+	}, nil
+}
+
+// LatestMigrationVersion returns the highest version among the embedded
+// migration files, named <version>_<title>.up.sql.
+func LatestMigrationVersion() (uint64, error) {
+	entries, err := fs.ReadDir(migrations.MigrationsFS, ".")
+	if err != nil {
+		return 0, fmt.Errorf("failed to read embedded migrations: %w", err)
+	}
+
+	var latest uint64
+
+	for _, entry := range entries {
+		prefix, _, found := strings.Cut(entry.Name(), "_")
+		if !found || !strings.HasSuffix(entry.Name(), ".up.sql") {
+			continue
+		}
+
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid migration file name %s: %w", entry.Name(), err)
+		}
+
+		latest = max(latest, version)
+	}
+
+	return latest, nil
+}
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS counter_checkpoints;
\ No newline at end of file
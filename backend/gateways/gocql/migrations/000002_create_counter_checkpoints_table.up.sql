@@ -0,0 +1,8 @@
+CREATE TABLE
+  counter_checkpoints (
+    name TEXT,
+    observed_value BIGINT,
+    high_water_mark BIGINT,
+    updated_at TIMESTAMP,
+    PRIMARY KEY (name)
+  );
\ No newline at end of file
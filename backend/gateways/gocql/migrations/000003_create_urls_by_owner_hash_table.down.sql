@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS urls_by_owner_hash;
+
+ALTER TABLE urls DROP owner_id;
@@ -0,0 +1,10 @@
+ALTER TABLE urls ADD owner_id TEXT;
+
+CREATE TABLE
+  urls_by_owner_hash (
+    owner_id TEXT,
+    url_hash TEXT,
+    short_code TEXT,
+    created_at TIMESTAMP,
+    PRIMARY KEY ((owner_id, url_hash))
+  );
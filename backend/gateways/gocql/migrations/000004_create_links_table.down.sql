@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS links_by_owner_hash;
+
+DROP TABLE IF EXISTS links;
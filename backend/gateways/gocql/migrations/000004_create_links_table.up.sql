@@ -0,0 +1,19 @@
+CREATE TABLE
+  links (
+    domain TEXT,
+    short_code TEXT,
+    long_url TEXT,
+    owner_id TEXT,
+    created_at TIMESTAMP,
+    PRIMARY KEY ((domain, short_code))
+  );
+
+CREATE TABLE
+  links_by_owner_hash (
+    owner_id TEXT,
+    domain TEXT,
+    url_hash TEXT,
+    short_code TEXT,
+    created_at TIMESTAMP,
+    PRIMARY KEY ((owner_id, domain, url_hash))
+  );
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS domains_by_workspace;
+
+DROP TABLE IF EXISTS workspace_domains;
+
+DROP TABLE IF EXISTS workspace_members;
+
+DROP TABLE IF EXISTS workspaces;
@@ -0,0 +1,33 @@
+CREATE TABLE
+  workspaces (
+    id TEXT PRIMARY KEY,
+    name TEXT,
+    links_per_month BIGINT,
+    max_domains INT,
+    created_by TEXT,
+    created_at TIMESTAMP
+  );
+
+CREATE TABLE
+  workspace_members (
+    workspace_id TEXT,
+    user_id TEXT,
+    role TEXT,
+    created_at TIMESTAMP,
+    PRIMARY KEY ((workspace_id), user_id)
+  );
+
+CREATE TABLE
+  workspace_domains (
+    domain TEXT PRIMARY KEY,
+    workspace_id TEXT,
+    created_at TIMESTAMP
+  );
+
+CREATE TABLE
+  domains_by_workspace (
+    workspace_id TEXT,
+    domain TEXT,
+    created_at TIMESTAMP,
+    PRIMARY KEY ((workspace_id), domain)
+  );
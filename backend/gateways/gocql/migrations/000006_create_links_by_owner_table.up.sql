@@ -0,0 +1,11 @@
+CREATE TABLE
+  links_by_owner (
+    owner_id TEXT,
+    created_at TIMESTAMP,
+    domain TEXT,
+    short_code TEXT,
+    long_url TEXT,
+    PRIMARY KEY ((owner_id), created_at, domain, short_code)
+  )
+WITH
+  CLUSTERING ORDER BY (created_at DESC, domain ASC, short_code ASC);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS links_by_tag;
+
+ALTER TABLE links_by_owner DROP (title, notes, tags, updated_at);
+
+ALTER TABLE links DROP (title, notes, tags, updated_at);
@@ -0,0 +1,20 @@
+ALTER TABLE links ADD (title TEXT, notes TEXT, tags SET<TEXT>, updated_at TIMESTAMP);
+
+ALTER TABLE links_by_owner ADD (title TEXT, notes TEXT, tags SET<TEXT>, updated_at TIMESTAMP);
+
+CREATE TABLE
+  links_by_tag (
+    owner_id TEXT,
+    tag TEXT,
+    created_at TIMESTAMP,
+    domain TEXT,
+    short_code TEXT,
+    long_url TEXT,
+    title TEXT,
+    notes TEXT,
+    tags SET<TEXT>,
+    updated_at TIMESTAMP,
+    PRIMARY KEY ((owner_id, tag), created_at, domain, short_code)
+  )
+WITH
+  CLUSTERING ORDER BY (created_at DESC, domain ASC, short_code ASC);
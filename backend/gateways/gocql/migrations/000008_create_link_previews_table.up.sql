@@ -0,0 +1,12 @@
+CREATE TABLE
+  link_previews (
+    domain TEXT,
+    short_code TEXT,
+    status TEXT,
+    title TEXT,
+    description TEXT,
+    favicon_url TEXT,
+    image_url TEXT,
+    fetched_at TIMESTAMP,
+    PRIMARY KEY ((domain, short_code))
+  );
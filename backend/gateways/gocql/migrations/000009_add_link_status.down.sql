@@ -0,0 +1 @@
+ALTER TABLE links DROP (status, status_reason);
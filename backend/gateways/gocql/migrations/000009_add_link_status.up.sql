@@ -0,0 +1 @@
+ALTER TABLE links ADD (status TEXT, status_reason TEXT);
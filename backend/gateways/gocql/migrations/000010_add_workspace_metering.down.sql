@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS workspace_usage;
+
+ALTER TABLE workspaces DROP domain_count;
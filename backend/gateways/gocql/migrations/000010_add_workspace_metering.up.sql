@@ -0,0 +1,9 @@
+ALTER TABLE workspaces ADD domain_count INT;
+
+CREATE TABLE
+  workspace_usage (
+    workspace_id TEXT,
+    period TEXT,
+    links COUNTER,
+    PRIMARY KEY ((workspace_id), period)
+  );
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS interstitial_choices;
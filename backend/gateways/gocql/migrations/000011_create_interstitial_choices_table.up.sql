@@ -0,0 +1,8 @@
+CREATE TABLE
+  interstitial_choices (
+    domain TEXT,
+    short_code TEXT,
+    choice TEXT,
+    visits COUNTER,
+    PRIMARY KEY ((domain, short_code), choice)
+  );
@@ -0,0 +1 @@
+ALTER TABLE link_previews DROP favicon;
@@ -0,0 +1 @@
+ALTER TABLE link_previews ADD favicon TEXT;
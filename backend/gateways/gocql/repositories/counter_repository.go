@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"lnk/domain/entities"
+	"lnk/extensions/logger"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+	"go.uber.org/zap"
+)
+
+func (r *Repository) GetCounterCheckpoint(ctx context.Context, name string) (*entities.CounterCheckpoint, error) {
+	var checkpoint entities.CounterCheckpoint
+
+	err := r.executor.Scan(ctx, selectCounterCheckpointStatement,
+		[]any{name},
+		&checkpoint.Name, &checkpoint.ObservedValue, &checkpoint.HighWaterMark, &checkpoint.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, gocql.ErrNotFound) {
+			return nil, gocql.ErrNotFound
+		}
+
+		return nil, fmt.Errorf("failed to get counter checkpoint: %w", err)
+	}
+
+	return &checkpoint, nil
+}
+
+// AdvanceCounterCheckpoint stores the checkpoint only if it raises the
+// persisted high-water mark, so concurrent replicas can never move it back.
+func (r *Repository) AdvanceCounterCheckpoint(ctx context.Context, checkpoint *entities.CounterCheckpoint) (bool, error) {
+	checkpoint.UpdatedAt = time.Now().UTC()
+
+	inserted, err := r.executor.ExecCAS(ctx, insertCounterCheckpointStatement,
+		checkpoint.Name, checkpoint.ObservedValue, checkpoint.HighWaterMark, checkpoint.UpdatedAt,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to insert counter checkpoint: %w", err)
+	}
+
+	if inserted {
+		return true, nil
+	}
+
+	advanced, err := r.executor.ExecCAS(ctx, advanceCounterCheckpointStatement,
+		checkpoint.ObservedValue, checkpoint.HighWaterMark, checkpoint.UpdatedAt,
+		checkpoint.Name, checkpoint.HighWaterMark,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to advance counter checkpoint: %w", err)
+	}
+
+	if !advanced {
+		logger.FromContext(ctx, r.logger).Debug("Counter checkpoint already ahead",
+			zap.String("name", checkpoint.Name),
+			zap.Int64("high_water_mark", checkpoint.HighWaterMark),
+		)
+	}
+
+	return advanced, nil
+}
@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"lnk/domain/entities"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+)
+
+// PutLinkPreview stores preview, replacing an earlier one of the same link.
+func (r *Repository) PutLinkPreview(ctx context.Context, preview *entities.LinkPreview) error {
+	err := r.executor.Exec(ctx, insertLinkPreviewStatement,
+		preview.Domain, preview.ShortCode, string(preview.Status),
+		preview.Page.Title, preview.Page.Description, preview.Page.FaviconURL, preview.Page.Favicon, preview.Page.ImageURL, preview.FetchedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to put link preview: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) GetLinkPreview(ctx context.Context, domain, shortCode string) (*entities.LinkPreview, error) {
+	var (
+		preview entities.LinkPreview
+		status  string
+	)
+
+	err := r.executor.Scan(ctx, selectLinkPreviewStatement,
+		[]any{domain, shortCode},
+		&preview.Domain, &preview.ShortCode, &status,
+		&preview.Page.Title, &preview.Page.Description, &preview.Page.FaviconURL, &preview.Page.Favicon, &preview.Page.ImageURL, &preview.FetchedAt,
+	)
+	if err != nil {
+		if errors.Is(err, gocql.ErrNotFound) {
+			return nil, gocql.ErrNotFound
+		}
+
+		return nil, fmt.Errorf("failed to get link preview: %w", err)
+	}
+
+	preview.Status = entities.PreviewStatus(status)
+
+	return &preview, nil
+}
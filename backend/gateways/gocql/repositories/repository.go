@@ -1,15 +1,26 @@
 package repositories
 
 import (
+	gocqlPackage "lnk/gateways/gocql"
+
 	gocql "github.com/apache/cassandra-gocql-driver/v2"
 	"go.uber.org/zap"
 )
 
 type Repository struct {
-	logger  *zap.Logger
-	session *gocql.Session
+	logger   *zap.Logger
+	executor *gocqlPackage.Executor
 }
 
 func NewRepository(logger *zap.Logger, session *gocql.Session) *Repository {
-	return &Repository{logger: logger, session: session}
+	return &Repository{logger: logger, executor: gocqlPackage.NewExecutor(session)}
+}
+
+// WithQueryPolicy applies per-statement read/write consistency and speculative
+// execution to every query issued by the repository. Without a policy, queries
+// use the session defaults.
+func (r *Repository) WithQueryPolicy(policy gocqlPackage.QueryPolicy) *Repository {
+	r.executor.SetQueryPolicy(policy)
+
+	return r
 }
@@ -0,0 +1,274 @@
+package repositories
+
+import gocqlPackage "lnk/gateways/gocql"
+
+var (
+	insertLinkStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name:       "links.insert",
+		CQL:        "INSERT INTO links (domain, short_code, long_url, owner_id, created_at, title, notes, tags, status, status_reason) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		Idempotent: true,
+	})
+
+	selectLinkStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name:       "links.select",
+		CQL:        "SELECT domain, short_code, long_url, owner_id, created_at, title, notes, tags, updated_at, status, status_reason FROM links WHERE domain = ? AND short_code = ?",
+		Idempotent: true,
+	})
+
+	updateLinkStatusStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name: "links.update_status",
+		CQL:  "UPDATE links SET status = ?, status_reason = ? WHERE domain = ? AND short_code = ? IF EXISTS",
+	})
+
+	// Automatic flags only apply to links whose status was never set and
+	// whose destination is unchanged, so they never override an operator.
+	flagLinkStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name: "links.flag",
+		CQL:  "UPDATE links SET status = ?, status_reason = ? WHERE domain = ? AND short_code = ? IF long_url = ? AND status = null",
+	})
+
+	scanLinkStatusesStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name:       "links.scan_status",
+		CQL:        "SELECT domain, short_code, long_url, status FROM links",
+		Idempotent: true,
+	})
+
+	incrementInterstitialChoiceStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name: "interstitial_choices.increment",
+		CQL:  "UPDATE interstitial_choices SET visits = visits + 1 WHERE domain = ? AND short_code = ? AND choice = ?",
+	})
+
+	selectInterstitialChoicesStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name:       "interstitial_choices.select",
+		CQL:        "SELECT choice, visits FROM interstitial_choices WHERE domain = ? AND short_code = ?",
+		Idempotent: true,
+	})
+
+	updateLinkMetadataStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name: "links.update_metadata",
+		CQL:  "UPDATE links SET title = ?, notes = ?, tags = ?, updated_at = ? WHERE domain = ? AND short_code = ? IF updated_at = ?",
+	})
+
+	// The listing tables are written with the link's version as the write
+	// timestamp, so a delayed write of an older version never overwrites a
+	// newer one.
+	insertLinkByOwnerStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name:       "links_by_owner.insert",
+		CQL:        "INSERT INTO links_by_owner (owner_id, created_at, domain, short_code, long_url, title, notes, tags, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?) USING TIMESTAMP ?",
+		Idempotent: true,
+	})
+
+	listLinksByOwnerStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name:       "links_by_owner.list",
+		CQL:        "SELECT domain, short_code, long_url, owner_id, created_at, title, notes, tags, updated_at FROM links_by_owner WHERE owner_id = ? AND created_at >= ? AND created_at < ?",
+		Idempotent: true,
+	})
+
+	selectOwnerLinkExistsStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name:       "links_by_owner.exists",
+		CQL:        "SELECT short_code FROM links_by_owner WHERE owner_id = ? LIMIT 1",
+		Idempotent: true,
+	})
+
+	insertLinkByTagStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name:       "links_by_tag.insert",
+		CQL:        "INSERT INTO links_by_tag (owner_id, tag, created_at, domain, short_code, long_url, title, notes, tags, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?) USING TIMESTAMP ?",
+		Idempotent: true,
+	})
+
+	deleteLinkByTagStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name:       "links_by_tag.delete",
+		CQL:        "DELETE FROM links_by_tag USING TIMESTAMP ? WHERE owner_id = ? AND tag = ? AND created_at = ? AND domain = ? AND short_code = ?",
+		Idempotent: true,
+	})
+
+	listLinksByTagStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name:       "links_by_tag.list",
+		CQL:        "SELECT domain, short_code, long_url, owner_id, created_at, title, notes, tags, updated_at FROM links_by_tag WHERE owner_id = ? AND tag = ? AND created_at >= ? AND created_at < ?",
+		Idempotent: true,
+	})
+
+	scanLinksStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name:       "links.scan",
+		CQL:        "SELECT domain, short_code, long_url, owner_id, created_at, title, notes, tags, updated_at FROM links",
+		Idempotent: true,
+	})
+
+	insertOwnerLinkStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name: "links_by_owner_hash.insert",
+		CQL:  "INSERT INTO links_by_owner_hash (owner_id, domain, url_hash, short_code, created_at) VALUES (?, ?, ?, ?, ?) IF NOT EXISTS",
+	})
+
+	selectOwnerLinkStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name:       "links_by_owner_hash.select",
+		CQL:        "SELECT short_code, created_at FROM links_by_owner_hash WHERE owner_id = ? AND domain = ? AND url_hash = ?",
+		Idempotent: true,
+	})
+
+	deleteOwnerLinkStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name: "links_by_owner_hash.delete",
+		CQL:  "DELETE FROM links_by_owner_hash WHERE owner_id = ? AND domain = ? AND url_hash = ? IF short_code = ?",
+	})
+)
+
+// Statements for the tables that predate custom domains. They copy existing
+// links into the default domain and serve the links the copy has not reached.
+var (
+	scanLegacyURLsStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name:       "urls.scan",
+		CQL:        "SELECT short_code, long_url, owner_id, created_at FROM urls",
+		Idempotent: true,
+	})
+
+	selectLegacyURLStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name:       "urls.select",
+		CQL:        "SELECT short_code, long_url, owner_id, created_at FROM urls WHERE short_code = ?",
+		Idempotent: true,
+	})
+
+	scanLegacyOwnerURLsStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name:       "urls_by_owner_hash.scan",
+		CQL:        "SELECT owner_id, url_hash, short_code, created_at FROM urls_by_owner_hash",
+		Idempotent: true,
+	})
+
+	backfillLinkStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name: "links.backfill",
+		CQL:  "INSERT INTO links (domain, short_code, long_url, owner_id, created_at) VALUES (?, ?, ?, ?, ?) IF NOT EXISTS",
+	})
+)
+
+var (
+	selectCounterCheckpointStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name:       "counter_checkpoints.select",
+		CQL:        "SELECT name, observed_value, high_water_mark, updated_at FROM counter_checkpoints WHERE name = ?",
+		Idempotent: true,
+	})
+
+	insertCounterCheckpointStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name: "counter_checkpoints.insert",
+		CQL:  "INSERT INTO counter_checkpoints (name, observed_value, high_water_mark, updated_at) VALUES (?, ?, ?, ?) IF NOT EXISTS",
+	})
+
+	advanceCounterCheckpointStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name: "counter_checkpoints.advance",
+		CQL:  "UPDATE counter_checkpoints SET observed_value = ?, high_water_mark = ?, updated_at = ? WHERE name = ? IF high_water_mark < ?",
+	})
+)
+
+var (
+	insertWorkspaceStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name: "workspaces.insert",
+		CQL:  "INSERT INTO workspaces (id, name, links_per_month, max_domains, created_by, created_at) VALUES (?, ?, ?, ?, ?, ?) IF NOT EXISTS",
+	})
+
+	selectWorkspaceStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name:       "workspaces.select",
+		CQL:        "SELECT id, name, links_per_month, max_domains, created_by, created_at FROM workspaces WHERE id = ?",
+		Idempotent: true,
+	})
+
+	updateWorkspaceQuotaStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name: "workspaces.update_quota",
+		CQL:  "UPDATE workspaces SET links_per_month = ?, max_domains = ? WHERE id = ? IF EXISTS",
+	})
+
+	selectWorkspaceDomainCountStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name:       "workspaces.select_domain_count",
+		CQL:        "SELECT domain_count FROM workspaces WHERE id = ?",
+		Idempotent: true,
+	})
+
+	updateWorkspaceDomainCountStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name: "workspaces.update_domain_count",
+		CQL:  "UPDATE workspaces SET domain_count = ? WHERE id = ? IF domain_count = ?",
+	})
+
+	addWorkspaceMemberStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name: "workspace_members.add",
+		CQL:  "INSERT INTO workspace_members (workspace_id, user_id, role, created_at) VALUES (?, ?, ?, ?) IF NOT EXISTS",
+	})
+
+	updateWorkspaceMemberRoleStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name: "workspace_members.update_role",
+		CQL:  "UPDATE workspace_members SET role = ? WHERE workspace_id = ? AND user_id = ? IF EXISTS",
+	})
+
+	selectWorkspaceMemberStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name:       "workspace_members.select",
+		CQL:        "SELECT workspace_id, user_id, role, created_at FROM workspace_members WHERE workspace_id = ? AND user_id = ?",
+		Idempotent: true,
+	})
+
+	listWorkspaceMembersStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name:       "workspace_members.list",
+		CQL:        "SELECT workspace_id, user_id, role, created_at FROM workspace_members WHERE workspace_id = ?",
+		Idempotent: true,
+	})
+
+	deleteWorkspaceMemberStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name:       "workspace_members.delete",
+		CQL:        "DELETE FROM workspace_members WHERE workspace_id = ? AND user_id = ?",
+		Idempotent: true,
+	})
+
+	insertWorkspaceDomainStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name: "workspace_domains.insert",
+		CQL:  "INSERT INTO workspace_domains (domain, workspace_id, created_at) VALUES (?, ?, ?) IF NOT EXISTS",
+	})
+
+	selectWorkspaceDomainStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name:       "workspace_domains.select",
+		CQL:        "SELECT workspace_id FROM workspace_domains WHERE domain = ?",
+		Idempotent: true,
+	})
+
+	deleteWorkspaceDomainStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name: "workspace_domains.delete",
+		CQL:  "DELETE FROM workspace_domains WHERE domain = ? IF workspace_id = ?",
+	})
+
+	insertDomainByWorkspaceStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name:       "domains_by_workspace.insert",
+		CQL:        "INSERT INTO domains_by_workspace (workspace_id, domain, created_at) VALUES (?, ?, ?)",
+		Idempotent: true,
+	})
+
+	listDomainsByWorkspaceStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name:       "domains_by_workspace.list",
+		CQL:        "SELECT domain FROM domains_by_workspace WHERE workspace_id = ?",
+		Idempotent: true,
+	})
+
+	deleteDomainByWorkspaceStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name:       "domains_by_workspace.delete",
+		CQL:        "DELETE FROM domains_by_workspace WHERE workspace_id = ? AND domain = ?",
+		Idempotent: true,
+	})
+
+	// Counter updates are not idempotent, so they are never retried.
+	incrementWorkspaceUsageStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name: "workspace_usage.increment",
+		CQL:  "UPDATE workspace_usage SET links = links + ? WHERE workspace_id = ? AND period = ?",
+	})
+
+	selectWorkspaceUsageStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name:       "workspace_usage.select",
+		CQL:        "SELECT links FROM workspace_usage WHERE workspace_id = ? AND period = ?",
+		Idempotent: true,
+	})
+)
+
+var (
+	insertLinkPreviewStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name:       "link_previews.insert",
+		CQL:        "INSERT INTO link_previews (domain, short_code, status, title, description, favicon_url, favicon, image_url, fetched_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		Idempotent: true,
+	})
+
+	selectLinkPreviewStatement = gocqlPackage.MustRegister(gocqlPackage.Statement{
+		Name:       "link_previews.select",
+		CQL:        "SELECT domain, short_code, status, title, description, favicon_url, favicon, image_url, fetched_at FROM link_previews WHERE domain = ? AND short_code = ?",
+		Idempotent: true,
+	})
+)
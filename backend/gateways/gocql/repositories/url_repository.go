@@ -4,34 +4,40 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"lnk/domain/entities"
+	gocqlPackage "lnk/gateways/gocql"
 
-	"github.com/gocql/gocql"
-	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/codes"
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
 )
 
+// CreateURL writes the link. Links with an owner are also added to the
+// owner's listing and to the listing of each of their tags in the same logged
+// batch, so the tables cannot drift apart.
 func (r *Repository) CreateURL(ctx context.Context, url *entities.URL) error {
-	tracer := otel.Tracer("repositories.CreateURL")
-	ctx, span := tracer.Start(ctx, "CreateURLRepository")
+	url.CreatedAt = time.Now().UTC().Truncate(time.Millisecond)
 
-	var err error
-	defer func() {
-		if err != nil {
-			span.RecordError(err)
-			span.SetStatus(codes.Error, err.Error())
-		}
-	}()
-	defer span.End()
+	// Active links leave the status unset, so it can still be flagged
+	// automatically.
+	var status, reason any = gocql.UnsetValue, gocql.UnsetValue
+	if url.Status != "" {
+		status, reason = string(url.Status), url.StatusReason
+	}
 
-	url.CreatedAt = time.Now().UTC()
+	link := gocqlPackage.BatchEntry{
+		Statement: insertLinkStatement,
+		Values:    []any{url.Domain, url.ShortCode, url.LongURL, url.OwnerID, url.CreatedAt, url.Title, url.Notes, url.Tags, status, reason},
+	}
+
+	var err error
+	if url.OwnerID == "" {
+		err = r.executor.Exec(ctx, link.Statement, link.Values...)
+	} else {
+		err = r.executor.ExecBatch(ctx, "links.insert_with_owner", append([]gocqlPackage.BatchEntry{link}, listingEntries(url, nil)...)...)
+	}
 
-	err = r.session.Query(
-		"INSERT INTO urls (short_code, long_url, created_at) VALUES (?, ?, ?)",
-		url.ShortCode, url.LongURL, url.CreatedAt,
-	).ExecContext(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to create URL: %w", err)
 	}
@@ -39,25 +45,156 @@ func (r *Repository) CreateURL(ctx context.Context, url *entities.URL) error {
 	return nil
 }
 
-func (r *Repository) GetURLByShortCode(ctx context.Context, shortCode string) (*entities.URL, error) {
-	tracer := otel.Tracer("repositories.GetURLByShortCode")
-	ctx, span := tracer.Start(ctx, "GetURLByShortCodeRepository")
+// UpdateURLMetadata stores the title, notes and tags of updated, which must be
+// previous with edited metadata and a later UpdatedAt. The link is only
+// changed if it still has previous's UpdatedAt; UpdateURLMetadata reports
+// whether it was. The listings are updated afterwards, so when that fails the
+// link is already changed; repeating the edit repairs the listings.
+func (r *Repository) UpdateURLMetadata(ctx context.Context, previous, updated *entities.URL) (bool, error) {
+	var version any
+	if !previous.UpdatedAt.IsZero() {
+		version = previous.UpdatedAt
+	}
 
-	var err error
-	defer func() {
-		if err != nil {
-			span.RecordError(err)
-			span.SetStatus(codes.Error, err.Error())
+	applied, err := r.executor.ExecCAS(ctx, updateLinkMetadataStatement,
+		updated.Title, updated.Notes, updated.Tags, updated.UpdatedAt, updated.Domain, updated.ShortCode, version,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to update URL metadata: %w", err)
+	}
+
+	if !applied || updated.OwnerID == "" {
+		return applied, nil
+	}
+
+	if err := r.executor.ExecBatch(ctx, "links.update_listings", listingEntries(updated, previous.Tags)...); err != nil {
+		return true, fmt.Errorf("failed to update URL listings: %w", err)
+	}
+
+	return true, nil
+}
+
+// listingEntries writes url to the owner's listing and to the listing of each
+// of its tags, and removes it from the listings of removedTags it no longer
+// has.
+func listingEntries(url *entities.URL, removedTags []string) []gocqlPackage.BatchEntry {
+	writeTime := url.CreatedAt.UnixMicro()
+	if !url.UpdatedAt.IsZero() {
+		writeTime = url.UpdatedAt.UnixMicro()
+	}
+
+	// Links that were never edited leave updated_at unset rather than
+	// writing a null.
+	var updatedAt any = gocql.UnsetValue
+	if !url.UpdatedAt.IsZero() {
+		updatedAt = url.UpdatedAt
+	}
+
+	entries := []gocqlPackage.BatchEntry{{
+		Statement: insertLinkByOwnerStatement,
+		Values: []any{
+			url.OwnerID, url.CreatedAt, url.Domain, url.ShortCode, url.LongURL,
+			url.Title, url.Notes, url.Tags, updatedAt, writeTime,
+		},
+	}}
+
+	for _, tag := range removedTags {
+		if slices.Contains(url.Tags, tag) {
+			continue
 		}
-	}()
-	defer span.End()
 
-	var url entities.URL
+		entries = append(entries, gocqlPackage.BatchEntry{
+			Statement: deleteLinkByTagStatement,
+			Values:    []any{writeTime, url.OwnerID, tag, url.CreatedAt, url.Domain, url.ShortCode},
+		})
+	}
+
+	for _, tag := range url.Tags {
+		entries = append(entries, gocqlPackage.BatchEntry{
+			Statement: insertLinkByTagStatement,
+			Values: []any{
+				url.OwnerID, tag, url.CreatedAt, url.Domain, url.ShortCode, url.LongURL,
+				url.Title, url.Notes, url.Tags, updatedAt, writeTime,
+			},
+		})
+	}
+
+	return entries
+}
+
+// ListOwnerURLs returns one page of the owner's links created in [from, to),
+// newest first. pageState resumes after an earlier page; the returned state is
+// nil after the last page.
+func (r *Repository) ListOwnerURLs(ctx context.Context, ownerID string, from, to time.Time, pageSize int, pageState []byte) ([]entities.URL, []byte, error) {
+	urls, next, err := r.listURLs(ctx, listLinksByOwnerStatement, []any{ownerID, from, to}, pageSize, pageState)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list owner URLs: %w", err)
+	}
+
+	return urls, next, nil
+}
+
+// ListTagURLs is ListOwnerURLs for the owner's links tagged with tag.
+func (r *Repository) ListTagURLs(ctx context.Context, ownerID, tag string, from, to time.Time, pageSize int, pageState []byte) ([]entities.URL, []byte, error) {
+	urls, next, err := r.listURLs(ctx, listLinksByTagStatement, []any{ownerID, tag, from, to}, pageSize, pageState)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list tag URLs: %w", err)
+	}
+
+	return urls, next, nil
+}
+
+func (r *Repository) listURLs(ctx context.Context, stmt gocqlPackage.Statement, values []any, pageSize int, pageState []byte) ([]entities.URL, []byte, error) {
+	var (
+		url  entities.URL
+		urls []entities.URL
+	)
 
-	err = r.session.Query(
-		"SELECT short_code, long_url, created_at FROM urls WHERE short_code = ?",
-		shortCode,
-	).ScanContext(ctx, &url.ShortCode, &url.LongURL, &url.CreatedAt)
+	next, err := r.executor.Page(ctx, stmt, values, pageSize, pageState, urlColumns(&url), func() {
+		urls = append(urls, url)
+		url.Tags = nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return urls, next, nil
+}
+
+// urlColumns are the scan destinations of the link columns the links and
+// listing statements select.
+func urlColumns(url *entities.URL) []any {
+	return []any{
+		&url.Domain, &url.ShortCode, &url.LongURL, &url.OwnerID, &url.CreatedAt,
+		&url.Title, &url.Notes, &url.Tags, &url.UpdatedAt,
+	}
+}
+
+// OwnerHasLinks reports whether ownerID owns any listed link.
+func (r *Repository) OwnerHasLinks(ctx context.Context, ownerID string) (bool, error) {
+	var shortCode string
+
+	err := r.executor.Scan(ctx, selectOwnerLinkExistsStatement, []any{ownerID}, &shortCode)
+
+	switch {
+	case errors.Is(err, gocql.ErrNotFound):
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("failed to check owner links: %w", err)
+	}
+
+	return true, nil
+}
+
+// GetURLByShortCode returns the link with its status, which only the links
+// table holds.
+func (r *Repository) GetURLByShortCode(ctx context.Context, domain, shortCode string) (*entities.URL, error) {
+	var (
+		url    entities.URL
+		status string
+	)
+
+	err := r.executor.Scan(ctx, selectLinkStatement, []any{domain, shortCode}, append(urlColumns(&url), &status, &url.StatusReason)...)
 	if err != nil {
 		if errors.Is(err, gocql.ErrNotFound) {
 			return nil, gocql.ErrNotFound
@@ -66,5 +203,206 @@ func (r *Repository) GetURLByShortCode(ctx context.Context, shortCode string) (*
 		return nil, fmt.Errorf("failed to get URL by short code: %w", err)
 	}
 
+	url.Status = entities.LinkStatus(status)
+
 	return &url, nil
 }
+
+// GetLegacyURL returns a link from the table that predates custom domains,
+// without a domain.
+func (r *Repository) GetLegacyURL(ctx context.Context, shortCode string) (*entities.URL, error) {
+	var url entities.URL
+
+	err := r.executor.Scan(ctx, selectLegacyURLStatement, []any{shortCode}, &url.ShortCode, &url.LongURL, &url.OwnerID, &url.CreatedAt)
+	if err != nil {
+		if errors.Is(err, gocql.ErrNotFound) {
+			return nil, gocql.ErrNotFound
+		}
+
+		return nil, fmt.Errorf("failed to get legacy URL: %w", err)
+	}
+
+	return &url, nil
+}
+
+// SetURLStatus changes the status of a link and reports whether the link
+// exists.
+func (r *Repository) SetURLStatus(ctx context.Context, domain, shortCode string, status entities.LinkStatus, reason string) (bool, error) {
+	applied, err := r.executor.ExecCAS(ctx, updateLinkStatusStatement, string(status), reason, domain, shortCode)
+	if err != nil {
+		return false, fmt.Errorf("failed to set URL status: %w", err)
+	}
+
+	return applied, nil
+}
+
+// FlagURL sets the status of url, unless its status was set before or its
+// destination changed, and reports whether it did.
+func (r *Repository) FlagURL(ctx context.Context, url *entities.URL, status entities.LinkStatus, reason string) (bool, error) {
+	applied, err := r.executor.ExecCAS(ctx, flagLinkStatement, string(status), reason, url.Domain, url.ShortCode, url.LongURL)
+	if err != nil {
+		return false, fmt.Errorf("failed to flag URL: %w", err)
+	}
+
+	return applied, nil
+}
+
+// RecordInterstitialChoice counts a visitor's choice on the warning of a link.
+func (r *Repository) RecordInterstitialChoice(ctx context.Context, domain, shortCode, choice string) error {
+	if err := r.executor.Exec(ctx, incrementInterstitialChoiceStatement, domain, shortCode, choice); err != nil {
+		return fmt.Errorf("failed to record interstitial choice: %w", err)
+	}
+
+	return nil
+}
+
+// GetInterstitialChoices returns the counted choices on the warning of a link.
+func (r *Repository) GetInterstitialChoices(ctx context.Context, domain, shortCode string) (map[string]int64, error) {
+	var (
+		choice string
+		visits int64
+	)
+
+	choices := make(map[string]int64)
+
+	err := r.executor.Each(ctx, selectInterstitialChoicesStatement, []any{domain, shortCode}, []any{&choice, &visits}, func() error {
+		choices[choice] = visits
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get interstitial choices: %w", err)
+	}
+
+	return choices, nil
+}
+
+// EachURLStatus calls fn with the domain, short code, destination and status
+// of every link. The URL is reused between calls.
+func (r *Repository) EachURLStatus(ctx context.Context, fn func(*entities.URL) error) error {
+	var (
+		url    entities.URL
+		status string
+	)
+
+	err := r.executor.Each(ctx, scanLinkStatusesStatement, nil, []any{&url.Domain, &url.ShortCode, &url.LongURL, &status}, func() error {
+		url.Status = entities.LinkStatus(status)
+
+		return fn(&url)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to scan URL statuses: %w", err)
+	}
+
+	return nil
+}
+
+// ClaimOwnerURL records shortCode as the owner's link for urlHash on domain
+// unless the owner already has one. It reports whether the claim was applied.
+func (r *Repository) ClaimOwnerURL(ctx context.Context, ownerID, domain, urlHash, shortCode string) (bool, error) {
+	claimed, err := r.executor.ExecCAS(ctx, insertOwnerLinkStatement, ownerID, domain, urlHash, shortCode, time.Now().UTC())
+	if err != nil {
+		return false, fmt.Errorf("failed to claim owner URL: %w", err)
+	}
+
+	return claimed, nil
+}
+
+// GetShortCodeByOwnerURL returns the owner's short code for urlHash on domain
+// and when it was claimed.
+func (r *Repository) GetShortCodeByOwnerURL(ctx context.Context, ownerID, domain, urlHash string) (string, time.Time, error) {
+	var (
+		shortCode string
+		claimedAt time.Time
+	)
+
+	err := r.executor.Scan(ctx, selectOwnerLinkStatement, []any{ownerID, domain, urlHash}, &shortCode, &claimedAt)
+	if err != nil {
+		if errors.Is(err, gocql.ErrNotFound) {
+			return "", time.Time{}, gocql.ErrNotFound
+		}
+
+		return "", time.Time{}, fmt.Errorf("failed to get short code by owner URL: %w", err)
+	}
+
+	return shortCode, claimedAt, nil
+}
+
+// ReleaseOwnerURL removes the owner's lookup for urlHash if it still points at
+// shortCode, so a concurrent claim for another code is never removed.
+func (r *Repository) ReleaseOwnerURL(ctx context.Context, ownerID, domain, urlHash, shortCode string) error {
+	if _, err := r.executor.ExecCAS(ctx, deleteOwnerLinkStatement, ownerID, domain, urlHash, shortCode); err != nil {
+		return fmt.Errorf("failed to release owner URL: %w", err)
+	}
+
+	return nil
+}
+
+// BackfillOwnerListing adds links created before owner listings to the
+// listings of their owner and tags. Re-running it rewrites the same rows. It
+// returns the number of links indexed.
+func (r *Repository) BackfillOwnerListing(ctx context.Context) (int, error) {
+	var (
+		url     entities.URL
+		indexed int
+	)
+
+	err := r.executor.Each(ctx, scanLinksStatement, nil, urlColumns(&url), func() error {
+		defer func() { url.Tags = nil }()
+
+		if url.OwnerID == "" {
+			return nil
+		}
+
+		indexed++
+
+		return r.executor.ExecBatch(ctx, "links.backfill_listings", listingEntries(&url, nil)...)
+	})
+	if err != nil {
+		return indexed, fmt.Errorf("failed to backfill owner listings: %w", err)
+	}
+
+	return indexed, nil
+}
+
+// BackfillDefaultDomain copies links and owner lookups created before custom
+// domains into domain. Rows that already exist are left alone, so the
+// backfill can be re-run safely. It returns the number of links copied.
+func (r *Repository) BackfillDefaultDomain(ctx context.Context, domain string) (int, error) {
+	var (
+		url    entities.URL
+		copied int
+	)
+
+	err := r.executor.Each(ctx, scanLegacyURLsStatement, nil,
+		[]any{&url.ShortCode, &url.LongURL, &url.OwnerID, &url.CreatedAt},
+		func() error {
+			applied, err := r.executor.ExecCAS(ctx, backfillLinkStatement, domain, url.ShortCode, url.LongURL, url.OwnerID, url.CreatedAt)
+			if applied {
+				copied++
+			}
+
+			return err
+		},
+	)
+	if err != nil {
+		return copied, fmt.Errorf("failed to backfill links: %w", err)
+	}
+
+	var ownerID, urlHash, shortCode string
+
+	var createdAt time.Time
+
+	err = r.executor.Each(ctx, scanLegacyOwnerURLsStatement, nil,
+		[]any{&ownerID, &urlHash, &shortCode, &createdAt},
+		func() error {
+			_, err := r.executor.ExecCAS(ctx, insertOwnerLinkStatement, ownerID, domain, urlHash, shortCode, createdAt)
+			return err
+		},
+	)
+	if err != nil {
+		return copied, fmt.Errorf("failed to backfill owner URL lookups: %w", err)
+	}
+
+	return copied, nil
+}
@@ -0,0 +1,277 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"lnk/domain/entities"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+)
+
+// CreateWorkspace records workspace unless its ID is taken. It reports
+// whether the workspace was created.
+func (r *Repository) CreateWorkspace(ctx context.Context, workspace *entities.Workspace) (bool, error) {
+	workspace.CreatedAt = time.Now().UTC()
+
+	created, err := r.executor.ExecCAS(ctx, insertWorkspaceStatement,
+		workspace.ID, workspace.Name, workspace.Quota.LinksPerMonth, workspace.Quota.Domains, workspace.CreatedBy, workspace.CreatedAt,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to create workspace: %w", err)
+	}
+
+	return created, nil
+}
+
+func (r *Repository) GetWorkspace(ctx context.Context, id string) (*entities.Workspace, error) {
+	var workspace entities.Workspace
+
+	err := r.executor.Scan(ctx, selectWorkspaceStatement,
+		[]any{id},
+		&workspace.ID, &workspace.Name, &workspace.Quota.LinksPerMonth, &workspace.Quota.Domains, &workspace.CreatedBy, &workspace.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, gocql.ErrNotFound) {
+			return nil, gocql.ErrNotFound
+		}
+
+		return nil, fmt.Errorf("failed to get workspace: %w", err)
+	}
+
+	return &workspace, nil
+}
+
+// SetWorkspaceQuota replaces the quota of an existing workspace. It reports
+// whether the workspace exists.
+func (r *Repository) SetWorkspaceQuota(ctx context.Context, id string, quota entities.WorkspaceQuota) (bool, error) {
+	updated, err := r.executor.ExecCAS(ctx, updateWorkspaceQuotaStatement, quota.LinksPerMonth, quota.Domains, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to set workspace quota: %w", err)
+	}
+
+	return updated, nil
+}
+
+// workspaceDomainCountAttempts bounds the compare-and-set retries of
+// AdjustWorkspaceDomainCount under contention.
+const workspaceDomainCountAttempts = 5
+
+// AdjustWorkspaceDomainCount adds delta to the number of custom domains the
+// workspace holds unless that would exceed limit, where 0 means unlimited,
+// and reports whether it did. The count is changed with a lightweight
+// transaction, so concurrent requests cannot overrun the limit. Workspaces
+// created before the count existed start from held.
+func (r *Repository) AdjustWorkspaceDomainCount(ctx context.Context, id string, delta, limit, held int) (bool, error) {
+	for range workspaceDomainCountAttempts {
+		var current *int
+
+		err := r.executor.Scan(ctx, selectWorkspaceDomainCountStatement, []any{id}, &current)
+		if err != nil {
+			return false, fmt.Errorf("failed to get workspace domain count: %w", err)
+		}
+
+		count := held
+		if current != nil {
+			count = *current
+		}
+
+		next := max(count+delta, 0)
+		if delta > 0 && limit > 0 && next > limit {
+			return false, nil
+		}
+
+		applied, err := r.executor.ExecCAS(ctx, updateWorkspaceDomainCountStatement, next, id, current)
+		if err != nil {
+			return false, fmt.Errorf("failed to update workspace domain count: %w", err)
+		}
+
+		if applied {
+			return true, nil
+		}
+	}
+
+	return false, fmt.Errorf("failed to update workspace domain count after %d attempts", workspaceDomainCountAttempts)
+}
+
+// RecordWorkspaceUsage adds links to the durable usage of the workspace in
+// period. Redis enforces the quota; this counter is the record kept for
+// billing after the Redis keys expire.
+func (r *Repository) RecordWorkspaceUsage(ctx context.Context, workspaceID, period string, links int64) error {
+	if err := r.executor.Exec(ctx, incrementWorkspaceUsageStatement, links, workspaceID, period); err != nil {
+		return fmt.Errorf("failed to record workspace usage: %w", err)
+	}
+
+	return nil
+}
+
+// GetWorkspaceUsage returns the links recorded for the workspace in period,
+// which is 0 before the first one.
+func (r *Repository) GetWorkspaceUsage(ctx context.Context, workspaceID, period string) (int64, error) {
+	var links int64
+
+	err := r.executor.Scan(ctx, selectWorkspaceUsageStatement, []any{workspaceID, period}, &links)
+	if err != nil && !errors.Is(err, gocql.ErrNotFound) {
+		return 0, fmt.Errorf("failed to get workspace usage: %w", err)
+	}
+
+	return links, nil
+}
+
+// SetWorkspaceMemberRole changes the role of an existing member. It reports
+// whether the member exists.
+func (r *Repository) SetWorkspaceMemberRole(ctx context.Context, member *entities.WorkspaceMember) (bool, error) {
+	updated, err := r.executor.ExecCAS(ctx, updateWorkspaceMemberRoleStatement, string(member.Role), member.WorkspaceID, member.UserID)
+	if err != nil {
+		return false, fmt.Errorf("failed to set workspace member role: %w", err)
+	}
+
+	return updated, nil
+}
+
+// AddWorkspaceMember adds member unless the user already has a membership in
+// the workspace. It reports whether the member was added.
+func (r *Repository) AddWorkspaceMember(ctx context.Context, member *entities.WorkspaceMember) (bool, error) {
+	if member.CreatedAt.IsZero() {
+		member.CreatedAt = time.Now().UTC()
+	}
+
+	added, err := r.executor.ExecCAS(ctx, addWorkspaceMemberStatement, member.WorkspaceID, member.UserID, string(member.Role), member.CreatedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to add workspace member: %w", err)
+	}
+
+	return added, nil
+}
+
+func (r *Repository) GetWorkspaceMember(ctx context.Context, workspaceID, userID string) (*entities.WorkspaceMember, error) {
+	var (
+		member entities.WorkspaceMember
+		role   string
+	)
+
+	err := r.executor.Scan(ctx, selectWorkspaceMemberStatement,
+		[]any{workspaceID, userID},
+		&member.WorkspaceID, &member.UserID, &role, &member.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, gocql.ErrNotFound) {
+			return nil, gocql.ErrNotFound
+		}
+
+		return nil, fmt.Errorf("failed to get workspace member: %w", err)
+	}
+
+	member.Role = entities.Role(role)
+
+	return &member, nil
+}
+
+func (r *Repository) ListWorkspaceMembers(ctx context.Context, workspaceID string) ([]entities.WorkspaceMember, error) {
+	var (
+		member  entities.WorkspaceMember
+		role    string
+		members []entities.WorkspaceMember
+	)
+
+	err := r.executor.Each(ctx, listWorkspaceMembersStatement, []any{workspaceID},
+		[]any{&member.WorkspaceID, &member.UserID, &role, &member.CreatedAt},
+		func() error {
+			member.Role = entities.Role(role)
+			members = append(members, member)
+
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspace members: %w", err)
+	}
+
+	return members, nil
+}
+
+func (r *Repository) DeleteWorkspaceMember(ctx context.Context, workspaceID, userID string) error {
+	if err := r.executor.Exec(ctx, deleteWorkspaceMemberStatement, workspaceID, userID); err != nil {
+		return fmt.Errorf("failed to delete workspace member: %w", err)
+	}
+
+	return nil
+}
+
+// ClaimWorkspaceDomain assigns domain to the workspace unless another
+// workspace holds it. It reports whether the workspace holds the domain
+// afterwards, so repeating a claim is harmless.
+func (r *Repository) ClaimWorkspaceDomain(ctx context.Context, workspaceID, domain string) (bool, error) {
+	now := time.Now().UTC()
+
+	claimed, err := r.executor.ExecCAS(ctx, insertWorkspaceDomainStatement, domain, workspaceID, now)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim workspace domain: %w", err)
+	}
+
+	if !claimed {
+		holder, err := r.GetDomainWorkspace(ctx, domain)
+		if err != nil {
+			return false, err
+		}
+
+		if holder != workspaceID {
+			return false, nil
+		}
+	}
+
+	if err := r.executor.Exec(ctx, insertDomainByWorkspaceStatement, workspaceID, domain, now); err != nil {
+		return false, fmt.Errorf("failed to index workspace domain: %w", err)
+	}
+
+	return true, nil
+}
+
+// GetDomainWorkspace returns the ID of the workspace holding domain.
+func (r *Repository) GetDomainWorkspace(ctx context.Context, domain string) (string, error) {
+	var workspaceID string
+
+	err := r.executor.Scan(ctx, selectWorkspaceDomainStatement, []any{domain}, &workspaceID)
+	if err != nil {
+		if errors.Is(err, gocql.ErrNotFound) {
+			return "", gocql.ErrNotFound
+		}
+
+		return "", fmt.Errorf("failed to get domain workspace: %w", err)
+	}
+
+	return workspaceID, nil
+}
+
+func (r *Repository) ListWorkspaceDomains(ctx context.Context, workspaceID string) ([]string, error) {
+	var (
+		domain  string
+		domains []string
+	)
+
+	err := r.executor.Each(ctx, listDomainsByWorkspaceStatement, []any{workspaceID}, []any{&domain}, func() error {
+		domains = append(domains, domain)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspace domains: %w", err)
+	}
+
+	return domains, nil
+}
+
+// ReleaseWorkspaceDomain unassigns domain if the workspace still holds it.
+func (r *Repository) ReleaseWorkspaceDomain(ctx context.Context, workspaceID, domain string) error {
+	if _, err := r.executor.ExecCAS(ctx, deleteWorkspaceDomainStatement, domain, workspaceID); err != nil {
+		return fmt.Errorf("failed to release workspace domain: %w", err)
+	}
+
+	if err := r.executor.Exec(ctx, deleteDomainByWorkspaceStatement, workspaceID, domain); err != nil {
+		return fmt.Errorf("failed to unindex workspace domain: %w", err)
+	}
+
+	return nil
+}
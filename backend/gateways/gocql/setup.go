@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/url"
 	"strconv"
 	"time"
 
@@ -16,21 +17,16 @@ import (
 	"go.uber.org/zap"
 )
 
-const (
-	connectTimeout  = 30 * time.Second
-	timeout         = 10 * time.Second
-	shutdownTimeout = 5 * time.Second
-)
-
 func SetupDatabase(config *Config, logger *zap.Logger, autoMigrate bool) (*gocql.Session, error) {
-	cluster := gocql.NewCluster(config.Host)
-	cluster.Port = config.Port
-	cluster.Authenticator = gocql.PasswordAuthenticator{
-		Username: config.Username,
-		Password: config.Password,
+	cluster, err := newClusterConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cluster config: %w", err)
+	}
+
+	replication, err := replicationClause(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid replication config: %w", err)
 	}
-	cluster.Consistency = gocql.Quorum
-	cluster.ConnectTimeout = connectTimeout
 
 	session, err := createSessionWithRetry(cluster)
 	if err != nil {
@@ -38,8 +34,8 @@ func SetupDatabase(config *Config, logger *zap.Logger, autoMigrate bool) (*gocql
 	}
 
 	createKeyspaceQuery := fmt.Sprintf(
-		"CREATE KEYSPACE IF NOT EXISTS %s WITH replication = {'class': 'SimpleStrategy', 'replication_factor': 1}",
-		config.Keyspace,
+		"CREATE KEYSPACE IF NOT EXISTS %s WITH replication = %s",
+		config.Keyspace, replication,
 	)
 
 	execErr := session.Query(createKeyspaceQuery).Exec()
@@ -100,10 +96,7 @@ func runMigrations(config *Config, logger *zap.Logger) error {
 		return fmt.Errorf("failed to load embedded migrations: %w", err)
 	}
 
-	hostPort := net.JoinHostPort(config.Host, strconv.Itoa(config.Port))
-	migrationURL := fmt.Sprintf("cassandra://%s/%s?x-multi-statement=true", hostPort, config.Keyspace)
-
-	m, err := migrate.NewWithSourceInstance("iofs", sourceDriver, migrationURL)
+	m, err := migrate.NewWithSourceInstance("iofs", sourceDriver, migrationURL(config))
 	if err != nil {
 		logger.Error("failed to create migration instance", zap.Error(err))
 		return fmt.Errorf("failed to create migration instance: %w", err)
@@ -129,3 +122,40 @@ func runMigrations(config *Config, logger *zap.Logger) error {
 
 	return nil
 }
+
+// migrationURL builds the golang-migrate connection string. Migrations only
+// need a single contact point, so the first configured host is used.
+func migrationURL(config *Config) string {
+	params := url.Values{}
+	params.Set("x-multi-statement", "true")
+	params.Set("username", config.Username)
+	params.Set("password", config.Password)
+
+	if config.TLSEnabled {
+		sslMode := "verify-ca"
+		if config.TLSHostVerification {
+			sslMode = "verify-full"
+		}
+
+		params.Set("sslmode", sslMode)
+
+		setIfNotEmpty(params, "sslcert", config.TLSCertPath)
+		setIfNotEmpty(params, "sslkey", config.TLSKeyPath)
+		setIfNotEmpty(params, "sslrootcert", config.TLSCAPath)
+	}
+
+	migrationURL := url.URL{
+		Scheme:   "cassandra",
+		Host:     net.JoinHostPort(config.Hosts[0], strconv.Itoa(config.Port)),
+		Path:     "/" + config.Keyspace,
+		RawQuery: params.Encode(),
+	}
+
+	return migrationURL.String()
+}
+
+func setIfNotEmpty(params url.Values, key, value string) {
+	if value != "" {
+		params.Set(key, value)
+	}
+}
@@ -0,0 +1,86 @@
+package gocql
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Statement is a named CQL statement registered once at package init.
+// Idempotent statements are safe for the driver to retry and to execute
+// speculatively.
+type Statement struct {
+	Name       string
+	CQL        string
+	Operation  string
+	Idempotent bool
+}
+
+// IsRead reports whether the statement reads data and should use the read
+// consistency level.
+func (s Statement) IsRead() bool {
+	return s.Operation == "SELECT"
+}
+
+type registry struct {
+	statements map[string]Statement
+	mu         sync.RWMutex
+}
+
+var statementRegistry = &registry{statements: map[string]Statement{}} //nolint:gochecknoglobals
+
+// Register adds a statement to the registry. Names must be unique.
+func Register(stmt Statement) (Statement, error) {
+	if stmt.Name == "" || stmt.CQL == "" {
+		return Statement{}, fmt.Errorf("statement name and CQL are required")
+	}
+
+	stmt.CQL = strings.TrimSpace(stmt.CQL)
+	stmt.Operation = operationOf(stmt.CQL)
+
+	statementRegistry.mu.Lock()
+	defer statementRegistry.mu.Unlock()
+
+	if _, exists := statementRegistry.statements[stmt.Name]; exists {
+		return Statement{}, fmt.Errorf("statement %q already registered", stmt.Name)
+	}
+
+	statementRegistry.statements[stmt.Name] = stmt
+
+	return stmt, nil
+}
+
+// MustRegister is like Register but panics on error. It is meant for
+// package-level statement declarations.
+func MustRegister(stmt Statement) Statement {
+	registered, err := Register(stmt)
+	if err != nil {
+		panic(err)
+	}
+
+	return registered
+}
+
+// Statements returns every registered statement sorted by name.
+func Statements() []Statement {
+	statementRegistry.mu.RLock()
+	defer statementRegistry.mu.RUnlock()
+
+	statements := make([]Statement, 0, len(statementRegistry.statements))
+	for _, stmt := range statementRegistry.statements {
+		statements = append(statements, stmt)
+	}
+
+	sort.Slice(statements, func(i, j int) bool {
+		return statements[i].Name < statements[j].Name
+	})
+
+	return statements
+}
+
+func operationOf(cql string) string {
+	operation, _, _ := strings.Cut(cql, " ")
+
+	return strings.ToUpper(operation)
+}
@@ -0,0 +1,29 @@
+package gocql_test
+
+import (
+	"testing"
+
+	gocqlPackage "lnk/gateways/gocql"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Register(t *testing.T) {
+	t.Parallel()
+
+	stmt, err := gocqlPackage.Register(gocqlPackage.Statement{
+		Name:       "test.select",
+		CQL:        "  select short_code FROM urls WHERE short_code = ?",
+		Idempotent: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "SELECT", stmt.Operation)
+	require.True(t, stmt.IsRead())
+	require.Contains(t, gocqlPackage.Statements(), stmt)
+
+	_, err = gocqlPackage.Register(gocqlPackage.Statement{Name: "test.select", CQL: "SELECT 1"})
+	require.Error(t, err)
+
+	_, err = gocqlPackage.Register(gocqlPackage.Statement{Name: "test.empty"})
+	require.Error(t, err)
+}
@@ -0,0 +1,28 @@
+package http
+
+import (
+	"lnk/gateways/http/handlers"
+	"lnk/gateways/http/middleware"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type AdminRouterConfig struct {
+	Logger  *zap.Logger
+	Handler *handlers.AdminHandler
+}
+
+// NewAdminRouter builds the router for the private admin listener. It carries
+// profiling and runtime controls and must never be bound to a public address.
+func NewAdminRouter(cfg AdminRouterConfig) *gin.Engine {
+	router := gin.New()
+
+	router.Use(middleware.RequestID())
+	router.Use(middleware.Recovery(cfg.Logger))
+	router.Use(middleware.RequestLogger(cfg.Logger))
+
+	cfg.Handler.RegisterRoutes(router)
+
+	return router
+}
@@ -0,0 +1,180 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"lnk/domain/entities"
+	"lnk/domain/entities/usecases"
+	httpServer "lnk/gateways/http"
+	"lnk/gateways/http/handlers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type fakeCounter struct{}
+
+func (fakeCounter) CounterStatus(context.Context) (*usecases.CounterStatus, error) {
+	return &usecases.CounterStatus{Key: "counter", RedisValue: 42, HighWaterMark: 40, Healthy: true}, nil
+}
+
+type fakeWorkspaceQuotas struct{}
+
+func (fakeWorkspaceQuotas) SetWorkspaceQuota(_ context.Context, id string, quota entities.WorkspaceQuota) (*entities.Workspace, error) {
+	if id != "acme" {
+		return nil, usecases.ErrWorkspaceNotFound
+	}
+
+	return &entities.Workspace{ID: id, Quota: quota}, nil
+}
+
+type fakeLinkModerator struct{}
+
+func (fakeLinkModerator) SetLinkStatus(_ context.Context, domain, _ string, status entities.LinkStatus, _ string) error {
+	if domain != "lnk.example" {
+		return usecases.ErrURLNotFound
+	}
+
+	if !status.Valid() {
+		return usecases.ErrInvalidLinkStatus
+	}
+
+	return nil
+}
+
+func (fakeLinkModerator) InterstitialChoices(context.Context, string, string) (map[usecases.InterstitialChoice]int64, error) {
+	return map[usecases.InterstitialChoice]int64{usecases.InterstitialShown: 3, usecases.InterstitialContinued: 1}, nil
+}
+
+// fakeCachePurger records the purged short codes; "*" stands for all.
+type fakeCachePurger struct {
+	purged []string
+}
+
+func (p *fakeCachePurger) Purge(_ context.Context, shortCode string) error {
+	p.purged = append(p.purged, shortCode)
+	return nil
+}
+
+func (p *fakeCachePurger) PurgeAll(context.Context) error {
+	p.purged = append(p.purged, "*")
+	return nil
+}
+
+func newAdminRouter(level zap.AtomicLevel) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	return httpServer.NewAdminRouter(httpServer.AdminRouterConfig{
+		Logger: zap.NewNop(),
+		Handler: handlers.NewAdminHandler(handlers.NewAdminHandlerParams{
+			Logger:     zap.NewNop(),
+			Counter:    fakeCounter{},
+			Workspaces: fakeWorkspaceQuotas{},
+			Links:      fakeLinkModerator{},
+			Level:      level,
+		}),
+	})
+}
+
+func serve(router http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	router.ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+func Test_AdminRouter(t *testing.T) {
+	t.Parallel()
+
+	level := zap.NewAtomicLevelAt(zapcore.InfoLevel)
+	router := newAdminRouter(level)
+
+	t.Run("changes the log level at runtime", func(t *testing.T) {
+		recorder := serve(router, http.MethodPut, "/log/level", `{"level":"debug"}`)
+		require.Equal(t, http.StatusOK, recorder.Code)
+		require.Equal(t, zapcore.DebugLevel, level.Level())
+	})
+
+	t.Run("serves pprof", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, serve(router, http.MethodGet, "/debug/pprof/", "").Code)
+		require.Equal(t, http.StatusOK, serve(router, http.MethodGet, "/debug/pprof/goroutine", "").Code)
+	})
+
+	t.Run("reports the counter", func(t *testing.T) {
+		recorder := serve(router, http.MethodGet, "/counter", "")
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		var status usecases.CounterStatus
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &status))
+		require.Equal(t, int64(42), status.RedisValue)
+	})
+
+	t.Run("sets workspace quotas", func(t *testing.T) {
+		recorder := serve(router, http.MethodPut, "/workspaces/acme/quota", `{"links_per_month":500,"domains":2}`)
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		var workspace handlers.WorkspaceResponse
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &workspace))
+		require.Equal(t, int64(500), workspace.Quota.LinksPerMonth)
+		require.Equal(t, 2, workspace.Quota.Domains)
+
+		require.Equal(t, http.StatusNotFound, serve(router, http.MethodPut, "/workspaces/globex/quota", `{}`).Code)
+		require.Equal(t, http.StatusBadRequest, serve(router, http.MethodPut, "/workspaces/acme/quota", `{"domains":-1}`).Code)
+	})
+
+	t.Run("sets link statuses", func(t *testing.T) {
+		require.Equal(t, http.StatusNoContent, serve(router, http.MethodPut, "/links/lnk.example/abc123/status", `{"status":"warned","reason":"Phishing"}`).Code)
+		require.Equal(t, http.StatusNotFound, serve(router, http.MethodPut, "/links/go.example/abc123/status", `{"status":"warned"}`).Code)
+		require.Equal(t, http.StatusBadRequest, serve(router, http.MethodPut, "/links/lnk.example/abc123/status", `{"status":"quarantined"}`).Code)
+	})
+
+	t.Run("reports interstitial choices", func(t *testing.T) {
+		recorder := serve(router, http.MethodGet, "/links/lnk.example/abc123/interstitial", "")
+		require.Equal(t, http.StatusOK, recorder.Code)
+		require.JSONEq(t, `{"shown":3,"continued":1}`, recorder.Body.String())
+	})
+
+	t.Run("rejects cache purge without a cache", func(t *testing.T) {
+		require.Equal(t, http.StatusNotImplemented, serve(router, http.MethodDelete, "/cache/abc", "").Code)
+	})
+
+	t.Run("purges the cache", func(t *testing.T) {
+		cache := &fakeCachePurger{}
+		router := httpServer.NewAdminRouter(httpServer.AdminRouterConfig{
+			Logger: zap.NewNop(),
+			Handler: handlers.NewAdminHandler(handlers.NewAdminHandlerParams{
+				Logger:  zap.NewNop(),
+				Counter: fakeCounter{},
+				Cache:   cache,
+				Level:   level,
+			}),
+		})
+
+		require.Equal(t, http.StatusNoContent, serve(router, http.MethodDelete, "/cache/abc", "").Code)
+		require.Equal(t, http.StatusNoContent, serve(router, http.MethodDelete, "/cache", "").Code)
+		require.Equal(t, []string{"abc", "*"}, cache.purged)
+	})
+}
+
+func Test_PublicRouter_HidesAdminRoutes(t *testing.T) {
+	t.Parallel()
+
+	router := httpServer.NewRouter(httpServer.RouterConfig{
+		Logger:   zap.NewNop(),
+		GinMode:  gin.TestMode,
+		Env:      "production",
+		Handlers: handlers.NewHandlers(handlers.NewHandlersParams{Logger: zap.NewNop()}),
+	})
+
+	for _, path := range []string{"/debug/pprof/", "/debug/vars", "/log/level"} {
+		require.Equal(t, http.StatusNotFound, serve(router, http.MethodGet, path, "").Code, path)
+	}
+}
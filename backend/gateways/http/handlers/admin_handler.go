@@ -0,0 +1,299 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+
+	"lnk/domain/entities"
+	"lnk/domain/entities/usecases"
+	"lnk/extensions/buildinfo"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// CounterInspector reports the state of the short-code counter.
+type CounterInspector interface {
+	CounterStatus(ctx context.Context) (*usecases.CounterStatus, error)
+}
+
+// CachePurger evicts what is cached for short links, such as rendered QR
+// codes. Redirects are not cached on the server. Without a purger the purge
+// endpoints answer 501.
+type CachePurger interface {
+	Purge(ctx context.Context, shortCode string) error
+	PurgeAll(ctx context.Context) error
+}
+
+// WorkspaceQuotas changes the quotas of workspaces.
+type WorkspaceQuotas interface {
+	SetWorkspaceQuota(ctx context.Context, id string, quota entities.WorkspaceQuota) (*entities.Workspace, error)
+}
+
+// LinkModerator changes the status of links and reports how visitors answered
+// their warnings.
+type LinkModerator interface {
+	SetLinkStatus(ctx context.Context, domain, shortCode string, status entities.LinkStatus, reason string) error
+	InterstitialChoices(ctx context.Context, domain, shortCode string) (map[usecases.InterstitialChoice]int64, error)
+}
+
+// AdminHandler serves the operational endpoints of the admin listener. None of
+// them are registered on the public router.
+type AdminHandler struct {
+	logger     *zap.Logger
+	counter    CounterInspector
+	cache      CachePurger
+	workspaces WorkspaceQuotas
+	links      LinkModerator
+	metrics    http.Handler
+	level      zap.AtomicLevel
+}
+
+type NewAdminHandlerParams struct {
+	Logger  *zap.Logger
+	Counter CounterInspector
+	Cache   CachePurger
+	// Workspaces serves the quota endpoint when set.
+	Workspaces WorkspaceQuotas
+	// Links serves the link status endpoints when set.
+	Links LinkModerator
+	// Metrics serves the Prometheus scrape endpoint when set.
+	Metrics http.Handler
+	Level   zap.AtomicLevel
+}
+
+func NewAdminHandler(params NewAdminHandlerParams) *AdminHandler {
+	return &AdminHandler{
+		logger:     params.Logger,
+		counter:    params.Counter,
+		cache:      params.Cache,
+		workspaces: params.Workspaces,
+		links:      params.Links,
+		metrics:    params.Metrics,
+		level:      params.Level,
+	}
+}
+
+func (h *AdminHandler) RegisterRoutes(router *gin.Engine) {
+	debug := router.Group("/debug")
+	debug.GET("/pprof/", gin.WrapF(pprof.Index))
+	debug.GET("/pprof/cmdline", gin.WrapF(pprof.Cmdline))
+	debug.GET("/pprof/profile", gin.WrapF(pprof.Profile))
+	debug.GET("/pprof/symbol", gin.WrapF(pprof.Symbol))
+	debug.POST("/pprof/symbol", gin.WrapF(pprof.Symbol))
+	debug.GET("/pprof/trace", gin.WrapF(pprof.Trace))
+	debug.GET("/pprof/:profile", h.profile)
+	debug.GET("/vars", gin.WrapH(expvar.Handler()))
+	debug.GET("/runtime", h.Runtime)
+
+	// AtomicLevel serves GET and PUT with a {"level": "debug"} body.
+	router.GET("/log/level", gin.WrapH(h.level))
+	router.PUT("/log/level", gin.WrapH(h.level))
+
+	router.DELETE("/cache", h.PurgeCache)
+	router.DELETE("/cache/:short_code", h.PurgeCache)
+
+	if h.metrics != nil {
+		router.GET("/metrics", gin.WrapH(h.metrics))
+	}
+
+	if h.workspaces != nil {
+		router.PUT("/workspaces/:id/quota", h.SetWorkspaceQuota)
+	}
+
+	if h.links != nil {
+		router.PUT("/links/:domain/:short_code/status", h.SetLinkStatus)
+		router.GET("/links/:domain/:short_code/interstitial", h.InterstitialChoices)
+	}
+
+	router.GET("/counter", h.Counter)
+	router.GET("/version", h.Version)
+}
+
+// profile serves the named runtime profiles such as heap, goroutine, allocs,
+// block, mutex and threadcreate.
+func (h *AdminHandler) profile(c *gin.Context) {
+	pprof.Handler(c.Param("profile")).ServeHTTP(c.Writer, c.Request)
+}
+
+// RuntimeStats is a snapshot of the Go runtime.
+type RuntimeStats struct {
+	GoVersion     string `json:"go_version"`
+	Goroutines    int    `json:"goroutines"`
+	GOMAXPROCS    int    `json:"gomaxprocs"`
+	HeapAlloc     uint64 `json:"heap_alloc_bytes"`
+	HeapInuse     uint64 `json:"heap_inuse_bytes"`
+	HeapObjects   uint64 `json:"heap_objects"`
+	Sys           uint64 `json:"sys_bytes"`
+	PauseTotalNs  uint64 `json:"gc_pause_total_ns"`
+	LastGCUnixNs  uint64 `json:"last_gc_unix_ns"`
+	NumGC         uint32 `json:"num_gc"`
+	NextGCTrigger uint64 `json:"next_gc_bytes"`
+}
+
+// Runtime reports goroutine, heap and GC statistics.
+func (h *AdminHandler) Runtime(c *gin.Context) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	c.JSON(http.StatusOK, RuntimeStats{
+		GoVersion:     runtime.Version(),
+		Goroutines:    runtime.NumGoroutine(),
+		GOMAXPROCS:    runtime.GOMAXPROCS(0),
+		HeapAlloc:     mem.HeapAlloc,
+		HeapInuse:     mem.HeapInuse,
+		HeapObjects:   mem.HeapObjects,
+		Sys:           mem.Sys,
+		PauseTotalNs:  mem.PauseTotalNs,
+		LastGCUnixNs:  mem.LastGC,
+		NumGC:         mem.NumGC,
+		NextGCTrigger: mem.NextGC,
+	})
+}
+
+// PurgeCache evicts one short code on every domain, or every cached entry
+// when no code is given.
+func (h *AdminHandler) PurgeCache(c *gin.Context) {
+	if h.cache == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "no cache configured"})
+		return
+	}
+
+	shortCode := c.Param("short_code")
+
+	var err error
+	if shortCode == "" {
+		err = h.cache.PurgeAll(c.Request.Context())
+	} else {
+		err = h.cache.Purge(c.Request.Context(), shortCode)
+	}
+
+	if err != nil {
+		h.logger.Error("Failed to purge cache", zap.String("short_code", shortCode), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to purge cache"})
+
+		return
+	}
+
+	h.logger.Info("Cache purged", zap.String("short_code", shortCode))
+	c.Status(http.StatusNoContent)
+}
+
+// Counter reports the Redis counter and its Cassandra checkpoint.
+func (h *AdminHandler) Counter(c *gin.Context) {
+	status, err := h.counter.CounterStatus(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to inspect counter", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to inspect counter"})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// WorkspaceQuotaRequest sets the limits of a workspace; 0 is unlimited.
+type WorkspaceQuotaRequest struct {
+	LinksPerMonth int64 `json:"links_per_month"`
+	Domains       int   `json:"domains"`
+}
+
+// SetWorkspaceQuota replaces the quota of a workspace.
+func (h *AdminHandler) SetWorkspaceQuota(c *gin.Context) {
+	var req WorkspaceQuotaRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.LinksPerMonth < 0 || req.Domains < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "quota must be non-negative links_per_month and domains"})
+		return
+	}
+
+	id := c.Param("id")
+
+	workspace, err := h.workspaces.SetWorkspaceQuota(c.Request.Context(), id, entities.WorkspaceQuota{
+		LinksPerMonth: req.LinksPerMonth,
+		Domains:       req.Domains,
+	})
+	if errors.Is(err, usecases.ErrWorkspaceNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "workspace not found"})
+		return
+	}
+
+	if err != nil {
+		h.logger.Error("Failed to set workspace quota", zap.String("workspace_id", id), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to set workspace quota"})
+
+		return
+	}
+
+	h.logger.Info("Workspace quota changed",
+		zap.String("workspace_id", id),
+		zap.Int64("links_per_month", req.LinksPerMonth),
+		zap.Int("domains", req.Domains),
+	)
+	c.JSON(http.StatusOK, workspaceResponse(workspace))
+}
+
+// LinkStatusRequest moderates a link. Reason is shown to visitors of warned
+// and blocked links.
+type LinkStatusRequest struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+// SetLinkStatus warns about, blocks, disables or reactivates a link.
+func (h *AdminHandler) SetLinkStatus(c *gin.Context) {
+	var req LinkStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "request body is not valid JSON"})
+		return
+	}
+
+	domain, shortCode := c.Param("domain"), c.Param("short_code")
+
+	err := h.links.SetLinkStatus(c.Request.Context(), domain, shortCode, entities.LinkStatus(req.Status), req.Reason)
+	if errors.Is(err, usecases.ErrURLNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "link not found"})
+		return
+	}
+
+	if domainErr := entities.AsError(err); domainErr != nil && domainErr.Code == entities.CodeValidation {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domainErr.Message})
+		return
+	}
+
+	if err != nil {
+		h.logger.Error("Failed to set link status", zap.String("domain", domain), zap.String("short_code", shortCode), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to set link status"})
+
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// InterstitialChoices reports how many times the warning of a link was shown
+// and continued past.
+func (h *AdminHandler) InterstitialChoices(c *gin.Context) {
+	domain, shortCode := c.Param("domain"), c.Param("short_code")
+
+	choices, err := h.links.InterstitialChoices(c.Request.Context(), domain, shortCode)
+	if err != nil {
+		h.logger.Error("Failed to get interstitial choices", zap.String("domain", domain), zap.String("short_code", shortCode), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get interstitial choices"})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		string(usecases.InterstitialShown):     choices[usecases.InterstitialShown],
+		string(usecases.InterstitialContinued): choices[usecases.InterstitialContinued],
+	})
+}
+
+// Version reports the build of the running binary.
+func (h *AdminHandler) Version(c *gin.Context) {
+	c.JSON(http.StatusOK, buildinfo.Get())
+}
@@ -0,0 +1,476 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"lnk/domain/entities"
+	"lnk/domain/entities/usecases"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	jsonContentType   = "application/json"
+	ndjsonContentType = "application/x-ndjson"
+	csvContentType    = "text/csv"
+	// maxBulkItemBytes bounds the average encoded size of one item, so the
+	// body limit scales with the item limit.
+	maxBulkItemBytes = 4 << 10
+
+	defaultBulkMaxItems      = 1000
+	defaultBulkMaxAsyncItems = 50000
+)
+
+var (
+	errBulkEmpty          = entities.ErrValidation.WithMessage("request must contain at least one item")
+	errBulkContentType    = entities.ErrUnsupportedMedia.WithMessage("Content-Type must be application/json, application/x-ndjson or text/csv")
+	errBulkJobsDisabled   = entities.ErrUnavailable.WithMessage("asynchronous bulk jobs are not configured")
+	errBulkInvalidAsync   = entities.ErrValidation.WithMessage("async must be true or false")
+	errBulkBodyNotJSONArr = entities.ErrValidation.WithMessage("request body must be a JSON array")
+)
+
+type BulkItemRequest struct {
+	URL string `json:"url" example:"https://example.com"`
+}
+
+type BulkItemError struct {
+	Code   string `json:"code" example:"validation_failed"`
+	Detail string `json:"detail" example:"URL must be an absolute http or https URL"`
+}
+
+// BulkItemResponse is the outcome of one input item. Either ShortURL and
+// ShortCode, or Error, are set.
+type BulkItemResponse struct {
+	Error       *BulkItemError `json:"error,omitempty"`
+	ShortURL    string         `json:"short_url,omitempty" example:"https://go.example.com/abc123"`
+	ShortCode   string         `json:"short_code,omitempty" example:"abc123"`
+	OriginalURL string         `json:"original_url" example:"https://example.com"`
+	Index       int            `json:"index" example:"0"`
+}
+
+type BulkCreateResponse struct {
+	Results []BulkItemResponse `json:"results"`
+	Created int                `json:"created" example:"1"`
+	Failed  int                `json:"failed" example:"0"`
+}
+
+type BulkJobResponse struct {
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+	ID        string             `json:"id" example:"6f1c2b0e-2f0a-4b8e-9d0c-2f7f1f0f7f3a"`
+	Domain    string             `json:"domain" example:"go.example.com"`
+	Status    string             `json:"status" example:"running"`
+	Results   []BulkItemResponse `json:"results,omitempty"`
+	Total     int                `json:"total" example:"50000"`
+	Completed int                `json:"completed" example:"12000"`
+	Failed    int                `json:"failed" example:"3"`
+}
+
+// BulkLimits caps the number of items of one bulk request.
+type BulkLimits struct {
+	MaxItems      int
+	MaxAsyncItems int
+}
+
+type BulkHandler struct {
+	logger        *zap.Logger
+	useCase       *usecases.UseCase
+	jobs          *usecases.BulkJobRunner
+	maxItems      int
+	maxAsyncItems int
+}
+
+type NewBulkHandlerParams struct {
+	Logger  *zap.Logger
+	UseCase *usecases.UseCase
+	// Jobs runs asynchronous bulk creations. Without it only synchronous
+	// requests are accepted.
+	Jobs   *usecases.BulkJobRunner
+	Limits BulkLimits
+}
+
+func NewBulkHandler(params NewBulkHandlerParams) *BulkHandler {
+	limits := params.Limits
+	if limits.MaxItems <= 0 {
+		limits.MaxItems = defaultBulkMaxItems
+	}
+
+	if limits.MaxAsyncItems <= 0 {
+		limits.MaxAsyncItems = defaultBulkMaxAsyncItems
+	}
+
+	return &BulkHandler{
+		logger:        params.Logger,
+		useCase:       params.UseCase,
+		jobs:          params.Jobs,
+		maxItems:      limits.MaxItems,
+		maxAsyncItems: limits.MaxAsyncItems,
+	}
+}
+
+// CreateLinks creates a short URL for every item of the request.
+//
+// @Summary      Create short URLs in bulk
+// @Description  Create short URLs from a JSON array or NDJSON stream of {"url": ...} objects, or from CSV with a "url" column (or the URLs in the first column). Every item gets its own result. With async=true the links are created in the background and the response points at a job to poll.
+// @Tags         links
+// @Accept       json
+// @Accept       application/x-ndjson
+// @Accept       text/csv
+// @Produce      json
+// @Param        request          body      []BulkItemRequest  true   "URLs to shorten"
+// @Param        async            query     bool               false  "Create the links in a background job"
+// @Param        domain           query     string             false  "Short domain of the links; the default domain when empty"
+// @Param        Idempotency-Key  header    string             false  "Replays the first response for retries with the same key"
+// @Success      200              {object}  BulkCreateResponse
+// @Success      202              {object}  BulkJobResponse
+// @Failure      400              {object}  Problem
+// @Failure      403              {object}  Problem
+// @Failure      404              {object}  Problem
+// @Failure      413              {object}  Problem
+// @Failure      415              {object}  Problem
+// @Failure      503              {object}  Problem
+// @Router       /api/v1/links/bulk [post]
+func (h *BulkHandler) CreateLinks(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	async, err := parseAsync(c.Query("async"))
+	if err != nil {
+		renderError(c, h.logger, err)
+		return
+	}
+
+	if async && h.jobs == nil {
+		renderError(c, h.logger, errBulkJobsDisabled)
+		return
+	}
+
+	domain, err := h.useCase.ValidateDomain(c.Query("domain"))
+	if err != nil {
+		renderError(c, h.logger, err)
+		return
+	}
+
+	// Checked before reading the body, so forbidden requests fail as a whole
+	// rather than item by item.
+	if err := h.useCase.AuthorizeLinks(ctx, domain); err != nil {
+		renderError(c, h.logger, err)
+		return
+	}
+
+	limit := h.maxItems
+	if async {
+		limit = h.maxAsyncItems
+	}
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, h.bodyLimit(async))
+
+	longURLs, err := readBulkItems(c.ContentType(), body, limit)
+	if err != nil {
+		renderError(c, h.logger, err)
+		return
+	}
+
+	if !async {
+		results := h.useCase.CreateShortURLs(ctx, domain, longURLs, nil)
+
+		response := BulkCreateResponse{Results: h.bulkItemResponses(domain, results)}
+		for _, result := range results {
+			if result.Error != nil {
+				response.Failed++
+			} else {
+				response.Created++
+			}
+		}
+
+		c.JSON(http.StatusOK, response)
+
+		return
+	}
+
+	job, err := h.jobs.Start(ctx, domain, longURLs)
+	if err != nil {
+		renderError(c, h.logger, err)
+		return
+	}
+
+	c.Header("Location", "/api/v1/links/bulk/jobs/"+job.ID)
+	c.JSON(http.StatusAccepted, h.bulkJobResponse(job))
+}
+
+// GetJob reports the progress of an asynchronous bulk creation.
+//
+// @Summary      Get a bulk job
+// @Description  Get the progress of a bulk job; results are included once it has finished
+// @Tags         links
+// @Produce      json
+// @Param        id   path      string  true  "Job ID"
+// @Success      200  {object}  BulkJobResponse
+// @Failure      404  {object}  Problem
+// @Failure      503  {object}  Problem
+// @Router       /api/v1/links/bulk/jobs/{id} [get]
+func (h *BulkHandler) GetJob(c *gin.Context) {
+	if h.jobs == nil {
+		renderError(c, h.logger, usecases.ErrBulkJobNotFound)
+		return
+	}
+
+	job, err := h.jobs.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		renderError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, h.bulkJobResponse(job))
+}
+
+// LimitBody is route middleware capping the request body by the item limit of
+// the requested mode. It runs ahead of the idempotency middleware, which
+// buffers the body before CreateLinks sees it.
+func (h *BulkHandler) LimitBody(c *gin.Context) {
+	async, err := parseAsync(c.Query("async"))
+	if err != nil {
+		renderError(c, h.logger, err)
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.bodyLimit(async))
+	c.Next()
+}
+
+// MaxBodyBytes is the largest request body accepted in any mode.
+func (h *BulkHandler) MaxBodyBytes() int64 {
+	return max(h.bodyLimit(false), h.bodyLimit(true))
+}
+
+func (h *BulkHandler) bodyLimit(async bool) int64 {
+	if async {
+		return int64(h.maxAsyncItems) * maxBulkItemBytes
+	}
+
+	return int64(h.maxItems) * maxBulkItemBytes
+}
+
+func parseAsync(value string) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+
+	async, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, errBulkInvalidAsync.Wrap(err)
+	}
+
+	return async, nil
+}
+
+// readBulkItems streams the URLs out of body, failing as soon as it holds
+// more than limit items.
+func readBulkItems(contentType string, body io.Reader, limit int) ([]string, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if contentType == "" {
+		mediaType, err = jsonContentType, nil
+	}
+
+	if err != nil {
+		return nil, errBulkContentType.Wrap(err)
+	}
+
+	var longURLs []string
+
+	add := func(longURL string) error {
+		if len(longURLs) == limit {
+			return entities.ErrTooLarge.WithMessage(fmt.Sprintf("request must contain at most %d items", limit))
+		}
+
+		longURLs = append(longURLs, longURL)
+
+		return nil
+	}
+
+	switch mediaType {
+	case jsonContentType:
+		err = readJSONItems(body, add)
+	case ndjsonContentType:
+		err = readNDJSONItems(body, add)
+	case csvContentType:
+		err = readCSVItems(body, add)
+	default:
+		return nil, errBulkContentType
+	}
+
+	var maxBytesErr *http.MaxBytesError
+
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return nil, entities.ErrTooLarge.WithMessage(fmt.Sprintf("request body must be at most %d bytes", maxBytesErr.Limit)).Wrap(err)
+	case err != nil:
+		return nil, err
+	case len(longURLs) == 0:
+		return nil, errBulkEmpty
+	}
+
+	return longURLs, nil
+}
+
+func readJSONItems(body io.Reader, add func(string) error) error {
+	decoder := json.NewDecoder(body)
+
+	token, err := decoder.Token()
+	if err != nil {
+		return errBulkBodyNotJSONArr.Wrap(err)
+	}
+
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return errBulkBodyNotJSONArr
+	}
+
+	for index := 0; decoder.More(); index++ {
+		var item BulkItemRequest
+		if err := decoder.Decode(&item); err != nil {
+			return invalidItem(index, err)
+		}
+
+		if err := add(item.URL); err != nil {
+			return err
+		}
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return errBulkBodyNotJSONArr.Wrap(err)
+	}
+
+	return nil
+}
+
+func readNDJSONItems(body io.Reader, add func(string) error) error {
+	decoder := json.NewDecoder(body)
+
+	for index := 0; ; index++ {
+		var item BulkItemRequest
+
+		err := decoder.Decode(&item)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return invalidItem(index, err)
+		}
+
+		if err := add(item.URL); err != nil {
+			return err
+		}
+	}
+}
+
+// readCSVItems takes URLs from the "url" column when the first row is a
+// header naming one, and from the first column otherwise.
+func readCSVItems(body io.Reader, add func(string) error) error {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	column := 0
+	headerChecked := false
+
+	for index := 0; ; {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return invalidItem(index, err)
+		}
+
+		if !headerChecked {
+			headerChecked = true
+
+			if header := headerColumn(record); header >= 0 {
+				column = header
+				continue
+			}
+		}
+
+		var longURL string
+		if column < len(record) {
+			longURL = strings.TrimSpace(record[column])
+		}
+
+		if err := add(longURL); err != nil {
+			return err
+		}
+
+		index++
+	}
+}
+
+func headerColumn(record []string) int {
+	for i, field := range record {
+		if strings.EqualFold(strings.TrimSpace(field), "url") {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func invalidItem(index int, err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return err
+	}
+
+	return entities.ErrValidation.WithMessage(fmt.Sprintf("item %d is malformed", index)).Wrap(err)
+}
+
+func (h *BulkHandler) bulkItemResponses(domain string, results []entities.BulkItemResult) []BulkItemResponse {
+	responses := make([]BulkItemResponse, len(results))
+
+	for i, result := range results {
+		responses[i] = BulkItemResponse{
+			Index:       result.Index,
+			OriginalURL: result.LongURL,
+		}
+
+		if result.ShortCode != "" {
+			responses[i].ShortURL = h.useCase.ShortURL(domain, result.ShortCode)
+			responses[i].ShortCode = result.ShortCode
+		}
+
+		if result.Error != nil {
+			responses[i].Error = &BulkItemError{Code: string(result.Error.Code), Detail: result.Error.Message}
+		}
+	}
+
+	return responses
+}
+
+func (h *BulkHandler) bulkJobResponse(job *entities.BulkJob) BulkJobResponse {
+	response := BulkJobResponse{
+		ID:        job.ID,
+		Domain:    job.Domain,
+		Status:    string(job.Status),
+		Total:     job.Total,
+		Completed: job.Completed,
+		Failed:    job.Failed,
+		CreatedAt: job.CreatedAt,
+		UpdatedAt: job.UpdatedAt,
+	}
+
+	if len(job.Results) > 0 {
+		response.Results = h.bulkItemResponses(job.Domain, job.Results)
+	}
+
+	return response
+}
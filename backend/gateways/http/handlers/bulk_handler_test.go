@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"lnk/domain/entities"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_readBulkItems(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        []string
+	}{
+		{
+			name:        "JSON array",
+			contentType: "application/json; charset=utf-8",
+			body:        `[{"url":"https://a.example"},{"url":"https://b.example"}]`,
+			want:        []string{"https://a.example", "https://b.example"},
+		},
+		{
+			name:        "NDJSON",
+			contentType: ndjsonContentType,
+			body:        "{\"url\":\"https://a.example\"}\n{\"url\":\"\"}\n",
+			want:        []string{"https://a.example", ""},
+		},
+		{
+			name:        "CSV with header",
+			contentType: csvContentType,
+			body:        "campaign,url\nspring, https://a.example\nsummer\n",
+			want:        []string{"https://a.example", ""},
+		},
+		{
+			name:        "CSV without header",
+			contentType: csvContentType,
+			body:        "https://a.example,ignored\nhttps://b.example\n",
+			want:        []string{"https://a.example", "https://b.example"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := readBulkItems(test.contentType, strings.NewReader(test.body), 10)
+			require.NoError(t, err)
+			require.Equal(t, test.want, got)
+		})
+	}
+}
+
+func Test_readBulkItems_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		want        *entities.Error
+		name        string
+		contentType string
+		body        string
+	}{
+		{name: "too many items", contentType: csvContentType, body: "a\nb\nc\n", want: entities.ErrTooLarge},
+		{name: "empty", contentType: jsonContentType, body: `[]`, want: errBulkEmpty},
+		{name: "not an array", contentType: jsonContentType, body: `{"url":"x"}`, want: errBulkBodyNotJSONArr},
+		{name: "malformed line", contentType: ndjsonContentType, body: "{\"url\":\"x\"}\n{", want: entities.ErrValidation},
+		{name: "unsupported type", contentType: "text/plain", body: "x", want: errBulkContentType},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := readBulkItems(test.contentType, strings.NewReader(test.body), 2)
+			require.True(t, errors.Is(err, test.want), "got %v", err)
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"lnk/domain/entities"
+	"lnk/extensions/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+const (
+	problemContentType = "application/problem+json"
+	problemTypePrefix  = "urn:lnk:problem:"
+)
+
+// Problem is an RFC 7807 problem details body. Code is the machine-readable
+// error code clients should switch on; Detail is for humans.
+type Problem struct {
+	Type      string                `json:"type" example:"urn:lnk:problem:not_found"`
+	Title     string                `json:"title" example:"Not Found"`
+	Detail    string                `json:"detail" example:"URL not found"`
+	Instance  string                `json:"instance" example:"/abc123"`
+	Code      string                `json:"code" example:"not_found"`
+	RequestID string                `json:"request_id,omitempty" example:"5f0c6f1e-7f2a-4c1e-9a57-0d5c3b1f2e44"`
+	Errors    []entities.FieldError `json:"errors,omitempty"`
+	Status    int                   `json:"status" example:"404"`
+}
+
+var statusByCode = map[entities.ErrorCode]int{
+	entities.CodeNotFound:         http.StatusNotFound,
+	entities.CodeGone:             http.StatusGone,
+	entities.CodeConflict:         http.StatusConflict,
+	entities.CodeValidation:       http.StatusBadRequest,
+	entities.CodeUnprocessable:    http.StatusUnprocessableEntity,
+	entities.CodeTooLarge:         http.StatusRequestEntityTooLarge,
+	entities.CodeUnsupportedMedia: http.StatusUnsupportedMediaType,
+	entities.CodeForbidden:        http.StatusForbidden,
+	entities.CodeRateLimited:      http.StatusTooManyRequests,
+	entities.CodeQuotaExceeded:    http.StatusTooManyRequests,
+	entities.CodeUnavailable:      http.StatusServiceUnavailable,
+}
+
+// renderError writes err as a problem+json response. Domain errors map to
+// their status and client-safe message; anything else becomes an opaque 500.
+// Internal details are only logged, never returned.
+func renderError(c *gin.Context, log *zap.Logger, err error) {
+	ctx := c.Request.Context()
+
+	domainErr := entities.AsError(err)
+	if domainErr == nil {
+		domainErr = entities.NewError(entities.CodeInternal, "internal server error")
+	}
+
+	status, ok := statusByCode[domainErr.Code]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+
+	if status >= http.StatusInternalServerError {
+		_ = c.Error(err)
+
+		logger.FromContext(ctx, log).Error("Request failed",
+			zap.String("code", string(domainErr.Code)),
+			zap.Error(err),
+		)
+	}
+
+	c.Header("Content-Type", problemContentType)
+	c.AbortWithStatusJSON(status, Problem{
+		Type:      problemTypePrefix + string(domainErr.Code),
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    domainErr.Message,
+		Instance:  c.Request.URL.Path,
+		Code:      string(domainErr.Code),
+		RequestID: logger.RequestID(ctx),
+		Errors:    domainErr.Fields,
+	})
+}
+
+// bindingError turns a request binding failure into a validation error that
+// names the offending JSON fields.
+func bindingError(err error) *entities.Error {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return entities.ErrValidation.WithMessage("request body is not valid JSON").Wrap(err)
+	}
+
+	fields := make([]entities.FieldError, 0, len(validationErrs))
+	for _, fieldErr := range validationErrs {
+		fields = append(fields, entities.FieldError{
+			Field:   fieldErr.Field(),
+			Message: "failed on the '" + fieldErr.Tag() + "' rule",
+		})
+	}
+
+	return entities.ErrValidation.WithFields(fields...).Wrap(err)
+}
+
+// UseJSONFieldNames makes validation errors report JSON field names instead
+// of Go struct field names.
+func UseJSONFieldNames() {
+	validate, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			return field.Name
+		}
+
+		return name
+	})
+}
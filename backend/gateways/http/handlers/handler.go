@@ -1,26 +1,97 @@
 package handlers
 
 import (
+	"html/template"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"go.uber.org/zap"
+	"lnk/domain/entities"
 	"lnk/domain/entities/usecases"
+	"lnk/extensions/health"
+	"lnk/extensions/qrcode"
 )
 
 type Handlers struct {
-	logger      *zap.Logger
-	URLsHandler *URLsHandler
-	useCase     *usecases.UseCase
+	logger             *zap.Logger
+	URLsHandler        *URLsHandler
+	LinksHandler       *LinksHandler
+	PreviewHandler     *PreviewHandler
+	HealthHandler      *HealthHandler
+	IdempotencyHandler *IdempotencyHandler
+	BulkHandler        *BulkHandler
+	bulkIdempotency    *IdempotencyHandler
+	QRHandler          *QRHandler
+	WorkspacesHandler  *WorkspacesHandler
+	useCase            *usecases.UseCase
 }
 
-func NewHandlers(logger *zap.Logger, useCase *usecases.UseCase) *Handlers {
+type NewHandlersParams struct {
+	// Idempotency stores responses for Idempotency-Key retries. Without it the
+	// header is ignored.
+	Idempotency IdempotencyStore
+	Logger      *zap.Logger
+	UseCase     *usecases.UseCase
+	Checker     *health.Checker
+	// BulkJobs runs asynchronous bulk creations. Without it only synchronous
+	// bulk requests are accepted.
+	BulkJobs *usecases.BulkJobRunner
+	// QRRenderer renders QR codes for short links.
+	QRRenderer *qrcode.Renderer
+	// Interstitial renders the warning of flagged links; see
+	// InterstitialPage. It defaults to the built-in warning page.
+	Interstitial *template.Template
+	BulkLimits   BulkLimits
+	// IdempotencyLockTTL is the lock TTL of Idempotency, which running
+	// requests renew.
+	IdempotencyLockTTL time.Duration
+}
+
+func NewHandlers(params NewHandlersParams) *Handlers {
+	previews := NewPreviewHandler(NewPreviewHandlerParams{
+		Logger:       params.Logger,
+		UseCase:      params.UseCase,
+		Interstitial: params.Interstitial,
+	})
+	idempotency := NewIdempotencyHandler(NewIdempotencyHandlerParams{
+		Logger:  params.Logger,
+		Store:   params.Idempotency,
+		LockTTL: params.IdempotencyLockTTL,
+	})
+	bulk := NewBulkHandler(NewBulkHandlerParams{
+		Logger:  params.Logger,
+		UseCase: params.UseCase,
+		Jobs:    params.BulkJobs,
+		Limits:  params.BulkLimits,
+	})
+
 	return &Handlers{
-		logger:      logger,
-		URLsHandler: NewURLsHandler(logger, useCase),
-		useCase:     useCase,
+		logger:             params.Logger,
+		URLsHandler:        NewURLsHandler(params.Logger, params.UseCase, previews),
+		LinksHandler:       NewLinksHandler(params.Logger, params.UseCase),
+		PreviewHandler:     previews,
+		HealthHandler:      NewHealthHandler(params.Checker),
+		IdempotencyHandler: idempotency,
+		BulkHandler:        bulk,
+		// Bulk bodies may exceed the default buffering limit; LimitBody bounds
+		// them by mode ahead of this middleware.
+		bulkIdempotency: NewIdempotencyHandler(NewIdempotencyHandlerParams{
+			Logger:       params.Logger,
+			Store:        params.Idempotency,
+			LockTTL:      params.IdempotencyLockTTL,
+			MaxBodyBytes: bulk.MaxBodyBytes(),
+		}),
+		QRHandler: NewQRHandler(NewQRHandlerParams{
+			Logger:   params.Logger,
+			UseCase:  params.UseCase,
+			Renderer: params.QRRenderer,
+		}),
+		WorkspacesHandler: NewWorkspacesHandler(params.Logger, params.UseCase),
+		useCase:           params.UseCase,
 	}
 }
 
@@ -30,9 +101,63 @@ func (h *Handlers) RegisterRoutes(router *gin.Engine, env string) {
 	}
 
 	router.GET("/health", h.healthCheck)
+	router.GET("/livez", h.HealthHandler.Livez)
+	router.GET("/readyz", h.HealthHandler.Readyz)
+
+	router.POST("/shorten", h.IdempotencyHandler.Handle, h.URLsHandler.CreateURL)
+	router.GET("/:short_url", h.getShortURL)
+	router.GET("/preview/:code", h.PreviewHandler.PreviewLink)
+	router.POST("/preview/settings", h.PreviewHandler.SetPreference)
+
+	api := router.Group("/api/v1")
+	api.GET("/links", h.LinksHandler.ListLinks)
+	api.POST("/links/bulk", h.BulkHandler.LimitBody, h.bulkIdempotency.Handle, h.BulkHandler.CreateLinks)
+	api.GET("/links/bulk/jobs/:id", h.BulkHandler.GetJob)
+	api.GET("/links/:code", h.LinksHandler.GetLink)
+	api.PATCH("/links/:code", h.LinksHandler.UpdateLink)
+	api.GET("/links/:code/qr", h.QRHandler.GetQRCode)
+
+	api.POST("/workspaces", h.WorkspacesHandler.CreateWorkspace)
+	api.GET("/workspaces/:id", h.WorkspacesHandler.GetWorkspace)
+	api.GET("/workspaces/:id/members", h.WorkspacesHandler.ListMembers)
+	api.PUT("/workspaces/:id/members/:user_id", h.WorkspacesHandler.SetMember)
+	api.DELETE("/workspaces/:id/members/:user_id", h.WorkspacesHandler.RemoveMember)
+	api.POST("/workspaces/:id/domains", h.WorkspacesHandler.AddDomain)
+	api.DELETE("/workspaces/:id/domains/:domain", h.WorkspacesHandler.RemoveDomain)
+
+	router.NoRoute(func(c *gin.Context) {
+		renderError(c, h.logger, entities.ErrNotFound)
+	})
+}
+
+// getShortURL serves the short link itself, its QR code when a format is
+// requested, its preview page for a trailing "+" and for browsers that opted
+// in to previews, or the destination of a flagged link whose warning the
+// visitor confirmed.
+func (h *Handlers) getShortURL(c *gin.Context) {
+	domain := h.useCase.ResolveDomain(c.Request.Host)
+
+	if c.Query("format") != "" {
+		h.QRHandler.render(c, domain, c.Param("short_url"))
+		return
+	}
+
+	if shortCode, ok := strings.CutSuffix(c.Param("short_url"), "+"); ok {
+		h.PreviewHandler.render(c, domain, shortCode)
+		return
+	}
+
+	if token := c.Query("continue"); token != "" {
+		h.PreviewHandler.continueLink(c, domain, c.Param("short_url"), token)
+		return
+	}
+
+	if h.PreviewHandler.alwaysPreview(c) {
+		h.PreviewHandler.render(c, domain, c.Param("short_url"))
+		return
+	}
 
-	router.POST("/shorten", h.URLsHandler.CreateURL)
-	router.GET("/:short_url", h.URLsHandler.GetURL)
+	h.URLsHandler.GetURL(c)
 }
 
 // healthCheck godoc
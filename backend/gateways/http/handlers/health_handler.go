@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http"
+
+	"lnk/extensions/health"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	checker *health.Checker
+}
+
+func NewHealthHandler(checker *health.Checker) *HealthHandler {
+	return &HealthHandler{checker: checker}
+}
+
+// Livez reports whether the process is alive. It never checks dependencies,
+// so a dependency outage does not get the replica restarted.
+//
+// @Summary      Liveness probe
+// @Description  Report whether the process is alive
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  map[string]string
+// @Router       /livez [get]
+func (h *HealthHandler) Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": health.StatusOK})
+}
+
+// Readyz reports whether the replica can serve traffic.
+//
+// @Summary      Readiness probe
+// @Description  Run the dependency checks and report whether the replica can serve traffic
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  health.Report
+// @Failure      503  {object}  health.Report
+// @Router       /readyz [get]
+func (h *HealthHandler) Readyz(c *gin.Context) {
+	report := h.checker.Check(c.Request.Context())
+	if !report.Ready() {
+		c.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
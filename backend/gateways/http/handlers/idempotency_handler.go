@@ -0,0 +1,298 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"lnk/domain/entities"
+	"lnk/extensions/logger"
+	"lnk/extensions/redis"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+	idempotencyRetryAfter    = "1"
+
+	defaultIdempotentBodyBytes     = 1 << 20
+	defaultIdempotentResponseBytes = 1 << 20
+	defaultIdempotencyLockTTL      = 30 * time.Second
+)
+
+var (
+	errIdempotencyKeyTooLong = entities.ErrValidation.WithMessage("Idempotency-Key must be at most 255 characters")
+	errIdempotencyKeyReused  = entities.ErrUnprocessable.WithMessage("Idempotency-Key was already used for a different request")
+	errIdempotencyInProgress = entities.ErrConflict.WithMessage("a request with this Idempotency-Key is still in progress")
+	errIdempotencyStore      = entities.ErrUnavailable.WithMessage("idempotency store is temporarily unavailable")
+)
+
+// IdempotencyStore remembers responses per caller and Idempotency-Key.
+type IdempotencyStore interface {
+	Begin(ctx context.Context, caller, key, fingerprint string) (string, *redis.IdempotentResponse, error)
+	Complete(ctx context.Context, caller, key, token string, response *redis.IdempotentResponse) error
+	Extend(ctx context.Context, caller, key, token string) error
+	Release(ctx context.Context, caller, key, token string) error
+}
+
+// IdempotencyHandler makes create endpoints safe to retry. The first response
+// for a caller and Idempotency-Key is stored and replayed for later requests
+// with the same query, content type and body; reusing the key for a different
+// request is rejected. Server errors, 429 responses and responses larger than
+// MaxResponseBytes are not stored, so the request can be retried with the same
+// key.
+type IdempotencyHandler struct {
+	logger           *zap.Logger
+	store            IdempotencyStore
+	maxBodyBytes     int64
+	lockTTL          time.Duration
+	maxResponseBytes int
+}
+
+type NewIdempotencyHandlerParams struct {
+	Logger *zap.Logger
+	Store  IdempotencyStore
+	// MaxBodyBytes bounds the request bodies buffered for fingerprinting;
+	// larger requests with an Idempotency-Key are rejected. It defaults to
+	// 1 MiB.
+	MaxBodyBytes int64
+	// LockTTL is how long the store holds the lock of a running request. The
+	// lock is renewed every third of it until the request finishes. It
+	// defaults to 30s.
+	LockTTL time.Duration
+	// MaxResponseBytes bounds the responses kept for replay. It defaults to
+	// 1 MiB.
+	MaxResponseBytes int
+}
+
+func NewIdempotencyHandler(params NewIdempotencyHandlerParams) *IdempotencyHandler {
+	if params.MaxBodyBytes <= 0 {
+		params.MaxBodyBytes = defaultIdempotentBodyBytes
+	}
+
+	if params.MaxResponseBytes <= 0 {
+		params.MaxResponseBytes = defaultIdempotentResponseBytes
+	}
+
+	if params.LockTTL <= 0 {
+		params.LockTTL = defaultIdempotencyLockTTL
+	}
+
+	return &IdempotencyHandler{
+		logger:           params.Logger,
+		store:            params.Store,
+		maxBodyBytes:     params.MaxBodyBytes,
+		lockTTL:          params.LockTTL,
+		maxResponseBytes: params.MaxResponseBytes,
+	}
+}
+
+// Handle is route middleware for create endpoints. Requests without an
+// Idempotency-Key, or without a configured store, pass through unchanged.
+func (h *IdempotencyHandler) Handle(c *gin.Context) {
+	key := c.GetHeader(IdempotencyKeyHeader)
+	if key == "" || h.store == nil {
+		c.Next()
+		return
+	}
+
+	if len(key) > maxIdempotencyKeyLength {
+		renderError(c, h.logger, errIdempotencyKeyTooLong)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, h.maxBodyBytes))
+
+	var maxBytesErr *http.MaxBytesError
+
+	switch {
+	case errors.As(err, &maxBytesErr):
+		renderError(c, h.logger, entities.ErrTooLarge.WithMessage(fmt.Sprintf("request body must be at most %d bytes", maxBytesErr.Limit)).Wrap(err))
+		return
+	case err != nil:
+		renderError(c, h.logger, entities.ErrValidation.WithMessage("request body could not be read").Wrap(err))
+		return
+	}
+
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	ctx := c.Request.Context()
+	caller := idempotencyCaller(c)
+	fingerprint := requestFingerprint(c, body)
+
+	token, replay, err := h.store.Begin(ctx, caller, key, fingerprint)
+
+	switch {
+	case errors.Is(err, redis.ErrIdempotencyMismatch):
+		renderError(c, h.logger, errIdempotencyKeyReused)
+		return
+	case errors.Is(err, redis.ErrIdempotencyInProgress):
+		c.Header("Retry-After", idempotencyRetryAfter)
+		renderError(c, h.logger, errIdempotencyInProgress)
+
+		return
+	case err != nil:
+		renderError(c, h.logger, errIdempotencyStore.Wrap(err))
+		return
+	case replay != nil:
+		c.Header(IdempotentReplayedHeader, "true")
+		c.Data(replay.Status, replay.ContentType, replay.Body)
+		c.Abort()
+
+		return
+	}
+
+	recorder := &responseRecorder{ResponseWriter: c.Writer, limit: h.maxResponseBytes}
+	c.Writer = recorder
+
+	// The client may have gone away; the outcome must still be recorded.
+	ctx = context.WithoutCancel(ctx)
+
+	stopRenewing := h.renewLock(ctx, caller, key, token)
+
+	// A panicking handler never finishes; releasing the key in a deferred call
+	// lets retries through before LockTTL instead of answering 409 until then.
+	finished := false
+	defer func() {
+		if !finished {
+			stopRenewing()
+			h.release(ctx, caller, key, token)
+		}
+	}()
+
+	c.Next()
+
+	stopRenewing()
+
+	finished = true
+
+	// Quota and rate limits lift over time, so their answers are not kept.
+	if recorder.Status() >= http.StatusInternalServerError || recorder.Status() == http.StatusTooManyRequests {
+		h.release(ctx, caller, key, token)
+		return
+	}
+
+	if recorder.truncated {
+		logger.FromContext(ctx, h.logger).Warn("Idempotent response too large to store",
+			zap.Int("limit", h.maxResponseBytes))
+		h.release(ctx, caller, key, token)
+
+		return
+	}
+
+	err = h.store.Complete(ctx, caller, key, token, &redis.IdempotentResponse{
+		Status:      recorder.Status(),
+		ContentType: recorder.Header().Get("Content-Type"),
+		Body:        recorder.body.Bytes(),
+	})
+	if err != nil {
+		logger.FromContext(ctx, h.logger).Warn("Failed to store idempotent response", zap.Error(err))
+	}
+}
+
+// renewLock extends the lock on key every third of its TTL until the returned
+// function is called, so a request that outlives the TTL, such as a large
+// bulk creation, is not run a second time by a retry.
+func (h *IdempotencyHandler) renewLock(ctx context.Context, caller, key, token string) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(h.lockTTL / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := h.store.Extend(ctx, caller, key, token); err != nil {
+					logger.FromContext(ctx, h.logger).Warn("Failed to extend idempotency lock", zap.Error(err))
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+func (h *IdempotencyHandler) release(ctx context.Context, caller, key, token string) {
+	if err := h.store.Release(ctx, caller, key, token); err != nil {
+		logger.FromContext(ctx, h.logger).Warn("Failed to release idempotency key", zap.Error(err))
+	}
+}
+
+// idempotencyCaller scopes keys to the authenticated user or owner, falling
+// back to the client IP for anonymous callers.
+func idempotencyCaller(c *gin.Context) string {
+	identity := entities.IdentityFromContext(c.Request.Context())
+
+	switch {
+	case identity.UserID != "":
+		return "user:" + identity.UserID
+	case identity.OwnerID != "":
+		return "owner:" + identity.OwnerID
+	default:
+		return "ip:" + c.ClientIP()
+	}
+}
+
+// requestFingerprint identifies a request by its route, query, content type
+// and body, so reusing a key with options such as ?domain= or ?async= changed
+// is rejected rather than replayed. Query parameters are sorted, so their
+// order does not matter.
+func requestFingerprint(c *gin.Context, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(c.Request.Method + " " + c.FullPath() + "?" + c.Request.URL.Query().Encode() + "\n"))
+	hash.Write([]byte(c.ContentType() + "\n"))
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder copies the response body while it is written, up to limit
+// bytes. Larger bodies are dropped and marked truncated.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body      bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.record(data)
+
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *responseRecorder) WriteString(data string) (int, error) {
+	r.record([]byte(data))
+
+	return r.ResponseWriter.WriteString(data)
+}
+
+func (r *responseRecorder) record(data []byte) {
+	switch {
+	case r.truncated:
+	case r.body.Len()+len(data) > r.limit:
+		r.truncated = true
+		r.body = bytes.Buffer{}
+	default:
+		r.body.Write(data)
+	}
+}
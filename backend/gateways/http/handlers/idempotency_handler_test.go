@@ -0,0 +1,347 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"lnk/extensions/redis"
+	"lnk/gateways/http/handlers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type storedEntry struct {
+	response    *redis.IdempotentResponse
+	fingerprint string
+	token       string
+}
+
+// memoryIdempotencyStore mirrors the Redis store semantics in memory, without
+// expiry. It counts lock renewals.
+type memoryIdempotencyStore struct {
+	entries map[string]*storedEntry
+	mu      sync.Mutex
+	extends atomic.Int64
+}
+
+func newMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{entries: map[string]*storedEntry{}}
+}
+
+func (s *memoryIdempotencyStore) Begin(_ context.Context, caller, key, fingerprint string) (string, *redis.IdempotentResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[caller+key]
+	if !ok {
+		s.entries[caller+key] = &storedEntry{fingerprint: fingerprint, token: "token"}
+		return "token", nil, nil
+	}
+
+	if entry.fingerprint != fingerprint {
+		return "", nil, redis.ErrIdempotencyMismatch
+	}
+
+	if entry.response == nil {
+		return "", nil, redis.ErrIdempotencyInProgress
+	}
+
+	return "", entry.response, nil
+}
+
+func (s *memoryIdempotencyStore) Complete(_ context.Context, caller, key, token string, response *redis.IdempotentResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry := s.entries[caller+key]; entry != nil && entry.token == token {
+		entry.response = response
+	}
+
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Extend(context.Context, string, string, string) error {
+	s.extends.Add(1)
+
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Release(_ context.Context, caller, key, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry := s.entries[caller+key]; entry != nil && entry.token == token {
+		delete(s.entries, caller+key)
+	}
+
+	return nil
+}
+
+func Test_IdempotencyHandler(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+
+	var (
+		calls  int
+		status = http.StatusCreated
+	)
+
+	idempotency := handlers.NewIdempotencyHandler(handlers.NewIdempotencyHandlerParams{
+		Logger: zap.NewNop(),
+		Store:  newMemoryIdempotencyStore(),
+	})
+
+	router := gin.New()
+	router.POST("/shorten", idempotency.Handle, func(c *gin.Context) {
+		calls++
+		c.JSON(status, gin.H{"short_url": "abc", "call": calls})
+	})
+
+	post := func(key, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, "/shorten", strings.NewReader(body))
+		if key != "" {
+			request.Header.Set(handlers.IdempotencyKeyHeader, key)
+		}
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	first := post("key-1", `{"url":"https://example.com"}`)
+	require.Equal(t, http.StatusCreated, first.Code)
+
+	replay := post("key-1", `{"url":"https://example.com"}`)
+	require.Equal(t, http.StatusCreated, replay.Code)
+	require.Equal(t, first.Body.String(), replay.Body.String())
+	require.Equal(t, "true", replay.Header().Get(handlers.IdempotentReplayedHeader))
+	require.Equal(t, 1, calls)
+
+	reused := post("key-1", `{"url":"https://other.example.com"}`)
+	require.Equal(t, http.StatusUnprocessableEntity, reused.Code)
+	require.Contains(t, reused.Body.String(), `"code":"unprocessable"`)
+
+	status = http.StatusServiceUnavailable
+	require.Equal(t, http.StatusServiceUnavailable, post("key-2", `{}`).Code)
+
+	status = http.StatusCreated
+	require.Equal(t, http.StatusCreated, post("key-2", `{}`).Code, "server errors must not be replayed")
+	require.Equal(t, 3, calls)
+
+	status = http.StatusTooManyRequests
+	require.Equal(t, http.StatusTooManyRequests, post("key-3", `{}`).Code)
+
+	status = http.StatusCreated
+	require.Equal(t, http.StatusCreated, post("key-3", `{}`).Code, "rate and quota limits must not be replayed")
+	require.Equal(t, 5, calls)
+
+	post("", `{}`)
+	post("", `{}`)
+	require.Equal(t, 7, calls, "requests without a key are not deduplicated")
+}
+
+func Test_IdempotencyHandler_FingerprintsQueryAndContentType(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+
+	idempotency := handlers.NewIdempotencyHandler(handlers.NewIdempotencyHandlerParams{
+		Logger: zap.NewNop(),
+		Store:  newMemoryIdempotencyStore(),
+	})
+
+	router := gin.New()
+	router.POST("/bulk", idempotency.Handle, func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{"domain": c.Query("domain")})
+	})
+
+	post := func(target, contentType string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, target, strings.NewReader(`[{"url":"https://a.example"}]`))
+		request.Header.Set(handlers.IdempotencyKeyHeader, "key")
+		request.Header.Set("Content-Type", contentType)
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	require.Equal(t, http.StatusCreated, post("/bulk?domain=go.example&async=false", "application/json").Code)
+
+	replay := post("/bulk?async=false&domain=go.example", "application/json; charset=utf-8")
+	require.Equal(t, http.StatusCreated, replay.Code, "parameter order and charset do not change the request")
+	require.Equal(t, "true", replay.Header().Get(handlers.IdempotentReplayedHeader))
+
+	require.Equal(t, http.StatusUnprocessableEntity, post("/bulk?domain=lnk.example&async=false", "application/json").Code)
+	require.Equal(t, http.StatusUnprocessableEntity, post("/bulk?domain=go.example&async=true", "application/json").Code)
+	require.Equal(t, http.StatusUnprocessableEntity, post("/bulk?domain=go.example&async=false", "application/x-ndjson").Code)
+}
+
+func Test_IdempotencyHandler_RenewsLock(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+
+	store := newMemoryIdempotencyStore()
+	idempotency := handlers.NewIdempotencyHandler(handlers.NewIdempotencyHandlerParams{
+		Logger:  zap.NewNop(),
+		Store:   store,
+		LockTTL: 30 * time.Millisecond,
+	})
+
+	router := gin.New()
+	router.POST("/bulk", idempotency.Handle, func(c *gin.Context) {
+		time.Sleep(100 * time.Millisecond)
+		c.Status(http.StatusCreated)
+	})
+
+	request := httptest.NewRequest(http.MethodPost, "/bulk", strings.NewReader(`[]`))
+	request.Header.Set(handlers.IdempotencyKeyHeader, "key")
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusCreated, recorder.Code)
+
+	renewed := store.extends.Load()
+	require.GreaterOrEqual(t, renewed, int64(2), "a request outliving the lock TTL keeps its lock")
+
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, renewed, store.extends.Load(), "renewal stops with the request")
+}
+
+func Test_IdempotencyHandler_InProgress(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+
+	var (
+		router *gin.Engine
+		nested *httptest.ResponseRecorder
+	)
+
+	post := func() *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, "/shorten", strings.NewReader(`{}`))
+		request.Header.Set(handlers.IdempotencyKeyHeader, "key")
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	router = gin.New()
+	idempotency := handlers.NewIdempotencyHandler(handlers.NewIdempotencyHandlerParams{
+		Logger: zap.NewNop(),
+		Store:  newMemoryIdempotencyStore(),
+	})
+
+	router.POST("/shorten", idempotency.Handle,
+		func(c *gin.Context) {
+			// Retry while the first request still holds the key.
+			nested = post()
+			c.Status(http.StatusCreated)
+		},
+	)
+
+	require.Equal(t, http.StatusCreated, post().Code)
+	require.Equal(t, http.StatusConflict, nested.Code)
+	require.Equal(t, "1", nested.Header().Get("Retry-After"))
+}
+
+func Test_IdempotencyHandler_Limits(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+
+	response := "panic"
+	store := newMemoryIdempotencyStore()
+
+	router := gin.New()
+	router.Use(gin.CustomRecovery(func(c *gin.Context, _ any) {
+		c.AbortWithStatus(http.StatusInternalServerError)
+	}))
+	router.POST("/shorten", handlers.NewIdempotencyHandler(handlers.NewIdempotencyHandlerParams{
+		Logger:           zap.NewNop(),
+		Store:            store,
+		MaxBodyBytes:     16,
+		MaxResponseBytes: 8,
+	}).Handle, func(c *gin.Context) {
+		if response == "panic" {
+			panic("boom")
+		}
+
+		c.String(http.StatusCreated, response)
+	})
+
+	post := func(body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, "/shorten", strings.NewReader(body))
+		request.Header.Set(handlers.IdempotencyKeyHeader, "key")
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	tooLarge := post(`{"url":"https://example.com"}`)
+	require.Equal(t, http.StatusRequestEntityTooLarge, tooLarge.Code)
+	require.Empty(t, store.entries, "oversized requests must not take the key")
+
+	require.Equal(t, http.StatusInternalServerError, post(`{}`).Code)
+	require.Empty(t, store.entries, "a panicking handler must release the key")
+
+	response = "a response over the limit"
+	recorder := post(`{}`)
+	require.Equal(t, http.StatusCreated, recorder.Code)
+	require.Equal(t, response, recorder.Body.String(), "the client still gets the whole response")
+	require.Empty(t, store.entries, "oversized responses must not be stored")
+
+	response = "created"
+	require.Equal(t, http.StatusCreated, post(`{}`).Code)
+
+	response = "changed"
+	replay := post(`{}`)
+	require.Equal(t, "created", replay.Body.String())
+	require.Equal(t, "true", replay.Header().Get(handlers.IdempotentReplayedHeader))
+}
+
+func Test_BulkHandler_LimitBody(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+
+	store := newMemoryIdempotencyStore()
+	bulk := handlers.NewBulkHandler(handlers.NewBulkHandlerParams{
+		Logger: zap.NewNop(),
+		Limits: handlers.BulkLimits{MaxItems: 1, MaxAsyncItems: 100},
+	})
+
+	router := gin.New()
+	router.POST("/api/v1/links/bulk", bulk.LimitBody, handlers.NewIdempotencyHandler(handlers.NewIdempotencyHandlerParams{
+		Logger:       zap.NewNop(),
+		Store:        store,
+		MaxBodyBytes: bulk.MaxBodyBytes(),
+	}).Handle, func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/links/bulk", strings.NewReader(strings.Repeat(" ", 5<<10)))
+	request.Header.Set(handlers.IdempotencyKeyHeader, "key")
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+	require.Contains(t, recorder.Body.String(), "at most 4096 bytes")
+	require.Empty(t, store.entries)
+}
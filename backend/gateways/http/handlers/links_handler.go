@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"lnk/domain/entities"
+	"lnk/domain/entities/usecases"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type LinkResponse struct {
+	CreatedAt time.Time `json:"created_at"`
+	// UpdatedAt is omitted until the link is edited.
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	// Preview is only returned for a single link, once its destination has
+	// been fetched.
+	Preview     *LinkPreviewResponse `json:"preview,omitempty"`
+	ShortURL    string               `json:"short_url" example:"https://go.example.com/abc123"`
+	ShortCode   string               `json:"short_code" example:"abc123"`
+	Domain      string               `json:"domain" example:"go.example.com"`
+	OriginalURL string               `json:"original_url" example:"https://example.com"`
+	Title       string               `json:"title,omitempty" example:"Spring sale landing page"`
+	Notes       string               `json:"notes,omitempty" example:"Linked from the March newsletter"`
+	// Status is only returned for a single link. StatusReason tells visitors
+	// why a warned or blocked link was flagged.
+	Status       string   `json:"status,omitempty" example:"active" enums:"active,warned,blocked,disabled"`
+	StatusReason string   `json:"status_reason,omitempty" example:"Reported as phishing"`
+	Tags         []string `json:"tags,omitempty" example:"spring-sale,newsletter"`
+}
+
+// LinkPreviewResponse is what the destination page says about itself. Status
+// is fetched or failed; the other fields are empty when it failed.
+type LinkPreviewResponse struct {
+	FetchedAt   time.Time `json:"fetched_at"`
+	Status      string    `json:"status" example:"fetched"`
+	Title       string    `json:"title,omitempty" example:"Example Domain"`
+	Description string    `json:"description,omitempty" example:"This domain is for use in documentation examples."`
+	FaviconURL  string    `json:"favicon_url,omitempty" example:"https://example.com/favicon.ico"`
+	ImageURL    string    `json:"image_url,omitempty" example:"https://example.com/og.png"`
+}
+
+// UpdateLinkRequest edits the metadata of a link. Omitted fields are left
+// unchanged; an empty tags array removes every tag.
+type UpdateLinkRequest struct {
+	Title *string  `json:"title" example:"Spring sale landing page"`
+	Notes *string  `json:"notes" example:"Linked from the March newsletter"`
+	Tags  []string `json:"tags" example:"spring-sale,newsletter"`
+}
+
+type ListLinksResponse struct {
+	// NextCursor fetches the next page; it is omitted on the last page.
+	NextCursor string         `json:"next_cursor,omitempty" example:"q1v0Zf3kS2MAAAAB"`
+	Links      []LinkResponse `json:"links"`
+}
+
+// LinksHandler serves the links of the caller's owner.
+type LinksHandler struct {
+	logger  *zap.Logger
+	useCase *usecases.UseCase
+}
+
+func NewLinksHandler(logger *zap.Logger, useCase *usecases.UseCase) *LinksHandler {
+	return &LinksHandler{
+		logger:  logger,
+		useCase: useCase,
+	}
+}
+
+// ListLinks lists the caller's links, newest first.
+//
+// @Summary      List links
+// @Description  List the links of the caller's owner, newest first. Pass next_cursor from a response as cursor to get the next page, keeping the other parameters unchanged. A page may hold fewer than limit links when filters skip many links; only a missing next_cursor marks the end.
+// @Tags         links
+// @Produce      json
+// @Param        limit         query     int     false  "Links per page, 1 to 100"  default(20)
+// @Param        cursor        query     string  false  "Cursor from the previous page"
+// @Param        domain        query     string  false  "Only links on this short domain"
+// @Param        tag           query     string  false  "Only links with this tag"
+// @Param        q             query     string  false  "Prefix of the short code or destination host"
+// @Param        created_from  query     string  false  "Only links created at or after this RFC 3339 time"
+// @Param        created_to    query     string  false  "Only links created before this RFC 3339 time"
+// @Success      200           {object}  ListLinksResponse
+// @Failure      400           {object}  Problem
+// @Failure      403           {object}  Problem
+// @Failure      404           {object}  Problem
+// @Failure      503           {object}  Problem
+// @Router       /api/v1/links [get]
+func (h *LinksHandler) ListLinks(c *gin.Context) {
+	filter, limit, err := parseListQuery(c)
+	if err != nil {
+		renderError(c, h.logger, err)
+		return
+	}
+
+	page, err := h.useCase.ListLinks(c.Request.Context(), filter, limit, c.Query("cursor"))
+	if err != nil {
+		renderError(c, h.logger, err)
+		return
+	}
+
+	response := ListLinksResponse{
+		NextCursor: page.Cursor,
+		Links:      make([]LinkResponse, 0, len(page.Links)),
+	}
+
+	for i := range page.Links {
+		response.Links = append(response.Links, h.linkResponse(&page.Links[i]))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetLink returns one of the caller's links.
+//
+// @Summary      Get a link
+// @Description  Get a link of the caller's owner with its title, notes, tags and status, and the metadata fetched from its destination
+// @Tags         links
+// @Produce      json
+// @Param        code    path      string  true   "Short code"
+// @Param        domain  query     string  false  "Short domain of the link; the default domain when empty"
+// @Success      200     {object}  LinkResponse
+// @Failure      400     {object}  Problem
+// @Failure      403     {object}  Problem
+// @Failure      404     {object}  Problem
+// @Failure      503     {object}  Problem
+// @Router       /api/v1/links/{code} [get]
+func (h *LinksHandler) GetLink(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	link, err := h.useCase.GetLink(ctx, c.Query("domain"), c.Param("code"))
+	if err != nil {
+		renderError(c, h.logger, err)
+		return
+	}
+
+	preview, err := h.useCase.LinkPreview(ctx, link.Domain, link.ShortCode)
+	if err != nil {
+		renderError(c, h.logger, err)
+		return
+	}
+
+	response := h.linkResponse(link)
+	response.Status = string(link.Status)
+	response.StatusReason = link.StatusReason
+
+	if response.Status == "" {
+		response.Status = string(entities.LinkActive)
+	}
+
+	if preview != nil {
+		response.Preview = &LinkPreviewResponse{
+			FetchedAt:   preview.FetchedAt,
+			Status:      string(preview.Status),
+			Title:       preview.Page.Title,
+			Description: preview.Page.Description,
+			FaviconURL:  preview.Page.FaviconURL,
+			ImageURL:    preview.Page.ImageURL,
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateLink edits the title, notes and tags of one of the caller's links.
+//
+// @Summary      Edit a link
+// @Description  Edit the title, notes and tags of a link of the caller's owner. Omitted fields are left unchanged, and tags replace the previous tags. Needs the editor role when workspaces are enforced.
+// @Tags         links
+// @Accept       json
+// @Produce      json
+// @Param        code     path      string             true   "Short code"
+// @Param        domain   query     string             false  "Short domain of the link; the default domain when empty"
+// @Param        request  body      UpdateLinkRequest  true   "Fields to change"
+// @Success      200      {object}  LinkResponse
+// @Failure      400      {object}  Problem
+// @Failure      403      {object}  Problem
+// @Failure      404      {object}  Problem
+// @Failure      409      {object}  Problem
+// @Failure      503      {object}  Problem
+// @Router       /api/v1/links/{code} [patch]
+func (h *LinksHandler) UpdateLink(c *gin.Context) {
+	var req UpdateLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		renderError(c, h.logger, bindingError(err))
+		return
+	}
+
+	link, err := h.useCase.UpdateLink(c.Request.Context(), c.Query("domain"), c.Param("code"), usecases.LinkUpdate{
+		Title: req.Title,
+		Notes: req.Notes,
+		Tags:  req.Tags,
+	})
+	if err != nil {
+		renderError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, h.linkResponse(link))
+}
+
+func (h *LinksHandler) linkResponse(link *entities.URL) LinkResponse {
+	response := LinkResponse{
+		CreatedAt:   link.CreatedAt,
+		ShortURL:    h.useCase.ShortURL(link.Domain, link.ShortCode),
+		ShortCode:   link.ShortCode,
+		Domain:      link.Domain,
+		OriginalURL: link.LongURL,
+		Title:       link.Title,
+		Notes:       link.Notes,
+		Tags:        link.Tags,
+	}
+
+	if !link.UpdatedAt.IsZero() {
+		response.UpdatedAt = &link.UpdatedAt
+	}
+
+	return response
+}
+
+func parseListQuery(c *gin.Context) (usecases.LinkFilter, int, error) {
+	var fields []entities.FieldError
+
+	limit := usecases.DefaultListLimit
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > usecases.MaxListLimit {
+			fields = append(fields, entities.FieldError{
+				Field:   "limit",
+				Message: fmt.Sprintf("must be an integer from 1 to %d", usecases.MaxListLimit),
+			})
+		}
+
+		limit = parsed
+	}
+
+	filter := usecases.LinkFilter{
+		Domain: c.Query("domain"),
+		Tag:    c.Query("tag"),
+		Search: c.Query("q"),
+	}
+
+	for _, param := range []struct {
+		dest *time.Time
+		name string
+	}{
+		{dest: &filter.CreatedFrom, name: "created_from"},
+		{dest: &filter.CreatedTo, name: "created_to"},
+	} {
+		value := c.Query(param.name)
+		if value == "" {
+			continue
+		}
+
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			fields = append(fields, entities.FieldError{Field: param.name, Message: "must be an RFC 3339 time"})
+			continue
+		}
+
+		*param.dest = parsed
+	}
+
+	if len(fields) > 0 {
+		return filter, 0, entities.ErrValidation.WithFields(fields...)
+	}
+
+	return filter, limit, nil
+}
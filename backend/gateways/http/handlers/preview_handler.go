@@ -0,0 +1,324 @@
+package handlers
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"lnk/domain/entities"
+	"lnk/domain/entities/usecases"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	// previewCookie opts a browser in to the preview page for every short
+	// link.
+	previewCookie       = "lnk_preview"
+	previewCookieMaxAge = 365 * 24 * 60 * 60
+
+	// previewSecurityPolicy loads nothing from outside, so rendering a page
+	// never tells a destination who is looking at it. The favicon is inlined
+	// as a data: URI instead.
+	previewSecurityPolicy = "default-src 'none'; img-src 'self' data:; style-src 'unsafe-inline'; form-action 'self'; frame-ancestors 'none'; base-uri 'none'"
+)
+
+//go:embed templates/*.html
+var templates embed.FS
+
+var (
+	previewTemplate      = template.Must(template.ParseFS(templates, "templates/preview.html"))
+	interstitialTemplate = template.Must(template.ParseFS(templates, "templates/interstitial.html"))
+)
+
+var safetyText = map[usecases.Safety]string{
+	usecases.SafetyNotFlagged: "Not flagged",
+	usecases.SafetyInsecure:   "Not encrypted: the destination uses plain HTTP",
+	usecases.SafetyWarned:     "Flagged: the destination may be unsafe",
+	usecases.SafetyBlocked:    "Blocked: the destination was flagged as harmful",
+}
+
+// previewPage is the data of the preview template.
+type previewPage struct {
+	CreatedAt time.Time
+	// Page is nil until the destination has been fetched.
+	Page *entities.PageMetadata
+	// Favicon is the page's favicon inlined as a data: URI, which html/template
+	// would otherwise refuse as an image source.
+	Favicon template.URL
+	LongURL string
+	// ContinueURL is empty for blocked links.
+	ContinueURL string
+	Reason      string
+	Safety      usecases.Safety
+	SafetyText  string
+	ReturnPath  string
+	Always      bool
+}
+
+// InterstitialPage is the data of the interstitial template, which warns
+// visitors of flagged links before they continue.
+type InterstitialPage struct {
+	LongURL string
+	// Reason is why the link was flagged; it may be empty.
+	Reason string
+	// ContinueURL follows the link past the warning; it is empty for blocked
+	// links.
+	ContinueURL string
+	Blocked     bool
+}
+
+// ParseInterstitial reads a custom interstitial template from path. It is
+// rendered once with sample data, so a template that refers to missing fields
+// fails at startup rather than when a flagged link is visited.
+func ParseInterstitial(path string) (*template.Template, error) {
+	interstitial, err := template.ParseFiles(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse interstitial template: %w", err)
+	}
+
+	sample := InterstitialPage{LongURL: "https://example.com", Reason: "Phishing", ContinueURL: "/abc123?continue=token"}
+	if err := interstitial.Execute(io.Discard, sample); err != nil {
+		return nil, fmt.Errorf("failed to render interstitial template: %w", err)
+	}
+
+	return interstitial, nil
+}
+
+// PreviewHandler serves the HTML pages shown instead of a redirect: the
+// preview page and the interstitial of flagged links.
+type PreviewHandler struct {
+	logger       *zap.Logger
+	useCase      *usecases.UseCase
+	interstitial *template.Template
+}
+
+type NewPreviewHandlerParams struct {
+	Logger  *zap.Logger
+	UseCase *usecases.UseCase
+	// Interstitial renders an InterstitialPage. It defaults to the built-in
+	// warning page.
+	Interstitial *template.Template
+}
+
+func NewPreviewHandler(params NewPreviewHandlerParams) *PreviewHandler {
+	interstitial := params.Interstitial
+	if interstitial == nil {
+		interstitial = interstitialTemplate
+	}
+
+	return &PreviewHandler{
+		logger:       params.Logger,
+		useCase:      params.UseCase,
+		interstitial: interstitial,
+	}
+}
+
+// PreviewLink renders the preview page of a short link.
+//
+// @Summary      Preview a short link
+// @Description  Render an HTML page with the destination, its fetched title, description and favicon, the creation date and the safety status of a short link on the domain named by the Host header, with a button to continue. The same page is served at /{short_url}+ and, for browsers that opted in, at /{short_url}.
+// @Tags         urls
+// @Produce      html
+// @Param        code  path      string  true  "Short URL identifier"
+// @Success      200   {string}  string  "HTML page"
+// @Failure      404   {object}  Problem
+// @Failure      503   {object}  Problem
+// @Router       /preview/{code} [get]
+func (h *PreviewHandler) PreviewLink(c *gin.Context) {
+	h.render(c, h.useCase.ResolveDomain(c.Request.Host), c.Param("code"))
+}
+
+// SetPreference opts the browser in to or out of previews.
+//
+// @Summary      Set the preview preference
+// @Description  Set or clear the cookie that shows the preview page instead of redirecting for every short link, then redirect back to return. Browsers must post the form from this host.
+// @Tags         urls
+// @Accept       x-www-form-urlencoded
+// @Param        always  formData  bool    true   "Always show the preview page"
+// @Param        return  formData  string  false  "Path on this host to return to"
+// @Success      303
+// @Failure      400     {object}  Problem
+// @Failure      403     {object}  Problem
+// @Router       /preview/settings [post]
+func (h *PreviewHandler) SetPreference(c *gin.Context) {
+	if !sameOrigin(c.Request) {
+		renderError(c, h.logger, entities.ErrForbidden.WithMessage("preview settings must be changed from this site"))
+		return
+	}
+
+	always, err := strconv.ParseBool(c.PostForm("always"))
+	if err != nil {
+		renderError(c, h.logger, entities.ErrValidation.WithMessage("always must be true or false"))
+		return
+	}
+
+	secure := strings.HasPrefix(h.useCase.ShortURL(h.useCase.ResolveDomain(c.Request.Host), ""), "https:")
+
+	c.SetSameSite(http.SameSiteLaxMode)
+
+	if always {
+		c.SetCookie(previewCookie, "always", previewCookieMaxAge, "/", "", secure, true)
+	} else {
+		c.SetCookie(previewCookie, "", -1, "/", "", secure, true)
+	}
+
+	c.Redirect(http.StatusSeeOther, localPath(c.PostForm("return")))
+}
+
+// alwaysPreview reports whether the browser opted in to previews.
+func (h *PreviewHandler) alwaysPreview(c *gin.Context) bool {
+	value, err := c.Cookie(previewCookie)
+
+	return err == nil && value == "always"
+}
+
+func (h *PreviewHandler) render(c *gin.Context, domain, shortCode string) {
+	destination, err := h.useCase.InspectLink(c.Request.Context(), domain, shortCode)
+	if err != nil {
+		renderError(c, h.logger, err)
+		return
+	}
+
+	page := previewPage{
+		CreatedAt:   destination.CreatedAt,
+		LongURL:     destination.LongURL,
+		ContinueURL: h.continueURL(destination, domain, shortCode),
+		Reason:      destination.Reason,
+		Safety:      destination.Safety,
+		SafetyText:  safetyText[destination.Safety],
+		ReturnPath:  c.Request.URL.Path,
+		Always:      h.alwaysPreview(c),
+	}
+
+	if preview := destination.Preview; preview != nil && preview.Status == entities.PreviewFetched {
+		page.Page = &preview.Page
+
+		if strings.HasPrefix(preview.Page.Favicon, "data:image/") {
+			page.Favicon = template.URL(preview.Page.Favicon) //nolint:gosec // inlined by the fetcher from a sniffed raster image
+		}
+	}
+
+	h.renderPage(c, http.StatusOK, previewTemplate, page)
+}
+
+// renderInterstitial warns the visitor of a flagged link instead of
+// redirecting.
+func (h *PreviewHandler) renderInterstitial(c *gin.Context, domain, shortCode string, destination *usecases.Destination) {
+	status := http.StatusOK
+	if destination.Safety == usecases.SafetyBlocked {
+		status = http.StatusForbidden
+	}
+
+	h.renderPage(c, status, h.interstitial, InterstitialPage{
+		LongURL:     destination.LongURL,
+		Reason:      destination.Reason,
+		ContinueURL: h.continueURL(destination, domain, shortCode),
+		Blocked:     destination.Safety == usecases.SafetyBlocked,
+	})
+
+	h.useCase.RecordInterstitialChoice(c.Request.Context(), domain, shortCode, usecases.InterstitialShown)
+}
+
+// continueLink redirects a visitor who confirmed the warning of a link with
+// token. A blocked link, or an expired or forged token, shows the warning
+// again.
+func (h *PreviewHandler) continueLink(c *gin.Context, domain, shortCode, token string) {
+	ctx := c.Request.Context()
+
+	longURL, err := h.useCase.ContinueToLongURL(ctx, domain, shortCode, token)
+	if errors.Is(err, usecases.ErrLinkBlocked) || errors.Is(err, usecases.ErrContinueExpired) {
+		destination, err := h.useCase.ResolveLink(ctx, domain, shortCode)
+		if err != nil {
+			renderError(c, h.logger, err)
+			return
+		}
+
+		h.renderInterstitial(c, domain, shortCode, destination)
+
+		return
+	}
+
+	if err != nil {
+		renderError(c, h.logger, err)
+		return
+	}
+
+	h.useCase.RecordInterstitialChoice(ctx, domain, shortCode, usecases.InterstitialContinued)
+
+	// The status of the link may change, so browsers must not remember this
+	// redirect.
+	c.Header("Cache-Control", "no-store")
+	c.Header("Referrer-Policy", "no-referrer")
+	c.Redirect(http.StatusFound, longURL)
+}
+
+func (h *PreviewHandler) renderPage(c *gin.Context, status int, page *template.Template, data any) {
+	var body bytes.Buffer
+	if err := page.Execute(&body, data); err != nil {
+		renderError(c, h.logger, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Content-Security-Policy", previewSecurityPolicy)
+	c.Header("Referrer-Policy", "no-referrer")
+	c.Header("X-Robots-Tag", "noindex, nofollow")
+	c.Data(status, "text/html; charset=utf-8", body.Bytes())
+}
+
+// continueURL leads past the warning of flagged links with a short-lived
+// token, which records the visitor's choice, and straight to the destination
+// otherwise. Blocked links have none.
+func (h *PreviewHandler) continueURL(destination *usecases.Destination, domain, shortCode string) string {
+	switch destination.Safety {
+	case usecases.SafetyBlocked:
+		return ""
+	case usecases.SafetyWarned:
+		return "/" + url.PathEscape(shortCode) + "?continue=" + url.QueryEscape(h.useCase.ContinueToken(domain, shortCode))
+	default:
+		return destination.LongURL
+	}
+}
+
+// sameOrigin reports whether a browser sent r from a page on this host, so
+// other sites cannot change the preview preference of their visitors.
+// Requests without Sec-Fetch-Site and Origin headers do not come from a
+// browser and are allowed.
+func sameOrigin(r *http.Request) bool {
+	switch r.Header.Get("Sec-Fetch-Site") {
+	case "":
+	case "same-origin", "none":
+		return true
+	default:
+		return false
+	}
+
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	parsed, err := url.Parse(origin)
+
+	return err == nil && parsed.Host == r.Host
+}
+
+// localPath returns path when it stays on this host, and "/" otherwise, so the
+// preference form cannot redirect elsewhere.
+func localPath(path string) string {
+	if !strings.HasPrefix(path, "/") || strings.HasPrefix(path, "//") || strings.HasPrefix(path, "/\\") {
+		return "/"
+	}
+
+	return path
+}
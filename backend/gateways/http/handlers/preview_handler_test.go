@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseInterstitial(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	valid := filepath.Join(dir, "valid.html")
+	require.NoError(t, os.WriteFile(valid, []byte(`<p>{{.Reason}}</p>{{if .ContinueURL}}<a href="{{.ContinueURL}}">Continue</a>{{end}}`), 0o600))
+
+	interstitial, err := ParseInterstitial(valid)
+	require.NoError(t, err)
+
+	var page strings.Builder
+	require.NoError(t, interstitial.Execute(&page, InterstitialPage{Reason: "<b>Phishing</b>", ContinueURL: "/abc123?continue=true"}))
+	require.Equal(t, `<p>&lt;b&gt;Phishing&lt;/b&gt;</p><a href="/abc123?continue=true">Continue</a>`, page.String())
+
+	misspelled := filepath.Join(dir, "misspelled.html")
+	require.NoError(t, os.WriteFile(misspelled, []byte(`<p>{{.Reasons}}</p>`), 0o600))
+
+	_, err = ParseInterstitial(misspelled)
+	require.Error(t, err)
+
+	_, err = ParseInterstitial(filepath.Join(dir, "missing.html"))
+	require.Error(t, err)
+}
+
+func Test_localPath(t *testing.T) {
+	t.Parallel()
+
+	for path, want := range map[string]string{
+		"/abc123+":             "/abc123+",
+		"":                     "/",
+		"//evil.example":       "/",
+		"/\\evil.example":      "/",
+		"https://evil.example": "/",
+	} {
+		require.Equal(t, want, localPath(path), path)
+	}
+}
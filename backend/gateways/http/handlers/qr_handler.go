@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"lnk/domain/entities"
+	"lnk/domain/entities/usecases"
+	"lnk/extensions/qrcode"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const qrCacheControl = "public, max-age=86400"
+
+type QRHandler struct {
+	logger   *zap.Logger
+	useCase  *usecases.UseCase
+	renderer *qrcode.Renderer
+}
+
+type NewQRHandlerParams struct {
+	Logger  *zap.Logger
+	UseCase *usecases.UseCase
+	// Renderer defaults to an uncached renderer without a logo.
+	Renderer *qrcode.Renderer
+}
+
+func NewQRHandler(params NewQRHandlerParams) *QRHandler {
+	renderer := params.Renderer
+	if renderer == nil {
+		renderer, _ = qrcode.NewRenderer(qrcode.NewRendererParams{})
+	}
+
+	return &QRHandler{
+		logger:   params.Logger,
+		useCase:  params.UseCase,
+		renderer: renderer,
+	}
+}
+
+// GetQRCode renders a QR code for a short link.
+//
+// @Summary      Get a QR code for a short link
+// @Description  Render a QR code for the fully qualified short URL as PNG or SVG. With logo=true the configured logo is drawn in the center and error correction H is used.
+// @Tags         links
+// @Produce      png
+// @Produce      image/svg+xml
+// @Param        code    path      string  true   "Short URL identifier"
+// @Param        domain  query     string  false  "Short domain of the link; the default domain when empty"
+// @Param        format  query     string  false  "png or svg"  default(png)
+// @Param        size    query     int     false  "Width and height in pixels (64-2048)"  default(256)
+// @Param        ecc     query     string  false  "Error correction level: L, M, Q or H"  default(M)
+// @Param        margin  query     int     false  "Quiet zone in modules (0-16)"  default(4)
+// @Param        fg      query     string  false  "Foreground color, RRGGBB or RRGGBBAA"  default(000000)
+// @Param        bg      query     string  false  "Background color, RRGGBB or RRGGBBAA"  default(ffffff)
+// @Param        logo    query     bool    false  "Draw the configured logo in the center"
+// @Success      200     {file}    binary
+// @Failure      400     {object}  Problem
+// @Failure      404     {object}  Problem
+// @Failure      503     {object}  Problem
+// @Router       /api/v1/links/{code}/qr [get]
+func (h *QRHandler) GetQRCode(c *gin.Context) {
+	domain, err := h.useCase.ValidateDomain(c.Query("domain"))
+	if err != nil {
+		renderError(c, h.logger, err)
+		return
+	}
+
+	h.render(c, domain, c.Param("code"))
+}
+
+func (h *QRHandler) render(c *gin.Context, domain, shortCode string) {
+	ctx := c.Request.Context()
+
+	opts, err := qrOptions(c)
+	if err == nil {
+		err = h.renderer.Validate(opts)
+	}
+
+	if err != nil {
+		renderError(c, h.logger, entities.ErrValidation.WithMessage(err.Error()))
+		return
+	}
+
+	if _, err := h.useCase.GetLongURL(ctx, domain, shortCode); err != nil {
+		renderError(c, h.logger, err)
+		return
+	}
+
+	img, err := h.renderer.Render(ctx, shortCode, h.useCase.ShortURL(domain, shortCode), opts)
+	if err != nil {
+		renderError(c, h.logger, err)
+		return
+	}
+
+	c.Header("Cache-Control", qrCacheControl)
+	c.Data(http.StatusOK, img.ContentType, img.Data)
+}
+
+func qrOptions(c *gin.Context) (qrcode.Options, error) {
+	opts := qrcode.DefaultOptions()
+	opts.Format = c.DefaultQuery("format", opts.Format)
+	opts.Level = c.DefaultQuery("ecc", opts.Level)
+	opts.Foreground = c.DefaultQuery("fg", opts.Foreground)
+	opts.Background = c.DefaultQuery("bg", opts.Background)
+
+	var err error
+
+	if value := c.Query("size"); value != "" {
+		if opts.Size, err = strconv.Atoi(value); err != nil {
+			return opts, qrcode.ErrInvalidSize
+		}
+	}
+
+	if value := c.Query("margin"); value != "" {
+		if opts.Margin, err = strconv.Atoi(value); err != nil {
+			return opts, qrcode.ErrInvalidMargin
+		}
+	}
+
+	if value := c.Query("logo"); value != "" {
+		if opts.Logo, err = strconv.ParseBool(value); err != nil {
+			return opts, entities.ErrValidation.WithMessage("logo must be true or false")
+		}
+	}
+
+	return opts, nil
+}
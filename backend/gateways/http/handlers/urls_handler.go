@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"errors"
 	"fmt"
 	"net/http"
 
@@ -15,11 +14,22 @@ import (
 
 type CreateURLRequest struct {
 	URL string `json:"url" example:"https://example.com" binding:"required"`
+	// Domain is the short domain of the link; the default domain when empty.
+	Domain string `json:"domain,omitempty" example:"go.example.com"`
+	// Title, Notes and Tags require an owner.
+	Title string   `json:"title,omitempty" example:"Spring sale landing page"`
+	Notes string   `json:"notes,omitempty" example:"Linked from the March newsletter"`
+	Tags  []string `json:"tags,omitempty" example:"spring-sale,newsletter"`
 }
 
 type CreateURLResponse struct {
-	ShortURL    string `json:"short_url" example:"abc123"`
-	OriginalURL string `json:"original_url" example:"https://example.com"`
+	ShortURL    string   `json:"short_url" example:"https://go.example.com/abc123"`
+	ShortCode   string   `json:"short_code" example:"abc123"`
+	Domain      string   `json:"domain" example:"go.example.com"`
+	OriginalURL string   `json:"original_url" example:"https://example.com"`
+	Title       string   `json:"title,omitempty" example:"Spring sale landing page"`
+	Notes       string   `json:"notes,omitempty" example:"Linked from the March newsletter"`
+	Tags        []string `json:"tags,omitempty" example:"spring-sale,newsletter"`
 }
 
 type GetURLResponse struct {
@@ -27,33 +37,39 @@ type GetURLResponse struct {
 	OriginalURL string `json:"original_url" example:"https://example.com"`
 }
 
-type ErrorResponse struct {
-	Error string `json:"error" example:"error message"`
-}
-
 type URLsHandler struct {
 	logger  *zap.Logger
 	useCase *usecases.UseCase
+	// previews serves the interstitial of flagged links.
+	previews *PreviewHandler
 }
 
-func NewURLsHandler(logger *zap.Logger, useCase *usecases.UseCase) *URLsHandler {
+func NewURLsHandler(logger *zap.Logger, useCase *usecases.UseCase, previews *PreviewHandler) *URLsHandler {
 	return &URLsHandler{
-		logger:  logger,
-		useCase: useCase,
+		logger:   logger,
+		useCase:  useCase,
+		previews: previews,
 	}
 }
 
 // CreateURL creates a short URL from a long URL.
 //
 // @Summary      Create a short URL
-// @Description  Create a short URL from a long URL
+// @Description  Create a short URL from a long URL, optionally with a title, notes and tags. A request deduplicated to an existing link returns that link with its own metadata.
 // @Tags         urls
 // @Accept       json
 // @Produce      json
-// @Param        request  body      CreateURLRequest  true  "URL to shorten"
-// @Success      200      {object}  CreateURLResponse
-// @Failure      400      {object}  ErrorResponse
-// @Failure      500      {object}  ErrorResponse
+// @Param        request          body      CreateURLRequest  true   "URL to shorten"
+// @Param        Idempotency-Key  header    string            false  "Replays the first response for retries with the same key"
+// @Success      200              {object}  CreateURLResponse
+// @Failure      400              {object}  Problem
+// @Failure      403              {object}  Problem
+// @Failure      404              {object}  Problem
+// @Failure      409              {object}  Problem
+// @Failure      422              {object}  Problem
+// @Failure      429              {object}  Problem
+// @Failure      500              {object}  Problem
+// @Failure      503              {object}  Problem
 // @Router       /shorten [post]
 func (h *URLsHandler) CreateURL(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -71,38 +87,52 @@ func (h *URLsHandler) CreateURL(c *gin.Context) {
 
 	var req CreateURLRequest
 	if bindErr := c.ShouldBindJSON(&req); bindErr != nil {
-		err = fmt.Errorf("failed to bind JSON: %w", bindErr)
-		span.SetStatus(codes.Error, err.Error())
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		err = bindingError(bindErr)
+		renderError(c, h.logger, err)
 		return
 	}
 
-	shortURL, err := h.useCase.CreateShortURL(ctx, req.URL)
+	url, err := h.useCase.CreateLink(ctx, req.Domain, req.URL, usecases.LinkMetadata{
+		Title: req.Title,
+		Notes: req.Notes,
+		Tags:  req.Tags,
+	})
 	if err != nil {
 		err = fmt.Errorf("failed to create short URL: %w", err)
-		span.SetStatus(codes.Error, err.Error())
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		renderError(c, h.logger, err)
 		return
 	}
 
 	span.SetStatus(codes.Ok, "Short URL created")
 	c.JSON(http.StatusOK, CreateURLResponse{
-		ShortURL:    shortURL,
-		OriginalURL: req.URL,
+		ShortURL:    h.useCase.ShortURL(url.Domain, url.ShortCode),
+		ShortCode:   url.ShortCode,
+		Domain:      url.Domain,
+		OriginalURL: url.LongURL,
+		Title:       url.Title,
+		Notes:       url.Notes,
+		Tags:        url.Tags,
 	})
 }
 
 // GetURL retrieves the original URL from a short URL.
 //
 // @Summary      Get original URL by short URL
-// @Description  Get the original URL from a short URL
+// @Description  Redirect to the original URL of a short URL on the domain named by the Host header. Warned links serve an HTML interstitial whose continue action requests the link again with a continue token that expires after 10 minutes; blocked links serve it without one, and disabled links are gone.
 // @Tags         urls
 // @Accept       json
 // @Produce      json
-// @Param        short_url  path      string  true  "Short URL identifier"
-// @Success      308        {object}  map[string]string
-// @Failure      404        {object}  ErrorResponse
-// @Failure      500        {object}  map[string]string
+// @Produce      html
+// @Param        short_url  path      string  true   "Short URL identifier"
+// @Param        continue   query     string  false  "Token from the interstitial that continues past the warning of a flagged link"
+// @Success      200        {string}  string  "Interstitial of a warned link"
+// @Success      302
+// @Success      308
+// @Failure      403        {string}  string  "Interstitial of a blocked link"
+// @Failure      404        {object}  Problem
+// @Failure      410        {object}  Problem
+// @Failure      500        {object}  Problem
+// @Failure      503        {object}  Problem
 // @Router       /{short_url} [get]
 func (h *URLsHandler) GetURL(c *gin.Context) {
 	shortCode := c.Param("short_url")
@@ -119,20 +149,26 @@ func (h *URLsHandler) GetURL(c *gin.Context) {
 	}()
 	defer span.End()
 
-	longURL, err := h.useCase.GetLongURL(ctx, shortCode)
+	domain := h.useCase.ResolveDomain(c.Request.Host)
+
+	destination, err := h.useCase.ResolveLink(ctx, domain, shortCode)
 	if err != nil {
-		if errors.Is(err, usecases.ErrURLNotFound) {
-			span.SetStatus(codes.Error, err.Error())
-			c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
-			return
-		}
+		renderError(c, h.logger, err)
+		return
+	}
 
-		span.SetStatus(codes.Error, err.Error())
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	if destination.Safety == usecases.SafetyWarned || destination.Safety == usecases.SafetyBlocked {
+		// A flagged link is served, not failed.
+		span.SetStatus(codes.Ok, "Link flagged")
+		h.previews.renderInterstitial(c, domain, shortCode, destination)
 
 		return
 	}
 
 	span.SetStatus(codes.Ok, "URL found")
-	c.JSON(http.StatusPermanentRedirect, gin.H{"url": longURL})
+
+	// The status of the link may change, so browsers must not remember this
+	// redirect.
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusPermanentRedirect, destination.LongURL)
 }
@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"lnk/domain/entities"
+	"lnk/domain/entities/usecases"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type CreateWorkspaceRequest struct {
+	ID   string `json:"id" example:"marketing" binding:"required"`
+	Name string `json:"name" example:"Marketing"`
+}
+
+type SetWorkspaceMemberRequest struct {
+	Role string `json:"role" example:"editor" binding:"required"`
+}
+
+type AddWorkspaceDomainRequest struct {
+	Domain string `json:"domain" example:"go.example.com" binding:"required"`
+}
+
+// WorkspaceQuotaResponse lists the limits of a workspace; 0 is unlimited.
+type WorkspaceQuotaResponse struct {
+	LinksPerMonth int64 `json:"links_per_month" example:"10000"`
+	Domains       int   `json:"domains" example:"1"`
+}
+
+type WorkspaceUsageResponse struct {
+	Period  string `json:"period" example:"2026-10"`
+	Links   int64  `json:"links" example:"1250"`
+	Domains int    `json:"domains" example:"1"`
+}
+
+type WorkspaceResponse struct {
+	CreatedAt time.Time               `json:"created_at"`
+	Usage     *WorkspaceUsageResponse `json:"usage,omitempty"`
+	ID        string                  `json:"id" example:"marketing"`
+	Name      string                  `json:"name" example:"Marketing"`
+	Role      string                  `json:"role,omitempty" example:"owner"`
+	Domains   []string                `json:"domains,omitempty"`
+	Quota     WorkspaceQuotaResponse  `json:"quota"`
+}
+
+type WorkspaceMemberResponse struct {
+	CreatedAt time.Time `json:"created_at"`
+	UserID    string    `json:"user_id" example:"alice"`
+	Role      string    `json:"role" example:"editor"`
+}
+
+type WorkspaceMembersResponse struct {
+	Members []WorkspaceMemberResponse `json:"members"`
+}
+
+type WorkspaceDomainResponse struct {
+	Domain string `json:"domain" example:"go.example.com"`
+}
+
+// WorkspacesHandler manages workspaces, their members and their custom
+// domains. Callers are identified by the X-User-ID header.
+type WorkspacesHandler struct {
+	logger  *zap.Logger
+	useCase *usecases.UseCase
+}
+
+func NewWorkspacesHandler(logger *zap.Logger, useCase *usecases.UseCase) *WorkspacesHandler {
+	return &WorkspacesHandler{
+		logger:  logger,
+		useCase: useCase,
+	}
+}
+
+// CreateWorkspace creates a workspace owned by the caller.
+//
+// @Summary      Create a workspace
+// @Description  Create a workspace with the default quota; the calling user becomes its owner. IDs that are taken, including owner IDs that already own links, return 409.
+// @Tags         workspaces
+// @Accept       json
+// @Produce      json
+// @Param        request  body      CreateWorkspaceRequest  true  "Workspace to create"
+// @Success      201      {object}  WorkspaceResponse
+// @Failure      400      {object}  Problem
+// @Failure      403      {object}  Problem
+// @Failure      409      {object}  Problem
+// @Failure      503      {object}  Problem
+// @Router       /api/v1/workspaces [post]
+func (h *WorkspacesHandler) CreateWorkspace(c *gin.Context) {
+	var req CreateWorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		renderError(c, h.logger, bindingError(err))
+		return
+	}
+
+	workspace, err := h.useCase.CreateWorkspace(c.Request.Context(), req.ID, req.Name)
+	if err != nil {
+		renderError(c, h.logger, err)
+		return
+	}
+
+	c.Header("Location", "/api/v1/workspaces/"+workspace.ID)
+	c.JSON(http.StatusCreated, workspaceResponse(workspace))
+}
+
+// GetWorkspace returns a workspace with its domains and current usage.
+//
+// @Summary      Get a workspace
+// @Description  Get a workspace with its quota, custom domains and usage in the current month; visible to its members only
+// @Tags         workspaces
+// @Produce      json
+// @Param        id   path      string  true  "Workspace ID"
+// @Success      200  {object}  WorkspaceResponse
+// @Failure      403  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      503  {object}  Problem
+// @Router       /api/v1/workspaces/{id} [get]
+func (h *WorkspacesHandler) GetWorkspace(c *gin.Context) {
+	summary, err := h.useCase.GetWorkspace(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		renderError(c, h.logger, err)
+		return
+	}
+
+	response := workspaceResponse(summary.Workspace)
+	response.Role = string(summary.Role)
+	response.Domains = summary.Domains
+	response.Usage = &WorkspaceUsageResponse{
+		Period:  summary.Usage.Period,
+		Links:   summary.Usage.Links,
+		Domains: summary.Usage.Domains,
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ListMembers lists the members of a workspace.
+//
+// @Summary      List workspace members
+// @Tags         workspaces
+// @Produce      json
+// @Param        id   path      string  true  "Workspace ID"
+// @Success      200  {object}  WorkspaceMembersResponse
+// @Failure      403  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      503  {object}  Problem
+// @Router       /api/v1/workspaces/{id}/members [get]
+func (h *WorkspacesHandler) ListMembers(c *gin.Context) {
+	members, err := h.useCase.ListWorkspaceMembers(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		renderError(c, h.logger, err)
+		return
+	}
+
+	response := WorkspaceMembersResponse{Members: make([]WorkspaceMemberResponse, 0, len(members))}
+	for i := range members {
+		response.Members = append(response.Members, workspaceMemberResponse(&members[i]))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// SetMember adds a member or changes their role.
+//
+// @Summary      Add or update a workspace member
+// @Description  Give a user the owner, editor or viewer role in the workspace; owners only
+// @Tags         workspaces
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                     true  "Workspace ID"
+// @Param        user_id  path      string                     true  "User ID"
+// @Param        request  body      SetWorkspaceMemberRequest  true  "Role of the member"
+// @Success      200      {object}  WorkspaceMemberResponse
+// @Failure      400      {object}  Problem
+// @Failure      403      {object}  Problem
+// @Failure      404      {object}  Problem
+// @Failure      409      {object}  Problem
+// @Failure      503      {object}  Problem
+// @Router       /api/v1/workspaces/{id}/members/{user_id} [put]
+func (h *WorkspacesHandler) SetMember(c *gin.Context) {
+	var req SetWorkspaceMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		renderError(c, h.logger, bindingError(err))
+		return
+	}
+
+	member, err := h.useCase.SetWorkspaceMember(c.Request.Context(), c.Param("id"), c.Param("user_id"), entities.Role(req.Role))
+	if err != nil {
+		renderError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, workspaceMemberResponse(member))
+}
+
+// RemoveMember removes a member from a workspace.
+//
+// @Summary      Remove a workspace member
+// @Description  Remove a user from the workspace; owners only
+// @Tags         workspaces
+// @Param        id       path  string  true  "Workspace ID"
+// @Param        user_id  path  string  true  "User ID"
+// @Success      204
+// @Failure      403  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      503  {object}  Problem
+// @Router       /api/v1/workspaces/{id}/members/{user_id} [delete]
+func (h *WorkspacesHandler) RemoveMember(c *gin.Context) {
+	if err := h.useCase.RemoveWorkspaceMember(c.Request.Context(), c.Param("id"), c.Param("user_id")); err != nil {
+		renderError(c, h.logger, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// AddDomain assigns a custom domain to a workspace.
+//
+// @Summary      Assign a custom domain
+// @Description  Assign a configured short domain to the workspace within its domain quota; owners only. Only the workspace can then create links on it.
+// @Tags         workspaces
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                     true  "Workspace ID"
+// @Param        request  body      AddWorkspaceDomainRequest  true  "Domain to assign"
+// @Success      201      {object}  WorkspaceDomainResponse
+// @Failure      400      {object}  Problem
+// @Failure      403      {object}  Problem
+// @Failure      404      {object}  Problem
+// @Failure      409      {object}  Problem
+// @Failure      429      {object}  Problem
+// @Failure      503      {object}  Problem
+// @Router       /api/v1/workspaces/{id}/domains [post]
+func (h *WorkspacesHandler) AddDomain(c *gin.Context) {
+	var req AddWorkspaceDomainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		renderError(c, h.logger, bindingError(err))
+		return
+	}
+
+	domain, err := h.useCase.AddWorkspaceDomain(c.Request.Context(), c.Param("id"), req.Domain)
+	if err != nil {
+		renderError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, WorkspaceDomainResponse{Domain: domain})
+}
+
+// RemoveDomain unassigns a custom domain from a workspace.
+//
+// @Summary      Unassign a custom domain
+// @Description  Unassign a custom domain from the workspace; owners only. Existing links on it keep working.
+// @Tags         workspaces
+// @Param        id      path  string  true  "Workspace ID"
+// @Param        domain  path  string  true  "Domain"
+// @Success      204
+// @Failure      400  {object}  Problem
+// @Failure      403  {object}  Problem
+// @Failure      404  {object}  Problem
+// @Failure      503  {object}  Problem
+// @Router       /api/v1/workspaces/{id}/domains/{domain} [delete]
+func (h *WorkspacesHandler) RemoveDomain(c *gin.Context) {
+	if err := h.useCase.RemoveWorkspaceDomain(c.Request.Context(), c.Param("id"), c.Param("domain")); err != nil {
+		renderError(c, h.logger, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func workspaceResponse(workspace *entities.Workspace) WorkspaceResponse {
+	return WorkspaceResponse{
+		CreatedAt: workspace.CreatedAt,
+		ID:        workspace.ID,
+		Name:      workspace.Name,
+		Quota: WorkspaceQuotaResponse{
+			LinksPerMonth: workspace.Quota.LinksPerMonth,
+			Domains:       workspace.Quota.Domains,
+		},
+	}
+}
+
+func workspaceMemberResponse(member *entities.WorkspaceMember) WorkspaceMemberResponse {
+	return WorkspaceMemberResponse{
+		CreatedAt: member.CreatedAt,
+		UserID:    member.UserID,
+		Role:      string(member.Role),
+	}
+}
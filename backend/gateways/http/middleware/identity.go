@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"lnk/domain/entities"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	OwnerIDHeader = "X-Owner-ID"
+	UserIDHeader  = "X-User-ID"
+)
+
+// Identity reads the caller identity set by the trusted gateway in front of
+// the service. The service does not authenticate these headers itself, so the
+// gateway must overwrite any values sent by clients. Malformed values are
+// ignored.
+func Identity() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		identity := entities.Identity{
+			OwnerID: headerValue(c, OwnerIDHeader),
+			UserID:  headerValue(c, UserIDHeader),
+		}
+
+		c.Request = c.Request.WithContext(entities.WithIdentity(c.Request.Context(), identity))
+
+		c.Next()
+	}
+}
+
+func headerValue(c *gin.Context, header string) string {
+	value := c.GetHeader(header)
+	if !validHeaderValue(value) {
+		return ""
+	}
+
+	return value
+}
@@ -4,6 +4,8 @@ import (
 	"net/http"
 	"time"
 
+	"lnk/extensions/logger"
+
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
@@ -13,7 +15,7 @@ const (
 	httpStatusNoContent           = 204
 )
 
-func RequestLogger(logger *zap.Logger) gin.HandlerFunc {
+func RequestLogger(log *zap.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
 		path := c.Request.URL.Path
@@ -22,7 +24,7 @@ func RequestLogger(logger *zap.Logger) gin.HandlerFunc {
 		c.Next()
 
 		latency := time.Since(start)
-		logger.Info("HTTP Request",
+		logger.FromContext(c.Request.Context(), log).Info("HTTP Request",
 			zap.Int("status", c.Writer.Status()),
 			zap.String("method", c.Request.Method),
 			zap.String("path", path),
@@ -34,9 +36,9 @@ func RequestLogger(logger *zap.Logger) gin.HandlerFunc {
 	}
 }
 
-func Recovery(logger *zap.Logger) gin.HandlerFunc {
+func Recovery(log *zap.Logger) gin.HandlerFunc {
 	return gin.CustomRecovery(func(c *gin.Context, recovered any) {
-		logger.Error("Panic recovered",
+		logger.FromContext(c.Request.Context(), log).Error("Panic recovered",
 			zap.Any("error", recovered),
 			zap.String("path", c.Request.URL.Path),
 		)
@@ -49,8 +51,9 @@ func CORS() gin.HandlerFunc {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Request-ID, Idempotency-Key, traceparent, tracestate, baggage")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE, PATCH")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", RequestIDHeader)
 
 		if c.Request.Method == http.MethodOptions {
 			c.AbortWithStatus(httpStatusNoContent)
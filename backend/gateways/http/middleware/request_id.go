@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"lnk/extensions/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+
+	maxHeaderValueLength = 128
+)
+
+// RequestID accepts the caller's X-Request-ID, or generates one when it is
+// missing or malformed, stores it in the request context for
+// logger.FromContext, tags the current span with it and echoes it back.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validHeaderValue(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		ctx := logger.WithRequestID(c.Request.Context(), requestID)
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("http.request.id", requestID))
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Header(RequestIDHeader, requestID)
+
+		c.Next()
+	}
+}
+
+// validHeaderValue only accepts short, printable ASCII identifiers so a
+// client cannot inject arbitrary content into logs or Redis keys.
+func validHeaderValue(value string) bool {
+	if value == "" || len(value) > maxHeaderValueLength {
+		return false
+	}
+
+	for _, r := range value {
+		if r < '!' || r > '~' {
+			return false
+		}
+	}
+
+	return true
+}
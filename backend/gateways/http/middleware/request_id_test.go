@@ -0,0 +1,59 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"lnk/extensions/logger"
+	"lnk/gateways/http/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RequestID(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+
+	var seen string
+
+	router := gin.New()
+	router.Use(middleware.RequestID())
+	router.GET("/", func(c *gin.Context) {
+		seen = logger.RequestID(c.Request.Context())
+	})
+
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{name: "accepts a valid ID", incoming: "req-42", keep: true},
+		{name: "generates a missing ID"},
+		{name: "replaces an ID with control characters", incoming: "bad\tid"},
+		{name: "replaces an oversized ID", incoming: strings.Repeat("a", 129)},
+	}
+
+	for _, tt := range tests {
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tt.incoming != "" {
+			request.Header.Set(middleware.RequestIDHeader, tt.incoming)
+		}
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		echoed := recorder.Header().Get(middleware.RequestIDHeader)
+		require.NotEmpty(t, echoed, tt.name)
+		require.Equal(t, echoed, seen, tt.name)
+
+		if tt.keep {
+			require.Equal(t, tt.incoming, echoed, tt.name)
+		} else {
+			require.NotEqual(t, tt.incoming, echoed, tt.name)
+		}
+	}
+}
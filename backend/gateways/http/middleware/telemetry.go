@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "lnk/gateways/http"
+
+// durationBuckets are the boundaries recommended by the HTTP semantic
+// conventions for http.server.request.duration.
+var durationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.075, 0.1, 0.25, 0.5, 0.75, 1, 2.5, 5, 7.5, 10}
+
+// Telemetry continues the trace from the incoming W3C traceparent and baggage
+// headers, wraps each request in a server span named after its route template
+// and records http.server.request.duration and http.server.active_requests.
+// It must be the outermost middleware so the span covers recovered panics.
+func Telemetry() gin.HandlerFunc {
+	tracer := otel.Tracer(instrumentationName)
+	meter := otel.Meter(instrumentationName)
+
+	// Instrument creation only fails on invalid names, which are constant here.
+	duration, _ := meter.Float64Histogram(
+		"http.server.request.duration",
+		metric.WithDescription("Duration of HTTP server requests"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(durationBuckets...),
+	)
+
+	activeRequests, _ := meter.Int64UpDownCounter(
+		"http.server.active_requests",
+		metric.WithDescription("Number of in-flight HTTP server requests"),
+		metric.WithUnit("{request}"),
+	)
+
+	return func(c *gin.Context) {
+		request := c.Request
+		ctx := otel.GetTextMapPropagator().Extract(request.Context(), propagation.HeaderCarrier(request.Header))
+
+		// The route template keeps span names and metric series bounded; it
+		// is empty for unmatched requests.
+		route := c.FullPath()
+		method := semconv.HTTPRequestMethodKey.String(request.Method)
+
+		spanName := request.Method
+		baseAttrs := []attribute.KeyValue{method, semconv.URLScheme(scheme(c))}
+
+		if route != "" {
+			spanName += " " + route
+			baseAttrs = append(baseAttrs, semconv.HTTPRoute(route))
+		}
+
+		ctx, span := tracer.Start(ctx, spanName,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(baseAttrs...),
+			trace.WithAttributes(
+				semconv.URLPath(request.URL.Path),
+				semconv.ServerAddress(request.Host),
+				semconv.ClientAddress(c.ClientIP()),
+				semconv.UserAgentOriginal(request.UserAgent()),
+				semconv.NetworkProtocolVersion(strconv.Itoa(request.ProtoMajor)+"."+strconv.Itoa(request.ProtoMinor)),
+			),
+		)
+		defer span.End()
+
+		activeAttrs := metric.WithAttributes(method, semconv.URLScheme(scheme(c)))
+		activeRequests.Add(ctx, 1, activeAttrs)
+		defer activeRequests.Add(ctx, -1, activeAttrs)
+
+		c.Request = request.WithContext(ctx)
+
+		start := time.Now()
+
+		c.Next()
+
+		status := c.Writer.Status()
+		attrs := make([]attribute.KeyValue, 0, len(baseAttrs)+2)
+		attrs = append(attrs, baseAttrs...)
+		attrs = append(attrs, semconv.HTTPResponseStatusCode(status))
+
+		if status >= httpStatusInternalServerError {
+			attrs = append(attrs, semconv.ErrorTypeKey.String(strconv.Itoa(status)))
+			span.SetStatus(codes.Error, "")
+		}
+
+		for _, err := range c.Errors {
+			span.RecordError(err.Err)
+		}
+
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		duration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(attrs...))
+	}
+}
+
+func scheme(c *gin.Context) string {
+	if c.Request.TLS != nil {
+		return "https"
+	}
+
+	return "http"
+}
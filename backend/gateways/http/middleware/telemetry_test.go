@@ -0,0 +1,82 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"lnk/gateways/http/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func Test_Telemetry(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)))
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(middleware.Telemetry())
+	router.GET("/:short_url", func(c *gin.Context) {
+		c.Status(http.StatusNotFound)
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/abc123", nil)
+	request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), request)
+
+	ended := spans.Ended()
+	require.Len(t, ended, 1)
+	require.Equal(t, "GET /:short_url", ended[0].Name())
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", ended[0].SpanContext().TraceID().String())
+	require.Equal(t, "00f067aa0ba902b7", ended[0].Parent().SpanID().String())
+	require.Contains(t, ended[0].Attributes(), attribute.String("http.route", "/:short_url"))
+
+	var metrics metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &metrics))
+
+	histogram := findHistogram(t, metrics, "http.server.request.duration")
+	require.Len(t, histogram.DataPoints, 1)
+	require.Equal(t, uint64(1), histogram.DataPoints[0].Count)
+
+	status, ok := histogram.DataPoints[0].Attributes.Value("http.response.status_code")
+	require.True(t, ok)
+	require.Equal(t, int64(http.StatusNotFound), status.AsInt64())
+
+	route, ok := histogram.DataPoints[0].Attributes.Value("http.route")
+	require.True(t, ok)
+	require.Equal(t, "/:short_url", route.AsString())
+}
+
+func findHistogram(t *testing.T, metrics metricdata.ResourceMetrics, name string) metricdata.Histogram[float64] {
+	t.Helper()
+
+	for _, scope := range metrics.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			if m.Name == name {
+				histogram, ok := m.Data.(metricdata.Histogram[float64])
+				require.True(t, ok)
+
+				return histogram
+			}
+		}
+	}
+
+	require.Failf(t, "metric not found", "%s", name)
+
+	return metricdata.Histogram[float64]{}
+}
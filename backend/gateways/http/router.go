@@ -17,9 +17,13 @@ type RouterConfig struct {
 
 func NewRouter(cfg RouterConfig) *gin.Engine {
 	gin.SetMode(cfg.GinMode)
+	handlers.UseJSONFieldNames()
 
 	router := gin.New()
 
+	router.Use(middleware.Telemetry())
+	router.Use(middleware.RequestID())
+	router.Use(middleware.Identity())
 	router.Use(middleware.Recovery(cfg.Logger))
 	router.Use(middleware.RequestLogger(cfg.Logger))
 	router.Use(middleware.CORS())
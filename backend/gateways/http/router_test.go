@@ -0,0 +1,169 @@
+package http_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"lnk/domain/entities/usecases"
+	httpServer "lnk/gateways/http"
+	"lnk/gateways/http/handlers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func Test_Router_ProblemResponses(t *testing.T) {
+	t.Parallel()
+
+	router := httpServer.NewRouter(httpServer.RouterConfig{
+		Logger:  zap.NewNop(),
+		GinMode: gin.TestMode,
+		Env:     "production",
+		Handlers: handlers.NewHandlers(handlers.NewHandlersParams{
+			Logger:     zap.NewNop(),
+			UseCase:    usecases.NewUseCase(usecases.NewUseCaseParams{Logger: zap.NewNop()}),
+			BulkLimits: handlers.BulkLimits{MaxItems: 1},
+		}),
+	})
+
+	t.Run("validation error names the JSON field", func(t *testing.T) {
+		recorder := serve(router, http.MethodPost, "/shorten", `{}`)
+		require.Equal(t, http.StatusBadRequest, recorder.Code)
+		require.Equal(t, "application/problem+json", recorder.Header().Get("Content-Type"))
+
+		var problem handlers.Problem
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &problem))
+		require.Equal(t, "validation_failed", problem.Code)
+		require.Equal(t, http.StatusBadRequest, problem.Status)
+		require.Equal(t, "/shorten", problem.Instance)
+		require.NotEmpty(t, problem.RequestID)
+		require.Len(t, problem.Errors, 1)
+		require.Equal(t, "url", problem.Errors[0].Field)
+	})
+
+	t.Run("malformed body", func(t *testing.T) {
+		recorder := serve(router, http.MethodPost, "/shorten", `{`)
+		require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+		var problem handlers.Problem
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &problem))
+		require.Equal(t, "request body is not valid JSON", problem.Detail)
+	})
+
+	t.Run("bulk request over the item limit", func(t *testing.T) {
+		recorder := serve(router, http.MethodPost, "/api/v1/links/bulk", `[{"url":"https://a.example"},{"url":"https://b.example"}]`)
+		require.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+		require.Contains(t, recorder.Body.String(), `"code":"too_large"`)
+	})
+
+	t.Run("bulk request for an unknown domain", func(t *testing.T) {
+		recorder := serve(router, http.MethodPost, "/api/v1/links/bulk?domain=evil.example", `[{"url":"https://a.example"}]`)
+		require.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("async bulk request without a job runner", func(t *testing.T) {
+		recorder := serve(router, http.MethodPost, "/api/v1/links/bulk?async=true", `[{"url":"https://a.example"}]`)
+		require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	})
+
+	t.Run("QR code with invalid options", func(t *testing.T) {
+		for _, path := range []string{"/api/v1/links/abc123/qr?format=gif", "/abc123?format=png&size=1"} {
+			recorder := serve(router, http.MethodGet, path, "")
+			require.Equal(t, http.StatusBadRequest, recorder.Code, path)
+			require.Contains(t, recorder.Body.String(), `"code":"validation_failed"`, path)
+		}
+	})
+
+	t.Run("workspace creation without a user", func(t *testing.T) {
+		recorder := serve(router, http.MethodPost, "/api/v1/workspaces", `{"id":"acme"}`)
+		require.Equal(t, http.StatusForbidden, recorder.Code)
+		require.Contains(t, recorder.Body.String(), `"code":"forbidden"`)
+	})
+
+	t.Run("link listing with invalid parameters", func(t *testing.T) {
+		recorder := serve(router, http.MethodGet, "/api/v1/links?limit=0&created_from=yesterday", "")
+		require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+		var problem handlers.Problem
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &problem))
+		require.Len(t, problem.Errors, 2)
+		require.Equal(t, "limit", problem.Errors[0].Field)
+		require.Equal(t, "created_from", problem.Errors[1].Field)
+	})
+
+	t.Run("link listing without an owner", func(t *testing.T) {
+		recorder := serve(router, http.MethodGet, "/api/v1/links", "")
+		require.Equal(t, http.StatusForbidden, recorder.Code)
+	})
+
+	t.Run("link metadata without an owner", func(t *testing.T) {
+		recorder := serve(router, http.MethodPost, "/shorten", `{"url":"https://example.com","tags":["spring"]}`)
+		require.Equal(t, http.StatusForbidden, recorder.Code)
+
+		recorder = serve(router, http.MethodPatch, "/api/v1/links/abc123", `{"title":"Spring"}`)
+		require.Equal(t, http.StatusForbidden, recorder.Code)
+	})
+
+	t.Run("link metadata over the limits", func(t *testing.T) {
+		recorder := serve(router, http.MethodPost, "/shorten", `{"url":"https://example.com","tags":[" "]}`)
+		require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+		var problem handlers.Problem
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &problem))
+		require.Len(t, problem.Errors, 1)
+		require.Equal(t, "tags", problem.Errors[0].Field)
+	})
+
+	t.Run("preview preference", func(t *testing.T) {
+		for form, location := range map[string]string{
+			"always=true&return=/abc123%2B":        "/abc123+",
+			"always=true&return=//evil.example/x":  "/",
+			"always=true&return=https://evil.test": "/",
+		} {
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodPost, "/preview/settings", strings.NewReader(form))
+			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			router.ServeHTTP(recorder, request)
+
+			require.Equal(t, http.StatusSeeOther, recorder.Code, form)
+			require.Equal(t, location, recorder.Header().Get("Location"), form)
+			require.Contains(t, recorder.Header().Get("Set-Cookie"), "lnk_preview=always", form)
+			require.Contains(t, recorder.Header().Get("Set-Cookie"), "HttpOnly", form)
+		}
+
+		recorder := serve(router, http.MethodPost, "/preview/settings", "")
+		require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+		for header, value := range map[string]string{
+			"Sec-Fetch-Site": "cross-site",
+			"Origin":         "https://evil.test",
+		} {
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodPost, "/preview/settings", strings.NewReader("always=true"))
+			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			request.Header.Set(header, value)
+			router.ServeHTTP(recorder, request)
+
+			require.Equal(t, http.StatusForbidden, recorder.Code, header)
+			require.Empty(t, recorder.Header().Get("Set-Cookie"), header)
+		}
+
+		recorder = httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodPost, "/preview/settings", strings.NewReader("always=true"))
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		request.Header.Set("Sec-Fetch-Site", "same-origin")
+		request.Header.Set("Origin", "http://"+request.Host)
+		router.ServeHTTP(recorder, request)
+		require.Equal(t, http.StatusSeeOther, recorder.Code)
+	})
+
+	t.Run("unknown route", func(t *testing.T) {
+		recorder := serve(router, http.MethodGet, "/a/b/c", "")
+		require.Equal(t, http.StatusNotFound, recorder.Code)
+		require.Contains(t, recorder.Body.String(), `"code":"not_found"`)
+	})
+}
@@ -2,7 +2,9 @@ package http
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"time"
 
@@ -24,10 +26,12 @@ const (
 // @schemes   http https
 
 type Server struct {
-	logger *zap.Logger
-	srv    *http.Server
-	router *gin.Engine
-	port   string
+	logger     *zap.Logger
+	srv        *http.Server
+	router     *gin.Engine
+	addr       string
+	onShutdown []func()
+	drainDelay time.Duration
 }
 
 type Config struct {
@@ -36,52 +40,76 @@ type Config struct {
 	Port   string
 }
 
-func NewServer(logger *zap.Logger, port string, router *gin.Engine) *Server {
+// NewServer creates a server listening on addr, e.g. ":8080" for every
+// interface or "127.0.0.1:9090" for loopback only.
+func NewServer(logger *zap.Logger, addr string, router *gin.Engine) *Server {
 	return &Server{
 		logger: logger,
-		port:   port,
+		addr:   addr,
 		router: router,
+		srv: &http.Server{
+			Addr:              addr,
+			Handler:           router,
+			ReadHeaderTimeout: readHeaderTimeout,
+		},
 	}
 }
 
-func (s *Server) Start() error {
-	addr := ":" + s.port
-	s.logger.Info("Starting HTTP server", zap.String("address", addr))
+// OnShutdown registers fn to run as soon as Shutdown is called, before the
+// listener closes, e.g. to start failing readiness checks.
+func (s *Server) OnShutdown(fn func()) {
+	s.onShutdown = append(s.onShutdown, fn)
+}
+
+// SetDrainDelay keeps the listener open for delay after Shutdown is called so
+// load balancers observe the failing readiness check before connections close.
+func (s *Server) SetDrainDelay(delay time.Duration) {
+	s.drainDelay = delay
+}
 
-	s.srv = &http.Server{
-		Addr:              addr,
-		Handler:           s.router,
-		ReadHeaderTimeout: readHeaderTimeout,
+// Run listens on the configured port and serves requests until Shutdown is
+// called. A listen failure, such as the port being taken, is returned to the
+// caller instead of terminating the process.
+func (s *Server) Run(_ context.Context) error {
+	s.logger.Info("Starting HTTP server", zap.String("address", s.srv.Addr))
+
+	listener, err := net.Listen("tcp", s.srv.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.srv.Addr, err)
 	}
 
-	go func() {
-		err := s.srv.ListenAndServe()
-		if err != nil && err != http.ErrServerClosed {
-			s.logger.Fatal("Failed to start server", zap.Error(err))
-		}
-	}()
+	err = s.srv.Serve(listener)
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("server failed: %w", err)
+	}
 
 	return nil
 }
 
+// Shutdown drains the server and waits for in-flight requests until ctx
+// expires.
 func (s *Server) Shutdown(ctx context.Context) error {
-	if s.srv == nil {
-		return nil
-	}
+	s.logger.Info("Shutting down HTTP server", zap.String("address", s.addr))
 
-	s.logger.Info("Shutting down HTTP server")
+	for _, fn := range s.onShutdown {
+		fn()
+	}
 
-	const shutdownTimeout = 5 * time.Second
+	if s.drainDelay > 0 {
+		s.logger.Info("Draining HTTP server", zap.Duration("delay", s.drainDelay))
 
-	shutdownCtx, cancel := context.WithTimeout(ctx, shutdownTimeout)
-	defer cancel()
+		select {
+		case <-time.After(s.drainDelay):
+		case <-ctx.Done():
+		}
+	}
 
-	err := s.srv.Shutdown(shutdownCtx)
+	err := s.srv.Shutdown(ctx)
 	if err != nil {
 		return fmt.Errorf("server shutdown failed: %w", err)
 	}
 
-	s.logger.Info("HTTP server stopped")
+	s.logger.Info("HTTP server stopped", zap.String("address", s.addr))
 
 	return nil
 }
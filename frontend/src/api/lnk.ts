@@ -7,11 +7,15 @@
  */
 import { customInstance } from "./undici-instance";
 export interface HandlersCreateURLRequest {
+  /** Domain is the short domain of the link; the default domain when empty. */
+  domain?: string;
   url: string;
 }
 
 export interface HandlersCreateURLResponse {
+  domain?: string;
   original_url?: string;
+  short_code?: string;
   short_url?: string;
 }
 
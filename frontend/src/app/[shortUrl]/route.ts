@@ -1,8 +1,18 @@
 import { NextResponse } from "next/server";
-import { getShortUrl } from "@/api/lnk";
+import { customInstance } from "@/api/undici-instance";
+
+// Response headers of the backend's pages that must reach the browser, such
+// as the security policy of the interstitial.
+const relayedHeaders = [
+  "cache-control",
+  "content-security-policy",
+  "content-type",
+  "referrer-policy",
+  "x-robots-tag",
+];
 
 export async function GET(
-  _request: Request,
+  request: Request,
   context: { params: Promise<{ shortUrl: string }> | { shortUrl: string } },
 ) {
   const params = context.params;
@@ -16,34 +26,41 @@ export async function GET(
     );
   }
 
-  try {
-    const response = await getShortUrl(shortUrl);
+  // The backend resolves the short domain from Host, and the query string
+  // carries options such as the interstitial's continue token.
+  const { search } = new URL(request.url);
+  const host = request.headers.get("host");
 
-    if (response.status === 308) {
-      const location = response.headers.get("Location");
-      if (location) {
-        return NextResponse.redirect(location, 308);
-      }
+  try {
+    const response = await customInstance<unknown>(
+      `/${encodeURIComponent(shortUrl)}${search}`,
+      {
+        method: "GET",
+        redirect: "manual",
+        headers: host ? { host } : undefined,
+      },
+    );
 
-      const data = response.data as { [key: string]: string };
-      const originalUrl =
-        data.original_url || data.url || Object.values(data)[0];
-      if (originalUrl) {
-        return NextResponse.redirect(originalUrl, 308);
+    const headers = new Headers();
+    for (const name of relayedHeaders) {
+      const value = response.headers.get(name);
+      if (value) {
+        headers.set(name, value);
       }
     }
 
-    if (response.status === 404) {
-      return NextResponse.json(
-        { error: "Short URL not found" },
-        { status: 404 },
-      );
+    const location = response.headers.get("location");
+    if (response.status >= 300 && response.status < 400 && location) {
+      headers.set("location", location);
+      return new NextResponse(null, { status: response.status, headers });
     }
 
-    return NextResponse.json(
-      { error: "Failed to redirect", status: response.status },
-      { status: response.status },
-    );
+    const body =
+      typeof response.data === "string"
+        ? response.data
+        : JSON.stringify(response.data);
+
+    return new NextResponse(body, { status: response.status, headers });
   } catch (error) {
     console.error("Error fetching short URL:", error);
     return NextResponse.json(
@@ -16,8 +16,8 @@ export function UrlDialog() {
   const { shortUrl, originalUrl, dialogOpen, setDialogOpen, clearUrl } =
     useUrlShortener();
   const [copied, setCopied] = useState(false);
-  const frontEndUrl = process.env.NEXT_PUBLIC_FRONT_URL;
-  const fullUrl = `${frontEndUrl}/${shortUrl}`;
+  // The API returns the fully qualified short URL on the link's domain.
+  const fullUrl = shortUrl;
 
   const handleCopy = async () => {
     if (!fullUrl) return;